@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	benchServer      string
+	benchTool        string
+	benchParams      string
+	benchN           int
+	benchConcurrency int
+	benchJSON        bool
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench --server <id> --tool <name> [--params <json>] [--n 100]",
+	Short: "Measure tool call latency through the mcp2 proxy",
+	Long: `Call a tool N times through a running mcp2 proxy over a single connection
+and report min/avg/p50/p95/p99/max latency and the error rate.
+
+Example:
+  mcp2 bench --server context7 --tool resolve-library-id --params '{"libraryName":"react"}' --n 200 --concurrency 8`,
+	RunE: runBench,
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringVar(&benchServer, "server", "", "server ID to target (prefixes the tool name)")
+	benchCmd.Flags().StringVar(&benchTool, "tool", "", "tool name (required)")
+	benchCmd.Flags().StringVar(&benchParams, "params", "{}", "tool parameters as JSON")
+	benchCmd.Flags().IntVar(&benchN, "n", 100, "number of calls to make")
+	benchCmd.Flags().IntVar(&benchConcurrency, "concurrency", 1, "number of calls in flight at once")
+	benchCmd.Flags().BoolVar(&benchJSON, "json", false, "output the summary as JSON")
+	benchCmd.Flags().IntVar(&callPort, "port", 8210, "mcp2 server port")
+	benchCmd.Flags().StringVar(&callEndpoint, "endpoint", "/mcp", "mcp2 endpoint (e.g., /mcp or /mcp/servername)")
+	benchCmd.Flags().StringVar(&callTimeout, "timeout", "30", "per-call timeout, in seconds or as a duration (e.g. 2m)")
+	addHubAuthFlags(benchCmd)
+	_ = benchCmd.MarkFlagRequired("tool")
+}
+
+// benchSummary is the latency and error summary printed by bench. Durations
+// marshal to JSON as nanoseconds.
+type benchSummary struct {
+	Tool      string        `json:"tool"`
+	Calls     int           `json:"calls"`
+	Errors    int           `json:"errors"`
+	ErrorRate float64       `json:"errorRate"`
+	Min       time.Duration `json:"min"`
+	Avg       time.Duration `json:"avg"`
+	P50       time.Duration `json:"p50"`
+	P95       time.Duration `json:"p95"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+	Total     time.Duration `json:"total"`
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchN < 1 {
+		return fmt.Errorf("--n must be at least 1")
+	}
+	if benchConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+
+	timeout, err := parseTimeout(callTimeout)
+	if err != nil {
+		return err
+	}
+
+	var params map[string]any
+	if err := json.Unmarshal([]byte(benchParams), &params); err != nil {
+		return fmt.Errorf("invalid JSON in --params: %w", err)
+	}
+
+	name := benchTool
+	if benchServer != "" {
+		name = benchServer + prefixSeparator() + benchTool
+	}
+
+	_, session, err := connectToMCP2(context.Background())
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	infof("Benchmarking %s: %d calls, concurrency %d\n", name, benchN, benchConcurrency)
+
+	latencies := make([]time.Duration, benchN)
+	failed := make([]bool, benchN)
+	calls := make(chan int)
+
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < benchConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range calls {
+				ctx, cancel := context.WithTimeout(context.Background(), timeout)
+				callStart := time.Now()
+				result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: name, Arguments: params})
+				latencies[i] = time.Since(callStart)
+				failed[i] = err != nil || result.IsError
+				cancel()
+			}
+		}()
+	}
+	for i := 0; i < benchN; i++ {
+		calls <- i
+	}
+	close(calls)
+	wg.Wait()
+
+	summary := summarizeBench(name, latencies, failed)
+	summary.Total = time.Since(start)
+
+	if benchJSON {
+		data, _ := json.MarshalIndent(summary, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Calls: %d (%d errors, %.1f%%)\n", summary.Calls, summary.Errors, 100*summary.ErrorRate)
+	fmt.Printf("Latency: min %v, avg %v, p50 %v, p95 %v, p99 %v, max %v\n",
+		summary.Min, summary.Avg, summary.P50, summary.P95, summary.P99, summary.Max)
+	fmt.Printf("Total: %v (%.1f calls/s)\n", summary.Total, float64(summary.Calls)/summary.Total.Seconds())
+	return nil
+}
+
+// summarizeBench computes latency percentiles and the error rate.
+func summarizeBench(name string, latencies []time.Duration, failed []bool) benchSummary {
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	summary := benchSummary{
+		Tool:  name,
+		Calls: len(sorted),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		P50:   percentile(sorted, 50),
+		P95:   percentile(sorted, 95),
+		P99:   percentile(sorted, 99),
+	}
+
+	var sum time.Duration
+	for _, d := range sorted {
+		sum += d
+	}
+	summary.Avg = sum / time.Duration(len(sorted))
+
+	for _, f := range failed {
+		if f {
+			summary.Errors++
+		}
+	}
+	summary.ErrorRate = float64(summary.Errors) / float64(summary.Calls)
+	return summary
+}
+
+// percentile returns the nearest-rank percentile p of sorted latencies.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -4,18 +4,32 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/proxy"
+	"github.com/ain3sh/mcp2/internal/version"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
+	"github.com/yosida95/uritemplate/v3"
 )
 
 var (
 	callPort     int
 	callEndpoint string
-	callTimeout  int
+	callTimeout  string
+	noTimeout    bool
 	jsonOutput   bool
+	callServer   string
+	callFirst    bool
+	callHeaders  []string
+	callToken    string
 )
 
 var callCmd = &cobra.Command{
@@ -37,7 +51,10 @@ var callToolCmd = &cobra.Command{
 
 Example:
   mcp2 call tool --name filesystem:list_directory --params '{"path":"/home/user"}'
-  mcp2 call tool --name context7:get-library-docs --params '{"context7CompatibleLibraryID":"/websites/react_dev"}'`,
+  mcp2 call tool --name context7:get-library-docs --params '{"context7CompatibleLibraryID":"/websites/react_dev"}'
+
+Use --server '*' to call the tool on every allowed server exposing it and
+combine the results in server ID order (or --first for the first success).`,
 	RunE: runCallTool,
 }
 
@@ -57,7 +74,18 @@ var callResourceCmd = &cobra.Command{
 	Long: `Read a resource through the mcp2 proxy with the active profile's filtering rules.
 
 Example:
-  mcp2 call resource --uri file:///home/user/projects/README.md`,
+  mcp2 call resource --uri file:///home/user/projects/README.md
+
+Use --server '*' to read the resource from every allowed server and combine the
+contents in server ID order (or --first for the first success).
+
+Use --range to read part of a large resource, e.g. --range 0-4095 for the
+first 4 KiB or --range 1048576- for everything after the first MiB. mcp2
+reads the whole resource from the server and returns only the range.
+
+Use --var to expand a templated URI from the server's resource templates,
+e.g. --uri 'file://logs/{date}.log' --var date=2024-01-01. Every variable in
+the template must be given.`,
 	RunE: runCallResource,
 }
 
@@ -67,6 +95,8 @@ var (
 	promptName   string
 	promptArgs   string
 	resourceURI  string
+	resourceVars []string
+	readRange    string
 )
 
 func init() {
@@ -81,8 +111,17 @@ func init() {
 	for _, cmd := range []*cobra.Command{callToolCmd, callPromptCmd, callResourceCmd} {
 		cmd.Flags().IntVar(&callPort, "port", 8210, "mcp2 server port")
 		cmd.Flags().StringVar(&callEndpoint, "endpoint", "/mcp", "mcp2 endpoint (e.g., /mcp or /mcp/servername)")
-		cmd.Flags().IntVar(&callTimeout, "timeout", 30, "request timeout in seconds")
+		cmd.Flags().StringVar(&callTimeout, "timeout", "30", "request timeout, in seconds or as a duration (e.g. 2m)")
+		cmd.Flags().BoolVar(&noTimeout, "no-timeout", false, "wait for the response however long it takes")
+		cmd.MarkFlagsMutuallyExclusive("timeout", "no-timeout")
 		cmd.Flags().BoolVar(&jsonOutput, "json", false, "output raw JSON response")
+		addHubAuthFlags(cmd)
+	}
+
+	// Broadcast flags
+	for _, cmd := range []*cobra.Command{callToolCmd, callResourceCmd} {
+		cmd.Flags().StringVar(&callServer, "server", "", "server ID to target (prefixes the name), or '*' to broadcast to all servers")
+		cmd.Flags().BoolVar(&callFirst, "first", false, "with --server '*', return only the first successful result")
 	}
 
 	// Tool-specific flags
@@ -97,20 +136,114 @@ func init() {
 
 	// Resource-specific flags
 	callResourceCmd.Flags().StringVar(&resourceURI, "uri", "", "resource URI (required)")
+	callResourceCmd.Flags().StringArrayVar(&resourceVars, "var", nil, "template variable as key=value for a templated --uri (repeatable)")
+	callResourceCmd.Flags().StringVar(&readRange, "range", "", "read only bytes start-end (inclusive) or start- of each content")
 	_ = callResourceCmd.MarkFlagRequired("uri")
 }
 
+// broadcastMeta returns the _meta requesting a hub broadcast when --server
+// is '*', or nil otherwise.
+func broadcastMeta() mcp.Meta {
+	if callServer != "*" {
+		return nil
+	}
+	mode := proxy.BroadcastAll
+	if callFirst {
+		mode = proxy.BroadcastFirst
+	}
+	return mcp.Meta{proxy.BroadcastMetaKey: mode}
+}
+
+// targetName prefixes name with the --server ID, if one was given.
+func targetName(name string) string {
+	if callServer == "" || callServer == "*" {
+		return name
+	}
+	return callServer + prefixSeparator() + name
+}
+
+// prefixSeparator returns the separator the hub joins server IDs and names
+// with for the profile served over HTTP, or the default if there is no
+// config to read it from.
+func prefixSeparator() string {
+	path, _ := resolveConfigPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return config.DefaultPrefixSeparator
+	}
+	activeProfile := cfg.ProfileFor(config.TransportHTTP)
+	if profileName != "" {
+		activeProfile = profileName
+	}
+	_, separator := cfg.PrefixSettings(activeProfile)
+	return separator
+}
+
+// progressPrinter renders progress notifications on stderr. On a terminal
+// it rewrites a single line as updates arrive; otherwise, so logs and pipes
+// get no escape codes, it prints a line per update.
+type progressPrinter struct {
+	rewrite bool
+
+	mu      sync.Mutex
+	printed bool
+}
+
+func (p *progressPrinter) handle(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	line := fmt.Sprintf("Progress: %v", req.Params.Progress)
+	if req.Params.Total > 0 {
+		line = fmt.Sprintf("Progress: %3.0f%%", 100*req.Params.Progress/req.Params.Total)
+	}
+	if req.Params.Message != "" {
+		line += " " + req.Params.Message
+	}
+	if !p.rewrite {
+		fmt.Fprintln(os.Stderr, line)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "\r\033[K%s", line)
+	p.printed = true
+}
+
+// finish ends the progress line, if one was printed.
+func (p *progressPrinter) finish() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.printed {
+		fmt.Fprintln(os.Stderr)
+	}
+}
+
 // connectToMCP2 creates a client connection to the mcp2 server
 func connectToMCP2(ctx context.Context) (*mcp.Client, *mcp.ClientSession, error) {
+	return connectToMCP2WithOptions(ctx, nil)
+}
+
+// connectToMCP2WithOptions is connectToMCP2 with custom client options.
+func connectToMCP2WithOptions(ctx context.Context, opts *mcp.ClientOptions) (*mcp.Client, *mcp.ClientSession, error) {
 	client := mcp.NewClient(&mcp.Implementation{
 		Name:    "mcp2-cli",
-		Version: "0.1.0",
-	}, nil)
+		Version: version.Version,
+	}, opts)
+
+	headers, err := hubHeaders()
+	if err != nil {
+		return nil, nil, err
+	}
 
 	endpoint := fmt.Sprintf("http://127.0.0.1:%d%s", callPort, callEndpoint)
 	transport := &mcp.StreamableClientTransport{
 		Endpoint: endpoint,
 	}
+	if len(headers) > 0 {
+		transport.HTTPClient = &http.Client{
+			Transport: &cliHeaderTransport{base: http.DefaultTransport, headers: headers},
+		}
+	}
 
 	session, err := client.Connect(ctx, transport, nil)
 	if err != nil {
@@ -120,8 +253,91 @@ func connectToMCP2(ctx context.Context) (*mcp.Client, *mcp.ClientSession, error)
 	return client, session, nil
 }
 
+// addHubAuthFlags registers the flags that add headers to requests sent to
+// the hub.
+func addHubAuthFlags(cmd *cobra.Command) {
+	cmd.Flags().StringArrayVar(&callHeaders, "header", nil, "header to send to mcp2, as 'Name: value' (repeatable)")
+	cmd.Flags().StringVar(&callToken, "token", "", "bearer token for an authenticated hub (default $MCP2_TOKEN)")
+}
+
+// hubHeaders returns the headers to send to the hub from --header and
+// --token, falling back to $MCP2_TOKEN for the token.
+func hubHeaders() (http.Header, error) {
+	headers := make(http.Header)
+	for _, h := range callHeaders {
+		name, value, ok := strings.Cut(h, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --header %q (expected 'Name: value')", h)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+
+	token := callToken
+	if token == "" {
+		token = os.Getenv("MCP2_TOKEN")
+	}
+	if token != "" {
+		headers.Set("Authorization", "Bearer "+token)
+	}
+	return headers, nil
+}
+
+// cliHeaderTransport adds fixed headers to every request sent to the hub.
+type cliHeaderTransport struct {
+	base    http.RoundTripper
+	headers http.Header
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *cliHeaderTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for name, values := range t.headers {
+		req.Header[name] = values
+	}
+	return t.base.RoundTrip(req)
+}
+
+// callContext returns the context for a call command, bounded by --timeout
+// unless --no-timeout is set.
+func callContext() (context.Context, context.CancelFunc, error) {
+	if noTimeout {
+		ctx, cancel := context.WithCancel(context.Background())
+		return ctx, cancel, nil
+	}
+	timeout, err := parseTimeout(callTimeout)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return ctx, cancel, nil
+}
+
+// parseTimeout parses a --timeout value: whole seconds, as in earlier
+// versions, or a duration such as 90s or 2m.
+func parseTimeout(s string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(s); err == nil {
+		if seconds <= 0 {
+			return 0, fmt.Errorf("--timeout must be positive; use --no-timeout to disable it")
+		}
+		return time.Duration(seconds) * time.Second, nil
+	}
+	timeout, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid --timeout %q: want seconds or a duration like 2m", s)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("--timeout must be positive; use --no-timeout to disable it")
+	}
+	return timeout, nil
+}
+
 func runCallTool(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(callTimeout)*time.Second)
+	ctx, cancel, err := callContext()
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
 	// Parse tool parameters
@@ -130,18 +346,33 @@ func runCallTool(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid JSON in --params: %w", err)
 	}
 
-	// Connect to mcp2
-	_, session, err := connectToMCP2(ctx)
+	// Connect to mcp2, rendering progress while the tool runs unless
+	// --quiet is set
+	progress := &progressPrinter{rewrite: isTerminal(os.Stderr)}
+	opts := &mcp.ClientOptions{}
+	if !quiet {
+		opts.ProgressNotificationHandler = progress.handle
+	}
+	_, session, err := connectToMCP2WithOptions(ctx, opts)
 	if err != nil {
 		return err
 	}
 	defer session.Close()
 
 	// Call the tool
-	result, err := session.CallTool(ctx, &mcp.CallToolParams{
-		Name:      toolName,
+	callParams := &mcp.CallToolParams{
+		Meta:      broadcastMeta(),
+		Name:      targetName(toolName),
 		Arguments: params,
-	})
+	}
+	if callParams.Meta == nil {
+		callParams.Meta = mcp.Meta{}
+	}
+	if !quiet {
+		callParams.SetProgressToken("mcp2-cli")
+	}
+	result, err := session.CallTool(ctx, callParams)
+	progress.finish()
 	if err != nil {
 		return fmt.Errorf("tool call failed: %w", err)
 	}
@@ -151,10 +382,14 @@ func runCallTool(cmd *cobra.Command, args []string) error {
 		data, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(data))
 	} else {
-		fmt.Printf("Tool: %s\n", toolName)
-		fmt.Printf("Status: Success\n")
-		fmt.Printf("\nResult:\n")
-		fmt.Printf("-------\n")
+		infof("Tool: %s\n", toolName)
+		if result.IsError {
+			infof("Status: %s\n", colorize("Error", ansiRed))
+		} else {
+			infof("Status: %s\n", colorize("Success", ansiGreen))
+		}
+		infof("\nResult:\n")
+		infof("-------\n")
 
 		if len(result.Content) == 0 {
 			fmt.Println("(no content)")
@@ -179,15 +414,41 @@ func runCallTool(cmd *cobra.Command, args []string) error {
 		}
 
 		if result.IsError {
-			fmt.Println("\nNote: Tool indicated an error condition")
+			fmt.Println(colorize("\nNote: Tool indicated an error condition", ansiRed))
 		}
 	}
 
+	// The hub tried several servers and every one failed; the result above
+	// carries the per-server breakdown
+	if attempts := callAttempts(result); len(attempts) > 0 {
+		return fmt.Errorf("tool call failed on all %d server(s) tried", len(attempts))
+	}
 	return nil
 }
 
+// callAttempts returns the per-server failures the hub attached to a tool
+// result when every upstream it tried failed.
+func callAttempts(result *mcp.CallToolResult) []proxy.CallAttempt {
+	raw, ok := result.Meta[proxy.AttemptsMetaKey]
+	if !ok {
+		return nil
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil
+	}
+	var attempts []proxy.CallAttempt
+	if err := json.Unmarshal(data, &attempts); err != nil {
+		return nil
+	}
+	return attempts
+}
+
 func runCallPrompt(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(callTimeout)*time.Second)
+	ctx, cancel, err := callContext()
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
 	// Parse prompt arguments
@@ -217,15 +478,15 @@ func runCallPrompt(cmd *cobra.Command, args []string) error {
 		data, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(data))
 	} else {
-		fmt.Printf("Prompt: %s\n", promptName)
-		fmt.Printf("Status: Success\n")
+		infof("Prompt: %s\n", promptName)
+		infof("Status: %s\n", colorize("Success", ansiGreen))
 
 		if result.Description != "" {
-			fmt.Printf("Description: %s\n", result.Description)
+			infof("Description: %s\n", result.Description)
 		}
 
-		fmt.Printf("\nMessages:\n")
-		fmt.Printf("---------\n")
+		infof("\nMessages:\n")
+		infof("---------\n")
 
 		if len(result.Messages) == 0 {
 			fmt.Println("(no messages)")
@@ -250,7 +511,15 @@ func runCallPrompt(cmd *cobra.Command, args []string) error {
 }
 
 func runCallResource(cmd *cobra.Command, args []string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(callTimeout)*time.Second)
+	uri, err := expandURITemplate(resourceURI, resourceVars)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel, err := callContext()
+	if err != nil {
+		return err
+	}
 	defer cancel()
 
 	// Connect to mcp2
@@ -260,9 +529,21 @@ func runCallResource(cmd *cobra.Command, args []string) error {
 	}
 	defer session.Close()
 
+	meta := broadcastMeta()
+	if readRange != "" {
+		if _, err := proxy.ParseRange(readRange); err != nil {
+			return err
+		}
+		if meta == nil {
+			meta = mcp.Meta{}
+		}
+		meta[proxy.RangeMetaKey] = readRange
+	}
+
 	// Read the resource
 	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{
-		URI: resourceURI,
+		Meta: meta,
+		URI:  targetName(uri),
 	})
 	if err != nil {
 		return fmt.Errorf("resource read failed: %w", err)
@@ -273,10 +554,10 @@ func runCallResource(cmd *cobra.Command, args []string) error {
 		data, _ := json.MarshalIndent(result, "", "  ")
 		fmt.Println(string(data))
 	} else {
-		fmt.Printf("Resource: %s\n", resourceURI)
-		fmt.Printf("Status: Success\n")
-		fmt.Printf("\nContents:\n")
-		fmt.Printf("---------\n")
+		infof("Resource: %s\n", uri)
+		infof("Status: %s\n", colorize("Success", ansiGreen))
+		infof("\nContents:\n")
+		infof("---------\n")
 
 		if len(result.Contents) == 0 {
 			fmt.Println("(no contents)")
@@ -286,6 +567,9 @@ func runCallResource(cmd *cobra.Command, args []string) error {
 			if len(result.Contents) > 1 {
 				fmt.Printf("\n[Content %d - URI: %s]\n", i, content.URI)
 			}
+			if r, ok := content.Meta[proxy.RangeMetaKey].(map[string]any); ok {
+				infof("Range: bytes %v-%v of %v\n", r["start"], r["end"], r["size"])
+			}
 
 			// Check if it's text or blob
 			if content.Text != "" {
@@ -309,6 +593,52 @@ func runCallResource(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// expandURITemplate expands uri as an RFC 6570 template with vars, each
+// given as key=value. A URI without template variables is returned as is
+// when no vars are given.
+func expandURITemplate(uri string, vars []string) (string, error) {
+	values := uritemplate.Values{}
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if !ok || key == "" {
+			return "", fmt.Errorf("invalid --var %q: want key=value", v)
+		}
+		if values.Get(key).Valid() {
+			return "", fmt.Errorf("--var %q given more than once", key)
+		}
+		values.Set(key, uritemplate.String(value))
+	}
+
+	tmpl, err := uritemplate.New(uri)
+	if err != nil {
+		if len(vars) == 0 {
+			return uri, nil
+		}
+		return "", fmt.Errorf("invalid URI template %q: %w", uri, err)
+	}
+	names := tmpl.Varnames()
+	if len(names) == 0 && len(vars) == 0 {
+		return uri, nil
+	}
+
+	var missing []string
+	for _, name := range names {
+		if !values.Get(name).Valid() {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("URI template %q needs --var for: %s", uri, strings.Join(missing, ", "))
+	}
+	for _, v := range vars {
+		key, _, _ := strings.Cut(v, "=")
+		if !slices.Contains(names, key) {
+			return "", fmt.Errorf("--var %q is not a variable of URI template %q", key, uri)
+		}
+	}
+	return tmpl.Expand(values)
+}
+
 // Helper to print JSON output to stderr and exit with error
 func printErrorJSON(message string, err error) {
 	errObj := map[string]string{
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	capabilitiesServer string
+	capabilitiesJSON   bool
+)
+
+var capabilitiesCmd = &cobra.Command{
+	Use:   "capabilities",
+	Short: "Show what each upstream server supports",
+	Long: `Connect to each upstream server and print the server info and capabilities
+(tools, resources, prompts, logging, completions) it advertises during the
+initialize handshake.`,
+	RunE: runCapabilities,
+}
+
+func init() {
+	rootCmd.AddCommand(capabilitiesCmd)
+	capabilitiesCmd.Flags().StringVarP(&capabilitiesServer, "server", "s", "", "only show this server")
+	capabilitiesCmd.Flags().BoolVar(&capabilitiesJSON, "json", false, "output capabilities as JSON")
+}
+
+func runCapabilities(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Resolve config path
+	path, _ := resolveConfigPath()
+
+	// Load config
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.ExpandEnvVars()
+
+	if err := cfg.ResolveSecrets(ctx); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	serverIDs := make([]string, 0, len(cfg.Servers))
+	if capabilitiesServer != "" {
+		if _, ok := cfg.Servers[capabilitiesServer]; !ok {
+			return fmt.Errorf("server %q not found in config", capabilitiesServer)
+		}
+		serverIDs = append(serverIDs, capabilitiesServer)
+	} else {
+		for serverID := range cfg.Servers {
+			serverIDs = append(serverIDs, serverID)
+		}
+		sort.Strings(serverIDs)
+	}
+
+	statuses, failed := probeUpstreams(ctx, cfg, serverIDs, 0)
+
+	if capabilitiesJSON {
+		data, _ := json.MarshalIndent(statuses, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		for _, s := range statuses {
+			printCapabilities(s)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to connect to %d of %d servers", failed, len(serverIDs))
+	}
+	return nil
+}
+
+// probeUpstreams connects to each server in turn and returns its status,
+// including its capabilities, along with the number of servers that could not
+// be connected. Failed servers are reported with LastError set. Each
+// connected server is pinged samples times first so its status carries
+// request latencies.
+func probeUpstreams(ctx context.Context, cfg *config.RootConfig, serverIDs []string, samples int) ([]upstream.Status, int) {
+	manager := upstream.NewManager()
+	manager.SetHTTPClientConfig(cfg.Hub.HTTPClient)
+	manager.SetInheritEnv(cfg.Hub.DefaultInheritEnv())
+	defer manager.Close()
+
+	statuses := make([]upstream.Status, 0, len(serverIDs))
+	failed := 0
+	for _, serverID := range serverIDs {
+		serverCfg := cfg.Servers[serverID]
+		if err := manager.Connect(ctx, serverID, &serverCfg); err != nil {
+			lastErr := err.Error()
+			if hint := connectHint(err); hint != "" {
+				lastErr += " (" + hint + ")"
+			}
+			statuses = append(statuses, upstream.Status{
+				ID:          serverID,
+				DisplayName: serverCfg.DisplayName,
+				LastError:   lastErr,
+			})
+			failed++
+			continue
+		}
+		u, _ := manager.Get(serverID)
+		for i := 0; i < samples; i++ {
+			if err := u.Session().Ping(ctx, nil); err != nil {
+				break
+			}
+		}
+		statuses = append(statuses, u.Status())
+	}
+	return statuses, failed
+}
+
+// printCapabilities prints a server's info and advertised capabilities.
+func printCapabilities(s upstream.Status) {
+	name := s.ID
+	if s.DisplayName != "" {
+		name = fmt.Sprintf("%s (%s)", s.ID, s.DisplayName)
+	}
+	fmt.Println(colorize(name, ansiBold))
+
+	if s.LastError != "" {
+		fmt.Printf("  %s %s\n", colorize("error:", ansiRed), s.LastError)
+		return
+	}
+
+	fmt.Printf("  Server: %s %s\n", s.ServerName, s.ServerVersion)
+	fmt.Printf("  Protocol: %s\n", s.ProtocolVersion)
+	fmt.Printf("  Capabilities: %s\n", strings.Join(capabilityList(s.Capabilities), ", "))
+}
+
+// capabilityList describes each advertised capability, noting listChanged
+// and subscribe support.
+func capabilityList(caps *mcp.ServerCapabilities) []string {
+	if caps == nil {
+		return []string{"none"}
+	}
+
+	var list []string
+	if caps.Tools != nil {
+		list = append(list, withFlags("tools", caps.Tools.ListChanged, false))
+	}
+	if caps.Resources != nil {
+		list = append(list, withFlags("resources", caps.Resources.ListChanged, caps.Resources.Subscribe))
+	}
+	if caps.Prompts != nil {
+		list = append(list, withFlags("prompts", caps.Prompts.ListChanged, false))
+	}
+	if caps.Logging != nil {
+		list = append(list, "logging")
+	}
+	if caps.Completions != nil {
+		list = append(list, "completions")
+	}
+	experimental := make([]string, 0, len(caps.Experimental))
+	for name := range caps.Experimental {
+		experimental = append(experimental, name)
+	}
+	sort.Strings(experimental)
+	for _, name := range experimental {
+		list = append(list, "experimental:"+name)
+	}
+
+	if len(list) == 0 {
+		return []string{"none"}
+	}
+	return list
+}
+
+func withFlags(name string, listChanged, subscribe bool) string {
+	var flags []string
+	if listChanged {
+		flags = append(flags, "listChanged")
+	}
+	if subscribe {
+		flags = append(flags, "subscribe")
+	}
+	if len(flags) == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s (%s)", name, strings.Join(flags, ", "))
+}
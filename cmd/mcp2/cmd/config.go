@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	dumpJSON bool
+	dumpYAML bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the loaded configuration",
+}
+
+var configDumpCmd = &cobra.Command{
+	Use:   "dump [--json|--yaml]",
+	Short: "Print the fully resolved config",
+	Long: `Print the config mcp2 runs with after overlays, --set overrides, environment
+variable expansion, and secret resolution, exactly as serve loads it.
+
+Secrets are masked unless --show-secrets is set. Output is YAML by default.`,
+	Args: cobra.NoArgs,
+	RunE: runConfigDump,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configDumpCmd)
+	configDumpCmd.Flags().BoolVar(&dumpJSON, "json", false, "print JSON")
+	configDumpCmd.Flags().BoolVar(&dumpYAML, "yaml", false, "print YAML (default)")
+	configDumpCmd.MarkFlagsMutuallyExclusive("json", "yaml")
+}
+
+func runConfigDump(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Load the config the same way serve does
+	path, _ := resolveConfigPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.ExpandEnvVars()
+
+	if err := cfg.ResolveSecrets(ctx); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	out := displayConfig(cfg)
+	if dumpJSON {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode config: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	enc := yaml.NewEncoder(os.Stdout)
+	enc.SetIndent(2)
+	if err := enc.Encode(out); err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	return enc.Close()
+}
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/ain3sh/mcp2/internal/config"
 	"github.com/ain3sh/mcp2/internal/profile"
@@ -26,11 +27,11 @@ func init() {
 }
 
 func runEffective(cmd *cobra.Command, args []string) error {
-	// Expand config path
-	path := expandPath(configPath)
+	// Resolve config path
+	path, _ := resolveConfigPath()
 
 	// Load config
-	cfg, err := config.Load(path)
+	cfg, err := loadConfig(path)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -60,19 +61,29 @@ func runEffective(cmd *cobra.Command, args []string) error {
 
 	// Get server profile config
 	serverProfile, ok := profileCfg.Servers[effectiveServer]
-	if !ok {
-		fmt.Printf("Profile: %s\n", activeProfile)
-		fmt.Printf("Server: %s\n", effectiveServer)
-		fmt.Println("\nServer is not configured in this profile (all access denied)")
+	if !ok && !profileCfg.Passthrough {
+		infof("Profile: %s\n", activeProfile)
+		infof("Server: %s\n\n", effectiveServer)
+		fmt.Println("Server is not configured in this profile (all access denied)")
 		return nil
 	}
 
 	// Create profile engine for testing
 	engine := profile.NewEngine(cfg, activeProfile)
 
-	fmt.Printf("Profile: %s\n", activeProfile)
-	fmt.Printf("Description: %s\n", profileCfg.Description)
-	fmt.Printf("Server: %s\n\n", effectiveServer)
+	infof("Profile: %s\n", activeProfile)
+	infof("Description: %s\n", profileCfg.Description)
+	infof("Server: %s\n\n", effectiveServer)
+
+	if engine.SafeMode() {
+		fmt.Println(colorize("Safe mode: ACTIVE (tools not annotated read-only are denied regardless of the rules below)", ansiRed))
+		fmt.Println()
+	}
+
+	if profileCfg.Passthrough {
+		fmt.Println("Passthrough profile: no filtering rules (allow all)")
+		return nil
+	}
 
 	// Display tools filtering
 	fmt.Println("Tools:")
@@ -85,6 +96,18 @@ func runEffective(cmd *cobra.Command, args []string) error {
 	displayFilterRules("  ", serverProfile.Resources, func(uri string) bool {
 		return engine.IsResourceAllowed(effectiveServer, uri)
 	})
+	if md := serverProfile.ResourceMetadata; md.IsSet() {
+		fmt.Println("  Metadata (resources declaring these are neither listed nor read):")
+		if md.MaxSize > 0 {
+			fmt.Printf("    - size over %d bytes\n", md.MaxSize)
+		}
+		if len(md.Audience) > 0 {
+			fmt.Printf("    - audience without %s\n", strings.Join(md.Audience, " or "))
+		}
+		if md.MinPriority > 0 {
+			fmt.Printf("    - priority below %g\n", md.MinPriority)
+		}
+	}
 
 	// Display prompts filtering
 	fmt.Println("\nPrompts:")
@@ -127,11 +150,6 @@ func displayFilterRules(indent string, filter config.ComponentFilter, testFunc f
 	}
 
 	for _, testCase := range testCases {
-		allowed := testFunc(testCase)
-		status := "DENIED"
-		if allowed {
-			status = "ALLOWED"
-		}
-		fmt.Printf("%s  %s: %s\n", indent, testCase, status)
+		fmt.Printf("%s  %s: %s\n", indent, testCase, decisionText(testFunc(testCase)))
 	}
 }
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	explainServer string
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain tool|resource|prompt <name>",
+	Short: "Explain a single filtering decision",
+	Long: `Trace how the active profile decides whether a tool, resource, or prompt on a
+server is allowed: which deny pattern matched, whether the allow list was empty,
+which allow pattern matched, and the final decision.`,
+	Args: cobra.ExactArgs(2),
+	RunE: runExplain,
+}
+
+func init() {
+	rootCmd.AddCommand(explainCmd)
+	explainCmd.Flags().StringVarP(&explainServer, "server", "s", "", "server the name belongs to (required)")
+	explainCmd.MarkFlagRequired("server")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	kind, name := args[0], args[1]
+
+	// Resolve config path
+	path, _ := resolveConfigPath()
+
+	// Load config
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.ExpandEnvVars()
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	// Determine active profile
+	activeProfile := cfg.DefaultProfile
+	if profileName != "" {
+		activeProfile = profileName
+	}
+
+	if _, ok := cfg.Profiles[activeProfile]; !ok {
+		return fmt.Errorf("profile %q not found", activeProfile)
+	}
+
+	if _, ok := cfg.Servers[explainServer]; !ok {
+		return fmt.Errorf("server %q not found in config", explainServer)
+	}
+
+	engine := profile.NewEngine(cfg, activeProfile)
+
+	var decision profile.Decision
+	switch kind {
+	case "tool":
+		decision = engine.ExplainTool(explainServer, name)
+	case "resource":
+		decision = engine.ExplainResource(explainServer, name)
+	case "prompt":
+		decision = engine.ExplainPrompt(explainServer, name)
+	default:
+		return fmt.Errorf("unknown kind %q (expected tool, resource, or prompt)", kind)
+	}
+
+	infof("Profile: %s\n", activeProfile)
+	infof("Server: %s\n", explainServer)
+	infof("Name: %s (%s)\n\n", name, kind)
+
+	printExplanation(decision)
+	return nil
+}
+
+// printExplanation prints the steps the engine took to reach a decision.
+func printExplanation(d profile.Decision) {
+	step := 1
+	stepf := func(format string, a ...any) {
+		fmt.Printf("  %d. %s\n", step, fmt.Sprintf(format, a...))
+		step++
+	}
+
+	switch d.Reason {
+	case profile.ReasonProfileNotFound:
+		stepf("profile does not exist: denied by default")
+	case profile.ReasonServerNotInProfile:
+		stepf("server is not configured in this profile: denied by default")
+	default:
+		if d.Reason == profile.ReasonDenied {
+			stepf("deny pattern %q matched", d.Pattern)
+			break
+		}
+		if len(d.Filter.Deny) == 0 {
+			stepf("no deny patterns")
+		} else {
+			stepf("no deny pattern matched %v", d.Filter.Deny)
+		}
+
+		switch d.Reason {
+		case profile.ReasonAllowListEmpty:
+			stepf("allow list is empty: everything not denied is allowed")
+		case profile.ReasonAllowed:
+			stepf("allow pattern %q matched", d.Pattern)
+		case profile.ReasonNotAllowed:
+			stepf("no allow pattern matched %v", d.Filter.Allow)
+		}
+	}
+
+	fmt.Printf("\nDecision: %s (%s)\n", decisionText(d.Allowed), d.Reason)
+	if !d.Allowed && d.Message != "" {
+		fmt.Printf("Message to client: %s\n", d.Message)
+	}
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	exportTo     string
+	exportOutput string
+	exportViaHub bool
+	exportPort   int
+	exportName   string
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export --to claude",
+	Short: "Export servers to another MCP client's config format",
+	Long: `Write the configured servers in another MCP client's config format.
+
+By default every upstream server is exported as-is. With --profile, only the
+servers referenced by that profile are exported. With --via-hub, a single
+entry pointing at the mcp2 hub's HTTP endpoint is written instead, so the
+client goes through mcp2's filtering.
+
+Example:
+  mcp2 export --to claude -p safe -o claude_desktop_config.json
+  mcp2 export --to claude --via-hub --port 8210`,
+	Args: cobra.NoArgs,
+	RunE: runExport,
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&exportTo, "to", "claude", "target config format (claude)")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "write the config to a file instead of stdout")
+	exportCmd.Flags().BoolVar(&exportViaHub, "via-hub", false, "emit a single entry for the mcp2 hub instead of the upstreams")
+	exportCmd.Flags().IntVar(&exportPort, "port", 8210, "mcp2 hub port (with --via-hub)")
+	exportCmd.Flags().StringVar(&exportName, "name", "mcp2", "server name for the hub entry (with --via-hub)")
+}
+
+func runExport(cmd *cobra.Command, args []string) error {
+	if exportTo != "claude" {
+		return fmt.Errorf("unsupported export target %q (supported: claude)", exportTo)
+	}
+
+	var out *config.ClaudeDesktopConfig
+
+	if exportViaHub {
+		out = &config.ClaudeDesktopConfig{
+			MCPServers: map[string]config.ClaudeServer{
+				exportName: {
+					Type: "http",
+					URL:  fmt.Sprintf("http://127.0.0.1:%d/mcp", exportPort),
+				},
+			},
+		}
+	} else {
+		path, _ := resolveConfigPath()
+
+		// Environment variables are deliberately not expanded so that
+		// references like ${GITHUB_TOKEN} are exported rather than their values.
+		cfg, err := loadConfig(path)
+		if err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+
+		var serverIDs []string
+		if profileName != "" {
+			profileCfg, ok := cfg.Profiles[profileName]
+			if !ok {
+				return fmt.Errorf("profile %q not found", profileName)
+			}
+			// Passthrough profiles export every server, as does a nil
+			// list; a profile listing no servers exports none
+			if !profileCfg.Passthrough {
+				serverIDs = []string{}
+				for serverID := range profileCfg.Servers {
+					serverIDs = append(serverIDs, serverID)
+				}
+			}
+			sort.Strings(serverIDs)
+		}
+
+		out, err = config.ExportClaudeDesktop(cfg, serverIDs)
+		if err != nil {
+			return err
+		}
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	data = append(data, '\n')
+
+	if exportOutput != "" {
+		if err := os.WriteFile(expandPath(exportOutput), data, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", exportOutput, err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %d server(s) to %s\n", len(out.MCPServers), exportOutput)
+		return nil
+	}
+
+	fmt.Print(string(data))
+	return nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	importFrom   string
+	importOutput string
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import --from claude <path>",
+	Short: "Import servers from another MCP client's config",
+	Long: `Convert another MCP client's server configuration into an mcp2 config.
+
+The generated config contains every imported server and a permissive
+"default" profile that exposes all of them. Environment variable references
+are preserved as-is.
+
+Example:
+  mcp2 import --from claude ~/Library/Application\ Support/Claude/claude_desktop_config.json > mcp2.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runImport,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVar(&importFrom, "from", "claude", "source config format (claude)")
+	importCmd.Flags().StringVarP(&importOutput, "output", "o", "", "write the config to a file instead of stdout")
+}
+
+func runImport(cmd *cobra.Command, args []string) error {
+	if importFrom != "claude" {
+		return fmt.Errorf("unsupported import source %q (supported: claude)", importFrom)
+	}
+
+	data, err := os.ReadFile(expandPath(args[0]))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+
+	cfg, err := config.ImportClaudeDesktop(data)
+	if err != nil {
+		return err
+	}
+
+	out, err := marshalYAML(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if importOutput != "" {
+		if err := os.WriteFile(expandPath(importOutput), out, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", importOutput, err)
+		}
+		fmt.Fprintf(os.Stderr, "Imported %d server(s) to %s\n", len(cfg.Servers), importOutput)
+		return nil
+	}
+
+	fmt.Print(string(out))
+	return nil
+}
+
+// marshalYAML encodes v as YAML with the two-space indentation used by the
+// example configs.
+func marshalYAML(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
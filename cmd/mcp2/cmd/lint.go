@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/spf13/cobra"
+)
+
+var (
+	lintFile string
+	lintJSON bool
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check profile filters against a snapshot's server catalog",
+	Long: `Check each profile's allow and deny patterns against the unfiltered catalog
+recorded by "mcp2 snapshot", without connecting to any server. Flags allow
+patterns that match nothing, usually typos or tools an upstream renamed, and
+deny patterns that are redundant: they match nothing, only match names the
+allow list already excludes, or only match names earlier deny patterns
+already deny. Exits non-zero if anything is flagged.
+
+Checks every profile, or only the one given with --profile.
+
+Example:
+  mcp2 snapshot -f mcp2.snapshot.json
+  mcp2 lint -f mcp2.snapshot.json
+  mcp2 lint -p safe --json`,
+	Args: cobra.NoArgs,
+	RunE: runLint,
+}
+
+func init() {
+	rootCmd.AddCommand(lintCmd)
+	lintCmd.Flags().StringVarP(&lintFile, "file", "f", "mcp2.snapshot.json", "snapshot file with the server catalog")
+	lintCmd.Flags().BoolVar(&lintJSON, "json", false, "print the warnings as JSON")
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	path, _ := resolveConfigPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.ExpandEnvVars()
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	data, err := os.ReadFile(lintFile)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snap surfaceSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to parse snapshot %s: %w", lintFile, err)
+	}
+	if snap.Catalog == nil {
+		return fmt.Errorf("snapshot %s has no server catalog; write it again with mcp2 snapshot", lintFile)
+	}
+
+	var profiles []string
+	if profileName != "" {
+		if _, ok := cfg.Profiles[profileName]; !ok {
+			return fmt.Errorf("profile %q not found", profileName)
+		}
+		profiles = []string{profileName}
+	} else {
+		for name := range cfg.Profiles {
+			profiles = append(profiles, name)
+		}
+		sort.Strings(profiles)
+	}
+
+	warnings := []profile.LintWarning{}
+	for _, name := range profiles {
+		warnings = append(warnings, profile.Lint(cfg, name, snap.Catalog)...)
+	}
+
+	if lintJSON {
+		data, _ := json.MarshalIndent(struct {
+			Warnings []profile.LintWarning `json:"warnings"`
+		}{warnings}, "", "  ")
+		fmt.Println(string(data))
+	} else if len(warnings) == 0 {
+		infof("No problems found against %s\n", lintFile)
+	} else {
+		for _, w := range warnings {
+			fmt.Printf("%s %s\n", colorize("warning:", ansiYellow), w)
+		}
+	}
+
+	if len(warnings) > 0 {
+		// Findings are a result, not a usage error
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d warning(s) against %s", len(warnings), lintFile)
+	}
+	return nil
+}
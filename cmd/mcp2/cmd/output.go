@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+)
+
+// Values for --color.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// ANSI color codes used for highlighting.
+const (
+	ansiRed    = "31"
+	ansiGreen  = "32"
+	ansiYellow = "33"
+	ansiBold   = "1"
+)
+
+// validateOutputFlags checks the global output flags.
+func validateOutputFlags() error {
+	switch colorMode {
+	case colorAuto, colorAlways, colorNever:
+		return nil
+	default:
+		return fmt.Errorf("invalid --color %q (expected auto, always, or never)", colorMode)
+	}
+}
+
+// useColor reports whether output should be colored: always with
+// --color=always, never with --color=never, and otherwise only when stdout
+// is a terminal and NO_COLOR is unset.
+func useColor() bool {
+	switch colorMode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	return isTerminal(os.Stdout)
+}
+
+// isTerminal reports whether f is a terminal rather than a file or pipe.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// colorize wraps s in the given ANSI color when color is enabled.
+func colorize(s, color string) string {
+	if !useColor() {
+		return s
+	}
+	return "\033[" + color + "m" + s + "\033[0m"
+}
+
+// decisionText renders an allow/deny decision as ALLOWED or DENIED.
+func decisionText(allowed bool) string {
+	if allowed {
+		return colorize("ALLOWED", ansiGreen)
+	}
+	return colorize("DENIED", ansiRed)
+}
+
+// infof prints informational output, which --quiet suppresses.
+func infof(format string, a ...any) {
+	if quiet {
+		return
+	}
+	fmt.Printf(format, a...)
+}
@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/upstream"
 	"github.com/spf13/cobra"
 )
 
@@ -12,20 +16,27 @@ var profilesCmd = &cobra.Command{
 	Use:   "profiles",
 	Short: "List available profiles",
 	Long: `List all profiles defined in the configuration file with their descriptions.
-Shows which profile is configured as the default.`,
+Shows which profile is configured as the default.
+
+With --probe, connects to each server and reports how many of its actual
+tools, resources, and prompts each profile exposes, and how long listing them
+took.`,
 	RunE: runProfiles,
 }
 
+var profilesProbe bool
+
 func init() {
 	rootCmd.AddCommand(profilesCmd)
+	profilesCmd.Flags().BoolVar(&profilesProbe, "probe", false, "connect to servers and count the tools, resources, and prompts each profile exposes")
 }
 
 func runProfiles(cmd *cobra.Command, args []string) error {
-	// Expand config path
-	path := expandPath(configPath)
+	// Resolve config path
+	path, _ := resolveConfigPath()
 
 	// Load config
-	cfg, err := config.Load(path)
+	cfg, err := loadConfig(path)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -38,9 +49,17 @@ func runProfiles(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("invalid config: %w", err)
 	}
 
+	var inventories map[string]serverInventory
+	if profilesProbe {
+		if err := cfg.ResolveSecrets(context.Background()); err != nil {
+			return fmt.Errorf("failed to load config: %w", err)
+		}
+		inventories = probeInventories(context.Background(), cfg)
+	}
+
 	// Print header
-	fmt.Printf("Available Profiles\n")
-	fmt.Printf("==================\n\n")
+	infof("Available Profiles\n")
+	infof("==================\n\n")
 
 	// Get sorted profile names for consistent output
 	profileNames := make([]string, 0, len(cfg.Profiles))
@@ -51,34 +70,38 @@ func runProfiles(cmd *cobra.Command, args []string) error {
 
 	// Print each profile
 	for _, name := range profileNames {
-		profile := cfg.Profiles[name]
+		profileCfg := cfg.Profiles[name]
 
 		// Mark default profile
 		defaultMarker := ""
 		if name == cfg.DefaultProfile {
-			defaultMarker = " (default)"
+			defaultMarker = " " + colorize("(default)", ansiYellow)
 		}
 
 		fmt.Printf("Profile: %s%s\n", name, defaultMarker)
 
-		if profile.Description != "" {
-			fmt.Printf("  Description: %s\n", profile.Description)
+		if profileCfg.Description != "" {
+			fmt.Printf("  Description: %s\n", profileCfg.Description)
 		}
 
 		// Count configured servers for this profile
-		serverCount := len(profile.Servers)
-		fmt.Printf("  Servers: %d configured\n", serverCount)
+		serverCount := len(profileCfg.Servers)
+		if profileCfg.Passthrough {
+			fmt.Printf("  Servers: all %d (passthrough, no filtering)\n", len(cfg.Servers))
+		} else {
+			fmt.Printf("  Servers: %d configured\n", serverCount)
+		}
 
 		// Show server names
 		if serverCount > 0 {
 			serverNames := make([]string, 0, serverCount)
-			for serverID := range profile.Servers {
+			for serverID := range profileCfg.Servers {
 				serverNames = append(serverNames, serverID)
 			}
 			sort.Strings(serverNames)
 
 			for _, serverID := range serverNames {
-				serverCfg := profile.Servers[serverID]
+				serverCfg := profileCfg.Servers[serverID]
 				displayName := serverID
 				if globalServerCfg, exists := cfg.Servers[serverID]; exists && globalServerCfg.DisplayName != "" {
 					displayName = globalServerCfg.DisplayName
@@ -110,6 +133,9 @@ func runProfiles(cmd *cobra.Command, args []string) error {
 				}
 
 				fmt.Printf("    - %s (%s)%s\n", displayName, serverID, filterInfo)
+				if inv, ok := inventories[serverID]; ok {
+					fmt.Printf("      %s\n", inv.exposed(profile.NewEngine(cfg, name), serverID))
+				}
 			}
 		}
 
@@ -117,8 +143,104 @@ func runProfiles(cmd *cobra.Command, args []string) error {
 	}
 
 	// Print summary
-	fmt.Printf("Total: %d profile(s)\n", len(cfg.Profiles))
-	fmt.Printf("Default profile: %s\n", cfg.DefaultProfile)
+	infof("Total: %d profile(s)\n", len(cfg.Profiles))
+	infof("Default profile: %s\n", cfg.DefaultProfile)
 
 	return nil
 }
+
+// serverInventory is what a server actually offers, as listed by --probe.
+type serverInventory struct {
+	tools, resources, prompts []string
+	latency                   *upstream.LatencySummary
+	err                       error
+}
+
+// probeInventories connects to every server referenced by a profile and
+// lists its tools, resources, and prompts.
+func probeInventories(ctx context.Context, cfg *config.RootConfig) map[string]serverInventory {
+	manager := upstream.NewManager()
+	manager.SetHTTPClientConfig(cfg.Hub.HTTPClient)
+	manager.SetInheritEnv(cfg.Hub.DefaultInheritEnv())
+	defer manager.Close()
+
+	inventories := make(map[string]serverInventory)
+	for _, profileCfg := range cfg.Profiles {
+		for serverID := range profileCfg.Servers {
+			if _, done := inventories[serverID]; done {
+				continue
+			}
+			serverCfg := cfg.Servers[serverID]
+			if err := manager.Connect(ctx, serverID, &serverCfg); err != nil {
+				inventories[serverID] = serverInventory{err: err}
+				continue
+			}
+			u, _ := manager.Get(serverID)
+			inv := listInventory(ctx, u)
+			inv.latency = u.Status().Latency
+			inventories[serverID] = inv
+		}
+	}
+	return inventories
+}
+
+// listInventory lists everything an upstream advertises.
+func listInventory(ctx context.Context, u *upstream.Upstream) serverInventory {
+	var inv serverInventory
+	if u.SupportsTools() {
+		result, err := u.Session().ListTools(ctx, nil)
+		if err != nil {
+			return serverInventory{err: fmt.Errorf("failed to list tools: %w", err)}
+		}
+		for _, tool := range result.Tools {
+			inv.tools = append(inv.tools, tool.Name)
+		}
+	}
+	if u.SupportsResources() {
+		result, err := u.Session().ListResources(ctx, nil)
+		if err != nil {
+			return serverInventory{err: fmt.Errorf("failed to list resources: %w", err)}
+		}
+		for _, resource := range result.Resources {
+			inv.resources = append(inv.resources, resource.URI)
+		}
+	}
+	if u.SupportsPrompts() {
+		result, err := u.Session().ListPrompts(ctx, nil)
+		if err != nil {
+			return serverInventory{err: fmt.Errorf("failed to list prompts: %w", err)}
+		}
+		for _, prompt := range result.Prompts {
+			inv.prompts = append(inv.prompts, prompt.Name)
+		}
+	}
+	return inv
+}
+
+// exposed describes how much of the inventory the profile behind engine
+// exposes, e.g. "exposes 7/23 tools, 0/0 resources, 2/2 prompts".
+func (inv serverInventory) exposed(engine *profile.Engine, serverID string) string {
+	if inv.err != nil {
+		return colorize("probe failed: ", ansiRed) + inv.err.Error()
+	}
+
+	count := func(names []string, allowed func(serverID, name string) bool) string {
+		n := 0
+		for _, name := range names {
+			if allowed(serverID, name) {
+				n++
+			}
+		}
+		return fmt.Sprintf("%d/%d", n, len(names))
+	}
+	parts := []string{
+		count(inv.tools, engine.IsToolAllowed) + " tools",
+		count(inv.resources, engine.IsResourceAllowed) + " resources",
+		count(inv.prompts, engine.IsPromptAllowed) + " prompts",
+	}
+	exposed := "exposes " + strings.Join(parts, ", ")
+	if inv.latency != nil {
+		exposed += fmt.Sprintf(" (p50 %.1fms, max %.1fms)", inv.latency.P50, inv.latency.Max)
+	}
+	return exposed
+}
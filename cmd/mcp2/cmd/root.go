@@ -2,12 +2,28 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/redact"
 	"github.com/spf13/cobra"
 )
 
+// defaultConfigPath is used when no config file is given and none is discovered.
+const defaultConfigPath = "~/.config/mcp2/config.yaml"
+
+// envProfile names the environment variable --profile falls back to.
+const envProfile = "MCP2_PROFILE"
+
 var (
-	configPath string
-	profileName string
+	configPath     string
+	configOverlays []string
+	configSets     []string
+	profileName    string
+	showSecrets    bool
+	colorMode      string
+	quiet          bool
 )
 
 var rootCmd = &cobra.Command{
@@ -15,6 +31,13 @@ var rootCmd = &cobra.Command{
 	Short: "MCP proxy with profile-based filtering",
 	Long: `mcp2 is a Go-based MCP proxy that sits between MCP clients and upstream servers,
 providing profile-based filtering of tools, resources, and prompts.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// --profile, then $MCP2_PROFILE, then each command's config default
+		if profileName == "" {
+			profileName = os.Getenv(envProfile)
+		}
+		return validateOutputFlags()
+	},
 }
 
 // Execute runs the root command.
@@ -24,6 +47,58 @@ func Execute() error {
 
 func init() {
 	// Global flags
-	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "~/.config/mcp2/config.yaml", "path to config file")
-	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "", "profile to use (overrides config default)")
+	rootCmd.PersistentFlags().StringVarP(&configPath, "config", "c", "",
+		"path to config file, or - for stdin (default: $MCP2_CONFIG, ./mcp2.{yaml,yml,json}, $XDG_CONFIG_HOME/mcp2/config.yaml, "+defaultConfigPath+")")
+	rootCmd.PersistentFlags().StringArrayVar(&configOverlays, "config-overlay", nil,
+		"config file merged over --config, overriding its settings (repeatable; applied in order)")
+	rootCmd.PersistentFlags().StringArrayVar(&configSets, "set", nil,
+		"override a server setting, e.g. servers.github.transport.url=http://staging/mcp (repeatable)")
+	rootCmd.PersistentFlags().StringVarP(&profileName, "profile", "p", "", "profile to use (default: $"+envProfile+", else the config's default)")
+	rootCmd.PersistentFlags().StringVar(&colorMode, "color", colorAuto, "colorize output: auto, always, or never (auto honors NO_COLOR)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "suppress informational headers; print only results and errors")
+	rootCmd.PersistentFlags().BoolVar(&showSecrets, "show-secrets", false, "show secret env/header values instead of masking them")
+}
+
+// displayConfig returns the config to render to the user, with secrets masked
+// unless --show-secrets is set.
+func displayConfig(cfg *config.RootConfig) *config.RootConfig {
+	if showSecrets {
+		return cfg
+	}
+	return redact.Config(cfg)
+}
+
+// resolveConfigPath returns the config file to use and a description of where
+// it came from. An explicit --config always wins; otherwise the standard
+// search paths are tried in order.
+func resolveConfigPath() (path string, source string) {
+	if configPath != "" {
+		return expandPath(configPath), "--config"
+	}
+	if c, ok := config.Discover(); ok {
+		return c.Path, c.Source
+	}
+	return expandPath(defaultConfigPath), "default location"
+}
+
+// loadConfig loads the config at path, merges each --config-overlay over it
+// in order, then applies the --set overrides.
+func loadConfig(path string) (*config.RootConfig, error) {
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, overlayPath := range configOverlays {
+		overlay, err := config.Load(expandPath(overlayPath))
+		if err != nil {
+			return nil, fmt.Errorf("overlay %s: %w", overlayPath, err)
+		}
+		cfg.Merge(overlay)
+	}
+	for _, override := range configSets {
+		if err := cfg.ApplySet(override); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
 }
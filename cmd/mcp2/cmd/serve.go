@@ -2,24 +2,37 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"sort"
+	"sync"
 	"syscall"
 	"time"
 
 	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/logging"
 	"github.com/ain3sh/mcp2/internal/proxy"
+	"github.com/ain3sh/mcp2/internal/redact"
 	"github.com/ain3sh/mcp2/internal/upstream"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 )
 
 var (
-	port  int
-	stdio bool
+	port          int
+	stdio         bool
+	logLevel      string
+	logFormat     string
+	logFile       string
+	logMaxSize    int
+	logMaxBackups int
+	safeMode      bool
 )
 
 var serveCmd = &cobra.Command{
@@ -32,85 +45,373 @@ var serveCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(serveCmd)
 	serveCmd.Flags().IntVarP(&port, "port", "", 8210, "port to listen on")
-	serveCmd.Flags().BoolVarP(&stdio, "stdio", "", false, "use stdio transport instead of HTTP")
+	serveCmd.Flags().BoolVarP(&stdio, "stdio", "", false, "serve the hub over stdio; with --port, serve HTTP as well")
+	serveCmd.Flags().StringVar(&logLevel, "log-level", "", "log level: info or debug (overrides config; default info)")
+	serveCmd.Flags().StringVar(&logFormat, "log-format", "", "log format: text or json (overrides config; default text)")
+	serveCmd.Flags().StringVar(&logFile, "log-file", "", "write logs to this file instead of stderr")
+	serveCmd.Flags().IntVar(&logMaxSize, "log-max-size", 0, "rotate the log file after this many megabytes (0 = never)")
+	serveCmd.Flags().IntVar(&logMaxBackups, "log-max-backups", 3, "number of rotated log files to keep")
+	serveCmd.Flags().BoolVar(&safeMode, "safe-mode", false, "allow only tools annotated read-only in every profile (same as hub.safeMode)")
 }
 
 func runServe(cmd *cobra.Command, args []string) error {
 	ctx := context.Background()
 
-	// Expand config path
-	path := expandPath(configPath)
+	// Resolve config path
+	path, source := resolveConfigPath()
 
-	log.Printf("Loading config from: %s", path)
+	// In stdio mode stdin carries the MCP session, so it can't also carry
+	// the config
+	if stdio && (path == config.StdinPath || slices.Contains(configOverlays, config.StdinPath)) {
+		return fmt.Errorf("--stdio serves MCP over stdin, so the config can't be read from stdin; pass a config file")
+	}
 
 	// Load and validate config
-	cfg, err := config.Load(path)
+	cfg, err := loadConfig(path)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
 
 	cfg.ExpandEnvVars()
 
+	if err := cfg.ResolveSecrets(ctx); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
 	if err := cfg.Validate(); err != nil {
 		return fmt.Errorf("config validation failed: %w", err)
 	}
 
-	// Determine active profile
-	activeProfile := cfg.DefaultProfile
-	if profileName != "" {
-		activeProfile = profileName
+	// Configure logging; flags override the config file
+	if logFormat == "" {
+		logFormat = cfg.Logging.Format
+	}
+	if logLevel == "" {
+		logLevel = cfg.Logging.Level
+	}
+	if !cmd.Flags().Changed("log-file") {
+		logFile = cfg.Logging.File
+	}
+	if !cmd.Flags().Changed("log-max-size") {
+		logMaxSize = cfg.Logging.MaxSizeMB
+	}
+	if !cmd.Flags().Changed("log-max-backups") && cfg.Logging.MaxBackups > 0 {
+		logMaxBackups = cfg.Logging.MaxBackups
 	}
 
-	if _, ok := cfg.Profiles[activeProfile]; !ok {
-		return fmt.Errorf("profile %q not found", activeProfile)
+	var logOutput io.Writer = os.Stderr
+	if logFile != "" {
+		f, err := logging.OpenRotatingFile(expandPath(logFile), int64(logMaxSize)*1024*1024, logMaxBackups)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		logOutput = f
+	}
+	if err := logging.Setup(logOutput, logFormat, logLevel); err != nil {
+		return err
 	}
 
-	log.Printf("Using profile: %s", activeProfile)
+	slog.Info("loaded config", "path", path, "source", source)
+	if safeMode {
+		cfg.Hub.SafeMode = true
+	}
+	if cfg.Hub.SafeMode {
+		slog.Warn("safe mode is active: only tools annotated read-only can be listed or called")
+	}
+	for _, warning := range cfg.Warnings() {
+		slog.Warn("config warning", "warning", warning)
+	}
+
+	// Serve stdio, HTTP, or both when --stdio is combined with --port
+	serveStdio := stdio
+	serveHTTP := !stdio || cmd.Flags().Changed("port")
+
+	// Determine each transport's profile: --profile, else its default
+	profiles := make(map[string]string)
+	if serveStdio {
+		profiles[config.TransportStdio] = cfg.ProfileFor(config.TransportStdio)
+	}
+	if serveHTTP {
+		profiles[config.TransportHTTP] = cfg.ProfileFor(config.TransportHTTP)
+	}
+	for transport, activeProfile := range profiles {
+		if profileName != "" {
+			activeProfile = profileName
+			profiles[transport] = activeProfile
+		}
+		if _, ok := cfg.Profiles[activeProfile]; !ok {
+			return fmt.Errorf("profile %q not found", activeProfile)
+		}
+		slog.Info("using profile", "profile", activeProfile, "transport", transport)
+	}
+
+	// HTTP is served on --port, on each configured listener, or both when
+	// --port is given alongside listeners
+	var listeners []httpListener
+	if serveHTTP {
+		listeners = httpListeners(cfg, profiles[config.TransportHTTP], cmd.Flags().Changed("port"))
+		for _, l := range listeners {
+			if l.name != "" {
+				slog.Info("using profile", "profile", l.profile, "listener", l.name, "address", l.addr)
+			}
+		}
+	}
 
 	// Create upstream manager
 	manager := upstream.NewManager()
+	manager.SetHTTPClientConfig(cfg.Hub.HTTPClient)
+	manager.SetInheritEnv(cfg.Hub.DefaultInheritEnv())
+	if cfg.Hub.AllowElicitation {
+		manager.EnableElicitation()
+	}
+	if cfg.Hub.AllowSampling {
+		manager.EnableSampling()
+	}
 
-	// Connect to all servers
+	// Close servers already connected if a required one fails
+	defer manager.Close()
+
+	// Connect to all servers in parallel, each after its dependsOn
 	for serverID, serverCfg := range cfg.Servers {
-		log.Printf("Connecting to upstream server: %s (%s)", serverID, serverCfg.DisplayName)
-		if err := manager.Connect(ctx, serverID, &serverCfg); err != nil {
-			return fmt.Errorf("failed to connect to server %q: %w", serverID, err)
+		slog.Info("connecting to upstream server", "server", serverID,
+			"displayName", serverCfg.DisplayName, "target", upstreamTarget(&serverCfg))
+	}
+	connectErrs := manager.ConnectAll(ctx, cfg.Servers, cfg.Hub.ConnectConcurrency,
+		func(serverID string, elapsed time.Duration, err error) {
+			if err == nil {
+				slog.Info("connected to upstream server", "server", serverID,
+					"transport", cfg.Servers[serverID].Transport.Kind, "duration", elapsed)
+			}
+		})
+	failed := failedServers(connectErrs)
+	for _, serverID := range failed {
+		err := connectErrs[serverID]
+		serverCfg := cfg.Servers[serverID]
+		if !serverCfg.IsRequired() {
+			slog.Warn("optional upstream server failed to connect, skipping it", "server", serverID,
+				"error", err, "hint", connectHint(err))
+			continue
+		}
+		if hint := connectHint(err); hint != "" {
+			slog.Error("upstream server failed to connect", "server", serverID, "hint", hint)
 		}
-		log.Printf("  Connected to %s via %s transport", serverID, serverCfg.Transport.Kind)
+		// The manager's error already names the server
+		return err
 	}
 
-	defer manager.Close()
+	// Wait for servers that need time after initialize before they can
+	// answer requests. Optional servers that never become ready are
+	// excluded; required ones abort startup, as failing to connect does.
+	notReady := waitForReadiness(ctx, cfg, manager, func(serverID string) {
+		slog.Info("upstream server ready", "server", serverID)
+	})
+	for _, serverID := range failedServers(notReady) {
+		serverCfg := cfg.Servers[serverID]
+		if serverCfg.IsRequired() {
+			// WaitReady's error already names the server
+			return notReady[serverID]
+		}
+		slog.Warn("optional upstream server not ready, excluding it", "server", serverID, "error", notReady[serverID])
+		manager.Remove(serverID)
+	}
+
+	// Explain missing tools: warn about servers the served profiles expect
+	// that aren't connected, now and whenever one disconnects
+	served := servedProfiles(profiles[config.TransportStdio], listeners)
+	warnUnavailableServers(cfg, manager, served)
+	manager.OnDisconnect(func(serverID string, err error) {
+		if err == nil {
+			// Closed at shutdown
+			return
+		}
+		for _, name := range served {
+			if slices.Contains(cfg.ProfileServerIDs(name), serverID) {
+				slog.Warn("server expected by profile disconnected; its tools, resources and prompts are unavailable until it reconnects",
+					"profile", name, "server", serverID)
+			}
+		}
+	})
+
+	// The hub, per-server endpoints, or both must be enabled
+	exposePerServer := false
+	for _, l := range listeners {
+		exposePerServer = exposePerServer || cfg.ExposePerServerFor(l.profile)
+	}
+	if !cfg.Hub.Enabled && !exposePerServer {
+		return fmt.Errorf("nothing to serve: enable the hub or exposePerServer in config")
+	}
 
-	// Create hub server if enabled
-	if !cfg.Hub.Enabled {
-		return fmt.Errorf("hub must be enabled in config")
+	if serveStdio && !cfg.Hub.Enabled {
+		return fmt.Errorf("stdio mode serves the hub, which is disabled in config")
 	}
 
-	hub := proxy.NewHub(cfg, manager, activeProfile)
+	// Stop every transport on SIGINT/SIGTERM, or once any of them stops
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	if stdio {
-		// Run in stdio mode
-		log.Println("Starting mcp2 hub in stdio mode")
-		return hub.Server().Run(ctx, &mcp.StdioTransport{})
+	endpoints := make(map[string]proxy.DecisionCounter)
+	defer logDecisionCounts(endpoints)
+
+	var httpServers []*http.Server
+	for _, l := range listeners {
+		httpServers = append(httpServers, &http.Server{
+			Addr:    l.addr,
+			Handler: newServeMux(cfg, manager, l, endpoints),
+		})
 	}
 
-	// Run in HTTP mode
-	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	// Build the stdio hub before starting any transport so the endpoints
+	// map is complete before transports start reading it
+	var stdioHub *proxy.Hub
+	if serveStdio {
+		stdioHub = proxy.NewHub(cfg, manager, profiles[config.TransportStdio])
+		stdioHub.SetDebug(logLevel == logging.LevelDebug)
+		endpoints["stdio"] = stdioHub
+	}
 
-	// Create HTTP multiplexer for routing
-	mux := http.NewServeMux()
+	errs := make(chan error, 1+len(httpServers))
+	running := 0
+
+	if stdioHub != nil {
+		running++
+		go func() {
+			slog.Info("starting mcp2 hub", "transport", "stdio", "profile", profiles[config.TransportStdio])
+			err := stdioHub.Server().Run(ctx, &mcp.StdioTransport{})
+			if ctx.Err() != nil {
+				err = nil
+			}
+			slog.Info("stdio transport stopped")
+			errs <- err
+		}()
+	}
+
+	for _, httpServer := range httpServers {
+		running++
+		go func() {
+			if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				errs <- fmt.Errorf("server error: %w", err)
+				return
+			}
+			errs <- nil
+		}()
+	}
+
+	// Wait for a signal or the first transport to stop, then stop the rest
+	var firstErr error
+	select {
+	case <-ctx.Done():
+	case firstErr = <-errs:
+		running--
+	}
+	slog.Info("shutting down server")
+	stop()
+
+	if len(httpServers) > 0 {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		for _, httpServer := range httpServers {
+			if err := httpServer.Shutdown(shutdownCtx); err != nil {
+				slog.Error("HTTP server shutdown error", "address", httpServer.Addr, "error", err)
+			}
+		}
+	}
+	for ; running > 0; running-- {
+		if err := <-errs; err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if firstErr != nil {
+		return firstErr
+	}
+
+	slog.Info("server stopped")
+	return nil
+}
 
-	// Register hub endpoint
-	log.Printf("Registering hub endpoint: http://%s/mcp", addr)
-	hubHandler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
-		return hub.Server()
-	}, nil)
-	mux.Handle("/mcp", hubHandler)
+// httpListener is an address serving one profile over HTTP.
+type httpListener struct {
+	// name is the listener's name in config, or "" for the --port listener
+	name    string
+	addr    string
+	profile string
+}
+
+// endpoint names an MCP endpoint path on l for logs.
+func (l httpListener) endpoint(path string) string {
+	if l.name == "" {
+		return path
+	}
+	return l.name + ":" + path
+}
+
+// httpListeners returns the configured listeners sorted by name, with those
+// without a profile serving httpProfile. The --port listener serving
+// httpProfile comes first when there are no configured listeners or
+// withPort is set.
+func httpListeners(cfg *config.RootConfig, httpProfile string, withPort bool) []httpListener {
+	var listeners []httpListener
+	if len(cfg.Listeners) == 0 || withPort {
+		listeners = append(listeners, httpListener{addr: fmt.Sprintf("127.0.0.1:%d", port), profile: httpProfile})
+	}
 
-	// Register per-server endpoints if enabled
-	if cfg.ExposePerServer {
-		log.Println("Per-server endpoints enabled")
+	names := make([]string, 0, len(cfg.Listeners))
+	for name := range cfg.Listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		l := cfg.Listeners[name]
+		activeProfile := l.Profile
+		if activeProfile == "" {
+			activeProfile = httpProfile
+		}
+		listeners = append(listeners, httpListener{name: name, addr: l.Address, profile: activeProfile})
+	}
+	return listeners
+}
+
+// newServeMux builds the HTTP handler for l serving /readyz, /statusz,
+// /metrics, and the hub and per-server endpoints enabled in cfg with l's
+// profile, adding each MCP endpoint to endpoints.
+func newServeMux(cfg *config.RootConfig, manager *upstream.Manager, l httpListener, endpoints map[string]proxy.DecisionCounter) *http.ServeMux {
+	mux := http.NewServeMux()
+	activeProfile, addr := l.profile, l.addr
+
+	// Readiness checks come from load balancers, which don't carry tokens
+	mux.Handle("/readyz", proxy.ReadyHandler(cfg, manager))
+	// Status and metrics name servers, so they take a hub token
+	mux.Handle("/statusz", proxy.RequireToken(cfg.Auth.Tokens, config.AuthScopeHub, proxy.StatusHandler(manager, endpoints)))
+	mux.Handle("/metrics", proxy.RequireToken(cfg.Auth.Tokens, config.AuthScopeHub, proxy.MetricsHandler(manager, endpoints)))
+
+	// Register hub endpoint if enabled
+	if cfg.Hub.Enabled {
+		hub := proxy.NewHub(cfg, manager, activeProfile)
+		hub.SetDebug(logLevel == logging.LevelDebug)
+
+		slog.Info("registering hub endpoint", "url", fmt.Sprintf("http://%s/mcp", addr))
+		hubHandler := hub.HTTPHandler(func(*http.Request) string { return activeProfile })
+		if cfg.Hub.Compress {
+			hubHandler = proxy.Compress(cfg.Hub.CompressMinBytes, hubHandler)
+		}
+		mux.Handle("/mcp", proxy.RequireToken(cfg.Auth.Tokens, config.AuthScopeHub, hubHandler))
+		endpoints[l.endpoint("/mcp")] = hub
+	}
+
+	// Register per-server endpoints if enabled for the profile, for the
+	// servers it includes
+	if cfg.ExposePerServerFor(activeProfile) {
+		slog.Info("per-server endpoints enabled", "profile", activeProfile)
 		for _, u := range manager.List() {
+			// Replica group members are only reachable through the hub
+			if _, ok := cfg.ReplicaGroupOf(u.ID); ok {
+				continue
+			}
+			if !cfg.ProfileIncludes(activeProfile, u.ID) {
+				slog.Debug("server not in profile, skipping its endpoint", "server", u.ID, "profile", activeProfile)
+				continue
+			}
+
 			// Create proxy and capture it properly in closure
 			serverProxy := proxy.NewPerServerProxy(cfg, u, activeProfile)
 			path := fmt.Sprintf("/mcp/%s", u.ID)
@@ -120,38 +421,139 @@ func runServe(cmd *cobra.Command, args []string) error {
 			serverHandler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server {
 				return sp.Server()
 			}, nil)
-			mux.Handle(path, serverHandler)
+			mux.Handle(path, proxy.RequireToken(cfg.Auth.Tokens, u.ID, serverHandler))
+			endpoints[l.endpoint(path)] = sp
+
+			slog.Info("registered server endpoint", "server", u.ID, "url", fmt.Sprintf("http://%s%s", addr, path))
+		}
+	}
+
+	return mux
+}
 
-			log.Printf("  Registered server endpoint: http://%s%s", addr, path)
+// waitForReadiness waits, in parallel, for each connected server with an
+// initializationTimeout to become ready, calling onReady (if non-nil) for
+// each that does, and returns the errors of those that didn't within it.
+func waitForReadiness(ctx context.Context, cfg *config.RootConfig, manager *upstream.Manager, onReady func(serverID string)) map[string]error {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		notReady = make(map[string]error)
+	)
+	for serverID, serverCfg := range cfg.Servers {
+		if serverCfg.InitializationTimeout == 0 {
+			continue
 		}
+		if _, err := manager.Get(serverID); err != nil {
+			// An optional server that was skipped
+			continue
+		}
+		timeout := time.Duration(serverCfg.InitializationTimeout) * time.Second
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := manager.WaitReady(ctx, serverID, timeout); err != nil {
+				mu.Lock()
+				notReady[serverID] = err
+				mu.Unlock()
+				return
+			}
+			if onReady != nil {
+				onReady(serverID)
+			}
+		}()
 	}
+	wg.Wait()
+	return notReady
+}
 
-	// Create HTTP server
-	httpServer := &http.Server{
-		Addr:    addr,
-		Handler: mux,
+// logDecisionCounts logs, per endpoint, how often the profile allowed and
+// denied each server's tools, resources, and prompts while serving.
+func logDecisionCounts(endpoints map[string]proxy.DecisionCounter) {
+	paths := make([]string, 0, len(endpoints))
+	for path := range endpoints {
+		paths = append(paths, path)
 	}
+	sort.Strings(paths)
 
-	// Handle graceful shutdown
-	go func() {
-		sigint := make(chan os.Signal, 1)
-		signal.Notify(sigint, os.Interrupt, syscall.SIGTERM)
-		<-sigint
+	for _, path := range paths {
+		for _, c := range endpoints[path].DecisionCounts() {
+			slog.Info("profile decisions", "endpoint", path, "profile", c.Profile, "server", c.Server,
+				"component", c.Component, "allowed", c.Allowed, "denied", c.Denied)
+		}
+	}
+}
 
-		log.Println("Shutting down server...")
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
+// connectHint suggests where to look for the cause of a Manager.Connect
+// error, or returns "" if the error doesn't say which phase failed.
+func connectHint(err error) string {
+	switch {
+	case errors.Is(err, upstream.ErrDial):
+		return "the server could not be reached; check its command or URL and the network"
+	case errors.Is(err, upstream.ErrInitialize):
+		return "the server was reached but MCP initialization failed; check its protocol version, credentials, and that it is an MCP server"
+	}
+	return ""
+}
 
-		if err := httpServer.Shutdown(shutdownCtx); err != nil {
-			log.Printf("HTTP server shutdown error: %v", err)
+// servedProfiles returns the names of the profiles served over stdio, if
+// any, and on each HTTP listener, sorted.
+func servedProfiles(stdioProfile string, listeners []httpListener) []string {
+	var names []string
+	if stdioProfile != "" {
+		names = append(names, stdioProfile)
+	}
+	for _, l := range listeners {
+		names = append(names, l.profile)
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// warnUnavailableServers logs, for each named profile, the servers it
+// expects that aren't connected, such as optional ones that failed to start.
+func warnUnavailableServers(cfg *config.RootConfig, manager *upstream.Manager, profileNames []string) {
+	for _, name := range profileNames {
+		var missing []string
+		for _, serverID := range cfg.ProfileServerIDs(name) {
+			if u, err := manager.Get(serverID); err != nil || !u.Connected() {
+				missing = append(missing, serverID)
+			}
+		}
+		if len(missing) > 0 {
+			slog.Warn("profile expects servers that aren't connected; their tools, resources and prompts are unavailable",
+				"profile", name, "servers", missing)
 		}
-	}()
+	}
+}
 
-	// Start server
-	if err := httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-		return fmt.Errorf("server error: %w", err)
+// failedServers returns the IDs of the servers in errs, sorted, with servers
+// that failed only because a dependency did last so the root cause is
+// reported first.
+func failedServers(errs map[string]error) []string {
+	ids := make([]string, 0, len(errs))
+	for serverID := range errs {
+		ids = append(ids, serverID)
 	}
+	sort.Slice(ids, func(i, j int) bool {
+		di := errors.Is(errs[ids[i]], upstream.ErrDependency)
+		dj := errors.Is(errs[ids[j]], upstream.ErrDependency)
+		if di != dj {
+			return dj
+		}
+		return ids[i] < ids[j]
+	})
+	return ids
+}
 
-	log.Println("Server stopped")
-	return nil
+// upstreamTarget describes where an upstream server lives for logging, with
+// secrets in URLs masked unless --show-secrets is set.
+func upstreamTarget(serverCfg *config.ServerConfig) string {
+	if serverCfg.Transport.Kind == "http" {
+		if showSecrets {
+			return serverCfg.Transport.URL
+		}
+		return redact.URL(serverCfg.Transport.URL)
+	}
+	return serverCfg.Transport.Command
 }
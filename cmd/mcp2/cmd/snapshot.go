@@ -0,0 +1,397 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/proxy"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+var (
+	snapshotFile  string
+	snapshotCheck bool
+	snapshotJSON  bool
+)
+
+var snapshotCmd = &cobra.Command{
+	Use:   "snapshot [--check]",
+	Short: "Pin the tools, resources, and prompts a profile exposes",
+	Long: `Connect to the configured servers, list what the hub exposes for the profile
+after filtering and prefixing, and write the names and schemas to a snapshot
+file. The snapshot also records the unfiltered catalog of each server, which
+"mcp2 lint" checks profile filters against.
+
+With --check, compare the live set against the snapshot instead and exit
+non-zero if anything was added, removed, or changed, so CI notices when an
+upstream update alters the exposed surface.
+
+Example:
+  mcp2 snapshot -p safe -f mcp2.snapshot.json
+  mcp2 snapshot -p safe -f mcp2.snapshot.json --check --json`,
+	Args: cobra.NoArgs,
+	RunE: runSnapshot,
+}
+
+func init() {
+	rootCmd.AddCommand(snapshotCmd)
+	snapshotCmd.Flags().StringVarP(&snapshotFile, "file", "f", "mcp2.snapshot.json", "snapshot file to write or check against")
+	snapshotCmd.Flags().BoolVar(&snapshotCheck, "check", false, "diff the live set against the snapshot instead of writing it")
+	snapshotCmd.Flags().BoolVar(&snapshotJSON, "json", false, "print the diff as JSON (with --check)")
+}
+
+// surfaceSnapshot is the exposed surface of a profile: what clients see and
+// depend on. Descriptions are left out so rewording doesn't count as drift.
+type surfaceSnapshot struct {
+	Profile   string          `json:"profile"`
+	Tools     []snapshotEntry `json:"tools"`
+	Resources []snapshotEntry `json:"resources"`
+	Prompts   []snapshotEntry `json:"prompts"`
+
+	// Catalog is every server's unfiltered names, for mcp2 lint. It is not
+	// part of the surface, so --check ignores it.
+	Catalog profile.Catalog `json:"catalog,omitempty"`
+}
+
+// snapshotEntry is one exposed tool, resource (named by URI), or prompt.
+type snapshotEntry struct {
+	Name         string                `json:"name"`
+	InputSchema  any                   `json:"inputSchema,omitempty"`
+	OutputSchema any                   `json:"outputSchema,omitempty"`
+	MIMEType     string                `json:"mimeType,omitempty"`
+	Arguments    []*mcp.PromptArgument `json:"arguments,omitempty"`
+}
+
+// snapshotChange is a difference between a snapshot and the live set.
+type snapshotChange struct {
+	Kind   string `json:"kind"`   // tool, resource, or prompt
+	Change string `json:"change"` // added, removed, or changed
+	Name   string `json:"name"`
+	// Fields lists what changed, for changed entries
+	Fields []string `json:"fields,omitempty"`
+}
+
+func runSnapshot(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	path, _ := resolveConfigPath()
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.ExpandEnvVars()
+
+	if err := cfg.ResolveSecrets(ctx); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	activeProfile := cfg.DefaultProfile
+	if profileName != "" {
+		activeProfile = profileName
+	}
+	if _, ok := cfg.Profiles[activeProfile]; !ok {
+		return fmt.Errorf("profile %q not found", activeProfile)
+	}
+
+	live, err := captureSnapshot(ctx, cfg, activeProfile)
+	if err != nil {
+		return err
+	}
+
+	if !snapshotCheck {
+		data, err := json.MarshalIndent(live, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode snapshot: %w", err)
+		}
+		if err := os.WriteFile(snapshotFile, append(data, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write snapshot: %w", err)
+		}
+		infof("Wrote %s: %d tools, %d resources, %d prompts\n",
+			snapshotFile, len(live.Tools), len(live.Resources), len(live.Prompts))
+		return nil
+	}
+
+	data, err := os.ReadFile(snapshotFile)
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var pinned surfaceSnapshot
+	if err := json.Unmarshal(data, &pinned); err != nil {
+		return fmt.Errorf("failed to parse snapshot %s: %w", snapshotFile, err)
+	}
+	if pinned.Profile != live.Profile {
+		return fmt.Errorf("snapshot %s is for profile %q, not %q", snapshotFile, pinned.Profile, live.Profile)
+	}
+
+	changes := diffSnapshots(&pinned, live)
+	if snapshotJSON {
+		data, _ := json.MarshalIndent(struct {
+			Profile string           `json:"profile"`
+			Changes []snapshotChange `json:"changes"`
+		}{live.Profile, changes}, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		printSnapshotChanges(changes)
+	}
+
+	if len(changes) > 0 {
+		// Drift is a result, not a usage error
+		cmd.SilenceUsage = true
+		return fmt.Errorf("%d change(s) against %s", len(changes), snapshotFile)
+	}
+	return nil
+}
+
+// captureSnapshot connects to the configured servers and lists what a hub
+// for activeProfile exposes.
+func captureSnapshot(ctx context.Context, cfg *config.RootConfig, activeProfile string) (*surfaceSnapshot, error) {
+	// Only the lists matter here, not per-request logs
+	quietLogger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	manager := upstream.NewManager()
+	manager.SetLogger(quietLogger)
+	manager.SetHTTPClientConfig(cfg.Hub.HTTPClient)
+	manager.SetInheritEnv(cfg.Hub.DefaultInheritEnv())
+	defer manager.Close()
+
+	connectErrs := manager.ConnectAll(ctx, cfg.Servers, cfg.Hub.ConnectConcurrency, nil)
+	failed := failedServers(connectErrs)
+	if len(failed) > 0 {
+		return nil, connectErrs[failed[0]]
+	}
+	notReady := waitForReadiness(ctx, cfg, manager, nil)
+	if failed := failedServers(notReady); len(failed) > 0 {
+		return nil, notReady[failed[0]]
+	}
+
+	catalog, err := captureCatalog(ctx, cfg, manager)
+	if err != nil {
+		return nil, err
+	}
+
+	hub := proxy.NewHub(cfg, manager, activeProfile)
+	hub.SetLogger(quietLogger)
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	serverSession, err := hub.Server().Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start hub: %w", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp2-snapshot", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to hub: %w", err)
+	}
+	defer session.Close()
+
+	snap := &surfaceSnapshot{
+		Profile:   activeProfile,
+		Tools:     []snapshotEntry{},
+		Resources: []snapshotEntry{},
+		Prompts:   []snapshotEntry{},
+		Catalog:   catalog,
+	}
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	for _, tool := range tools.Tools {
+		snap.Tools = append(snap.Tools, snapshotEntry{
+			Name:         tool.Name,
+			InputSchema:  tool.InputSchema,
+			OutputSchema: tool.OutputSchema,
+		})
+	}
+
+	resources, err := session.ListResources(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	for _, resource := range resources.Resources {
+		snap.Resources = append(snap.Resources, snapshotEntry{Name: resource.URI, MIMEType: resource.MIMEType})
+	}
+
+	prompts, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompts: %w", err)
+	}
+	for _, prompt := range prompts.Prompts {
+		snap.Prompts = append(snap.Prompts, snapshotEntry{Name: prompt.Name, Arguments: prompt.Arguments})
+	}
+
+	for _, entries := range [][]snapshotEntry{snap.Tools, snap.Resources, snap.Prompts} {
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	}
+	return snap, nil
+}
+
+// captureCatalog lists every connected server's tools, resources, and
+// prompts, unfiltered. Replica group members are recorded under their
+// group's ID, which is what profiles refer to.
+func captureCatalog(ctx context.Context, cfg *config.RootConfig, manager *upstream.Manager) (profile.Catalog, error) {
+	catalog := make(profile.Catalog)
+	for _, u := range manager.List() {
+		var entry profile.ServerCatalog
+		if u.SupportsTools() {
+			result, err := u.Session().ListTools(ctx, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list tools of %q: %w", u.ID, err)
+			}
+			for _, tool := range result.Tools {
+				entry.Tools = append(entry.Tools, tool.Name)
+			}
+		}
+		if u.SupportsResources() {
+			result, err := u.Session().ListResources(ctx, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list resources of %q: %w", u.ID, err)
+			}
+			for _, resource := range result.Resources {
+				entry.Resources = append(entry.Resources, resource.URI)
+			}
+		}
+		if u.SupportsPrompts() {
+			result, err := u.Session().ListPrompts(ctx, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list prompts of %q: %w", u.ID, err)
+			}
+			for _, prompt := range result.Prompts {
+				entry.Prompts = append(entry.Prompts, prompt.Name)
+			}
+		}
+
+		serverID := u.ID
+		if groupID, ok := cfg.ReplicaGroupOf(u.ID); ok {
+			serverID = groupID
+		}
+		merged := catalog[serverID]
+		merged.Tools = mergeNames(merged.Tools, entry.Tools)
+		merged.Resources = mergeNames(merged.Resources, entry.Resources)
+		merged.Prompts = mergeNames(merged.Prompts, entry.Prompts)
+		catalog[serverID] = merged
+	}
+	return catalog, nil
+}
+
+// mergeNames returns the sorted union of a and b, never nil, so empty
+// lists are written as [] rather than null.
+func mergeNames(a, b []string) []string {
+	names := append(append([]string{}, a...), b...)
+	sort.Strings(names)
+	return slices.Compact(names)
+}
+
+// diffSnapshots returns what changed from pinned to live, tools first, then
+// resources and prompts, each by name.
+func diffSnapshots(pinned, live *surfaceSnapshot) []snapshotChange {
+	var changes []snapshotChange
+	changes = append(changes, diffEntries("tool", pinned.Tools, live.Tools)...)
+	changes = append(changes, diffEntries("resource", pinned.Resources, live.Resources)...)
+	changes = append(changes, diffEntries("prompt", pinned.Prompts, live.Prompts)...)
+	return changes
+}
+
+func diffEntries(kind string, pinned, live []snapshotEntry) []snapshotChange {
+	before := make(map[string]snapshotEntry, len(pinned))
+	for _, e := range pinned {
+		before[e.Name] = e
+	}
+	after := make(map[string]snapshotEntry, len(live))
+	for _, e := range live {
+		after[e.Name] = e
+	}
+
+	names := make([]string, 0, len(before)+len(after))
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []snapshotChange
+	for _, name := range names {
+		old, inBefore := before[name]
+		cur, inAfter := after[name]
+		switch {
+		case !inBefore:
+			changes = append(changes, snapshotChange{Kind: kind, Change: "added", Name: name})
+		case !inAfter:
+			changes = append(changes, snapshotChange{Kind: kind, Change: "removed", Name: name})
+		default:
+			if fields := changedFields(old, cur); len(fields) > 0 {
+				changes = append(changes, snapshotChange{Kind: kind, Change: "changed", Name: name, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// changedFields names the fields that differ between a and b. Values are
+// compared as JSON, so a schema read back from a snapshot file equals the
+// one it was written from.
+func changedFields(a, b snapshotEntry) []string {
+	var fields []string
+	compare := func(field string, x, y any) {
+		xj, _ := json.Marshal(x)
+		yj, _ := json.Marshal(y)
+		if !bytes.Equal(canonicalJSON(xj), canonicalJSON(yj)) {
+			fields = append(fields, field)
+		}
+	}
+	compare("inputSchema", a.InputSchema, b.InputSchema)
+	compare("outputSchema", a.OutputSchema, b.OutputSchema)
+	compare("mimeType", a.MIMEType, b.MIMEType)
+	compare("arguments", a.Arguments, b.Arguments)
+	return fields
+}
+
+// canonicalJSON re-encodes data through a generic value, which sorts object
+// keys, so documents that differ only in key order compare equal.
+func canonicalJSON(data []byte) []byte {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	out, _ := json.Marshal(v)
+	return out
+}
+
+func printSnapshotChanges(changes []snapshotChange) {
+	if len(changes) == 0 {
+		fmt.Printf("No changes against %s\n", snapshotFile)
+		return
+	}
+	for _, c := range changes {
+		switch c.Change {
+		case "added":
+			fmt.Printf("%s %s %s\n", colorize("+", ansiGreen), c.Kind, c.Name)
+		case "removed":
+			fmt.Printf("%s %s %s\n", colorize("-", ansiRed), c.Kind, c.Name)
+		default:
+			fmt.Printf("%s %s %s (%s changed)\n", colorize("~", ansiYellow), c.Kind, c.Name, strings.Join(c.Fields, ", "))
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/proxy"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/spf13/cobra"
+)
+
+var (
+	statusJSON    bool
+	statusSamples int
+	statusPort    int
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Report upstream connection state and hub settings",
+	Long: `Connect to each configured upstream server and report its connection state,
+server info, capabilities, last error, and latency percentiles over --samples
+pings, along with the active profile and hub settings. Exits non-zero if any server cannot be connected, so it can be
+used as a health check.
+
+By default status probes the upstreams directly. With --port it instead reads
+/statusz from "mcp2 serve" on that port, which reports the sessions serving
+clients, with their request counts and cache hits, misses, evictions, and
+sizes, and how often each endpoint's profile allowed and denied names.`,
+	RunE: runStatus,
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+	statusCmd.Flags().BoolVar(&statusJSON, "json", false, "output status as JSON")
+	statusCmd.Flags().IntVar(&statusSamples, "samples", 5, "pings sent to each server to measure latency")
+	statusCmd.Flags().IntVar(&statusPort, "port", 0, "read status from mcp2 serve on this port instead of probing the servers")
+	addHubAuthFlags(statusCmd)
+}
+
+// statusDocument is the --json output of status.
+type statusDocument struct {
+	Profile         string            `json:"profile"`
+	Hub             statusHubSettings `json:"hub"`
+	ExposePerServer bool              `json:"exposePerServer"`
+	Upstreams       []upstream.Status `json:"upstreams"`
+	// Unavailable lists the servers the profile expects that are down.
+	Unavailable []string `json:"unavailable,omitempty"`
+	// Decisions holds, per endpoint of a running proxy, how often its
+	// profile allowed and denied each server's names; only with --port.
+	Decisions map[string][]profile.DecisionCount `json:"decisions,omitempty"`
+	CheckedAt time.Time                          `json:"checkedAt"`
+}
+
+// statusHubSettings are the hub settings in effect for the active profile.
+type statusHubSettings struct {
+	Enabled         bool   `json:"enabled"`
+	PrefixServerIDs bool   `json:"prefixServerIDs"`
+	PrefixSeparator string `json:"prefixSeparator,omitempty"`
+	SafeMode        bool   `json:"safeMode"`
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+
+	// Resolve config path
+	path, _ := resolveConfigPath()
+
+	// Load config
+	cfg, err := loadConfig(path)
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	cfg.ExpandEnvVars()
+
+	if err := cfg.ResolveSecrets(ctx); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	// Determine active profile
+	activeProfile := cfg.DefaultProfile
+	if profileName != "" {
+		activeProfile = profileName
+	}
+
+	if _, ok := cfg.Profiles[activeProfile]; !ok {
+		return fmt.Errorf("profile %q not found", activeProfile)
+	}
+
+	serverIDs := make([]string, 0, len(cfg.Servers))
+	for serverID := range cfg.Servers {
+		serverIDs = append(serverIDs, serverID)
+	}
+	sort.Strings(serverIDs)
+
+	var statuses []upstream.Status
+	var decisions map[string][]profile.DecisionCount
+	var failed int
+	if statusPort != 0 {
+		report, err := fetchStatusReport(ctx, serverIDs)
+		if err != nil {
+			return err
+		}
+		statuses, decisions = report.Upstreams, report.Decisions
+		for _, st := range statuses {
+			if !st.Connected {
+				failed++
+			}
+		}
+	} else {
+		statuses, failed = probeUpstreams(ctx, cfg, serverIDs, statusSamples)
+	}
+
+	prefixEnabled, separator := cfg.PrefixSettings(activeProfile)
+	doc := statusDocument{
+		Profile: activeProfile,
+		Hub: statusHubSettings{
+			Enabled:         cfg.Hub.Enabled,
+			PrefixServerIDs: prefixEnabled,
+			SafeMode:        cfg.Hub.SafeMode,
+		},
+		ExposePerServer: cfg.ExposePerServerFor(activeProfile),
+		Upstreams:       statuses,
+		Decisions:       decisions,
+		CheckedAt:       time.Now().UTC(),
+	}
+	if prefixEnabled {
+		doc.Hub.PrefixSeparator = separator
+	}
+	down := make(map[string]bool)
+	for _, st := range statuses {
+		down[st.ID] = !st.Connected
+	}
+	for _, serverID := range cfg.ProfileServerIDs(activeProfile) {
+		if down[serverID] {
+			doc.Unavailable = append(doc.Unavailable, serverID)
+		}
+	}
+
+	if statusJSON {
+		data, _ := json.MarshalIndent(doc, "", "  ")
+		fmt.Println(string(data))
+	} else {
+		infof("Profile: %s\n", doc.Profile)
+		infof("Hub enabled: %v (prefix server IDs: %v)\n", doc.Hub.Enabled, doc.Hub.PrefixServerIDs)
+		if doc.Hub.SafeMode {
+			fmt.Println(colorize("Safe mode: ACTIVE (only read-only tools are allowed)", ansiRed))
+		}
+		infof("Per-server endpoints: %v\n", doc.ExposePerServer)
+		if len(doc.Unavailable) > 0 {
+			fmt.Println(colorize(fmt.Sprintf("Profile %s expects servers that are down; their tools, resources and prompts are unavailable: %s",
+				doc.Profile, strings.Join(doc.Unavailable, ", ")), ansiRed))
+		}
+		infof("\n")
+		for _, s := range statuses {
+			state := colorize("connected", ansiGreen)
+			if !s.Connected {
+				state = colorize("down", ansiRed)
+			}
+			fmt.Printf("%s: %s", s.ID, state)
+			if s.Connected {
+				fmt.Printf(" (%s %s, protocol %s)", s.ServerName, s.ServerVersion, s.ProtocolVersion)
+			}
+			fmt.Println()
+			if l := s.Latency; l != nil {
+				fmt.Printf("  latency: p50 %.1fms, p90 %.1fms, p99 %.1fms over %d requests\n", l.P50, l.P90, l.P99, l.Count)
+			}
+			if w := s.QueueWait; w != nil {
+				fmt.Printf("  queue: %d waiting, %d refused as overloaded; wait p50 %.1fms, p99 %.1fms\n", s.Queued, s.Rejected, w.P50, w.P99)
+			}
+			printCacheStats("tool cache", s.Caches.Tools)
+			printCacheStats("resource cache", s.Caches.Resources)
+			if s.LastError != "" {
+				fmt.Printf("  error: %s\n", s.LastError)
+			}
+		}
+		printDecisions(doc.Decisions)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d servers are down", failed, len(serverIDs))
+	}
+	return nil
+}
+
+// fetchStatusReport reads the running proxy's /statusz, adding configured
+// servers it doesn't hold, such as optional ones that failed to start, to
+// its upstreams as down.
+func fetchStatusReport(ctx context.Context, serverIDs []string) (*proxy.StatusReport, error) {
+	url := fmt.Sprintf("http://127.0.0.1:%d/statusz", statusPort)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	headers, err := hubHeaders()
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range headers {
+		req.Header[name] = values
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reach mcp2 at %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("mcp2 at %s answered %s: %s", url, resp.Status, strings.TrimSpace(string(body)))
+	}
+	var report proxy.StatusReport
+	if err := json.NewDecoder(resp.Body).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode status from %s: %w", url, err)
+	}
+
+	held := make(map[string]bool, len(report.Upstreams))
+	for _, st := range report.Upstreams {
+		held[st.ID] = true
+	}
+	for _, serverID := range serverIDs {
+		if !held[serverID] {
+			report.Upstreams = append(report.Upstreams, upstream.Status{ID: serverID, LastError: "not connected by the running proxy"})
+		}
+	}
+	sort.Slice(report.Upstreams, func(i, j int) bool { return report.Upstreams[i].ID < report.Upstreams[j].ID })
+	return &report, nil
+}
+
+// printCacheStats prints a definition cache's counters, if it was used.
+func printCacheStats(name string, c upstream.CacheStats) {
+	if c.Hits == 0 && c.Misses == 0 && c.Size == 0 {
+		return
+	}
+	fmt.Printf("  %s: %d cached; %d hits, %d misses, %d evicted\n", name, c.Size, c.Hits, c.Misses, c.Evictions)
+}
+
+// printDecisions prints each endpoint's profile decision counts, sorted by
+// endpoint.
+func printDecisions(decisions map[string][]profile.DecisionCount) {
+	if len(decisions) == 0 {
+		return
+	}
+	endpoints := make([]string, 0, len(decisions))
+	for endpoint := range decisions {
+		endpoints = append(endpoints, endpoint)
+	}
+	sort.Strings(endpoints)
+
+	infof("\nProfile decisions:\n")
+	for _, endpoint := range endpoints {
+		for _, c := range decisions[endpoint] {
+			fmt.Printf("  %s (%s): %s %ss: %d allowed, %d denied\n", endpoint, c.Profile, c.Server, c.Component, c.Allowed, c.Denied)
+		}
+	}
+}
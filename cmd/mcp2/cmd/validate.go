@@ -1,9 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/ain3sh/mcp2/internal/config"
 	"github.com/spf13/cobra"
@@ -16,18 +20,37 @@ var validateCmd = &cobra.Command{
 	RunE:  runValidate,
 }
 
+var (
+	validateJSON  bool
+	checkCommands bool
+)
+
 func init() {
 	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "output the result as JSON, with errors and warnings listed separately")
+	validateCmd.Flags().BoolVar(&checkCommands, "check-commands", false, "check that stdio server commands exist on this host's PATH")
+}
+
+// validateResult is the --json output of validate.
+type validateResult struct {
+	Path     string   `json:"path"`
+	Valid    bool     `json:"valid"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
 }
 
 func runValidate(cmd *cobra.Command, args []string) error {
-	// Expand config path
-	path := expandPath(configPath)
+	// Resolve config path
+	path, source := resolveConfigPath()
 
-	fmt.Printf("Validating config file: %s\n", path)
+	if validateJSON {
+		return runValidateJSON(path)
+	}
+
+	infof("Validating config file: %s (from %s)\n", path, source)
 
 	// Load config
-	cfg, err := config.Load(path)
+	cfg, err := loadConfig(path)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -40,9 +63,28 @@ func runValidate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	if checkCommands {
+		if errs := cfg.CheckCommands(); len(errs) > 0 {
+			return fmt.Errorf("validation failed: %w", errors.Join(errs...))
+		}
+	}
+
+	warnings := cfg.Warnings()
+	if len(warnings) > 0 {
+		fmt.Println(colorize("Warnings:", ansiYellow))
+		for _, w := range warnings {
+			fmt.Printf("  - %s\n", w)
+		}
+	}
+
+	if quiet {
+		return nil
+	}
+
 	fmt.Println("Configuration is valid!")
 	fmt.Printf("  Default profile: %s\n", cfg.DefaultProfile)
 	fmt.Printf("  Servers: %d\n", len(cfg.Servers))
+	printServers("    ", displayConfig(cfg))
 	fmt.Printf("  Profiles: %d\n", len(cfg.Profiles))
 	fmt.Printf("  Hub enabled: %v\n", cfg.Hub.Enabled)
 	fmt.Printf("  Prefix server IDs: %v\n", cfg.Hub.PrefixServerIDs)
@@ -50,6 +92,67 @@ func runValidate(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// runValidateJSON validates the config at path and prints the result as JSON.
+// It returns an error, after printing, if the config is invalid.
+func runValidateJSON(path string) error {
+	result := validateResult{Path: path, Errors: []string{}, Warnings: []string{}}
+
+	cfg, err := loadConfig(path)
+	if err == nil {
+		cfg.ExpandEnvVars()
+		err = cfg.Validate()
+		result.Warnings = append(result.Warnings, cfg.Warnings()...)
+	}
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+	} else if checkCommands {
+		for _, cmdErr := range cfg.CheckCommands() {
+			result.Errors = append(result.Errors, cmdErr.Error())
+		}
+	}
+	result.Valid = len(result.Errors) == 0
+
+	data, _ := json.MarshalIndent(result, "", "  ")
+	fmt.Println(string(data))
+
+	if !result.Valid {
+		return fmt.Errorf("validation failed")
+	}
+	return nil
+}
+
+// printServers prints each server's transport details in a stable order.
+func printServers(indent string, cfg *config.RootConfig) {
+	serverIDs := make([]string, 0, len(cfg.Servers))
+	for serverID := range cfg.Servers {
+		serverIDs = append(serverIDs, serverID)
+	}
+	sort.Strings(serverIDs)
+
+	for _, serverID := range serverIDs {
+		transport := cfg.Servers[serverID].Transport
+		switch transport.Kind {
+		case "stdio":
+			fmt.Printf("%s- %s (stdio): %s\n", indent, serverID, strings.Join(append([]string{transport.Command}, transport.Args...), " "))
+			printSortedMap(indent+"    env ", transport.Env)
+		case "http":
+			fmt.Printf("%s- %s (http): %s\n", indent, serverID, transport.URL)
+			printSortedMap(indent+"    header ", transport.Headers)
+		}
+	}
+}
+
+func printSortedMap(prefix string, m map[string]string) {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("%s%s: %s\n", prefix, k, m[k])
+	}
+}
+
 // expandPath expands ~ and environment variables in paths.
 func expandPath(path string) string {
 	if len(path) > 0 && path[0] == '~' {
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ain3sh/mcp2/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var versionJSON bool
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	RunE:  runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "output build information as JSON")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := version.Get()
+
+	if versionJSON {
+		data, _ := json.MarshalIndent(info, "", "  ")
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("mcp2 %s\n", info.Version)
+	fmt.Printf("  Commit: %s\n", info.Commit)
+	fmt.Printf("  Built: %s\n", info.Date)
+	fmt.Printf("  Go: %s\n", info.GoVersion)
+	return nil
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/spf13/cobra"
+)
+
+// watchReconnectDelay is how long watch waits before reconnecting to a hub
+// that went away.
+const watchReconnectDelay = 2 * time.Second
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Live-report changes to the tools, resources, and prompts mcp2 exposes",
+	Long: `Connect to a running mcp2 proxy and print its filtered tools, resources, and
+prompts, reprinting them whenever an upstream reports a change. Added entries
+are marked with +, removed entries with -. If the proxy restarts, watch
+reconnects automatically. Press Ctrl-C to stop.`,
+	RunE: runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.Flags().IntVar(&callPort, "port", 8210, "mcp2 server port")
+	watchCmd.Flags().StringVar(&callEndpoint, "endpoint", "/mcp", "mcp2 endpoint (e.g., /mcp or /mcp/servername)")
+	addHubAuthFlags(watchCmd)
+}
+
+// watchLists is a snapshot of the names mcp2 exposes, by list.
+type watchLists map[string][]string
+
+var watchListNames = []string{"Tools", "Resources", "Prompts"}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var previous watchLists
+	for {
+		err := watchSession(ctx, &previous)
+		if ctx.Err() != nil {
+			return nil
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v; reconnecting in %v\n", colorize("disconnected", ansiYellow), err, watchReconnectDelay)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(watchReconnectDelay):
+		}
+	}
+}
+
+// watchSession connects to mcp2 and prints its lists on connect and on every
+// list_changed notification, until the connection ends.
+func watchSession(ctx context.Context, previous *watchLists) error {
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	_, session, err := connectToMCP2WithOptions(ctx, &mcp.ClientOptions{
+		ToolListChangedHandler:     func(context.Context, *mcp.ToolListChangedRequest) { notify() },
+		ResourceListChangedHandler: func(context.Context, *mcp.ResourceListChangedRequest) { notify() },
+		PromptListChangedHandler:   func(context.Context, *mcp.PromptListChangedRequest) { notify() },
+	})
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+
+	notify()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-done:
+			if err == nil {
+				err = errors.New("connection closed")
+			}
+			return err
+		case <-changed:
+			current, err := fetchWatchLists(ctx, session)
+			if err != nil {
+				return err
+			}
+			printWatchLists(*previous, current)
+			*previous = current
+		}
+	}
+}
+
+// fetchWatchLists lists everything mcp2 exposes through session.
+func fetchWatchLists(ctx context.Context, session *mcp.ClientSession) (watchLists, error) {
+	lists := make(watchLists)
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	for _, tool := range tools.Tools {
+		lists["Tools"] = append(lists["Tools"], tool.Name)
+	}
+
+	// Per-server endpoints may not support resources or prompts
+	if resources, err := session.ListResources(ctx, nil); err == nil {
+		for _, resource := range resources.Resources {
+			lists["Resources"] = append(lists["Resources"], resource.URI)
+		}
+	}
+	if prompts, err := session.ListPrompts(ctx, nil); err == nil {
+		for _, prompt := range prompts.Prompts {
+			lists["Prompts"] = append(lists["Prompts"], prompt.Name)
+		}
+	}
+
+	for _, names := range lists {
+		sort.Strings(names)
+	}
+	return lists, nil
+}
+
+// printWatchLists prints current, marking entries added or removed since
+// previous. Nothing is marked on the first print.
+func printWatchLists(previous, current watchLists) {
+	fmt.Printf("\n%s\n", colorize(time.Now().Format("15:04:05"), ansiBold))
+	for _, list := range watchListNames {
+		names := current[list]
+		fmt.Printf("%s (%d):\n", list, len(names))
+
+		before := make(map[string]bool)
+		for _, name := range previous[list] {
+			before[name] = true
+		}
+		now := make(map[string]bool)
+		for _, name := range names {
+			now[name] = true
+			if previous != nil && !before[name] {
+				fmt.Println(colorize("  + "+name, ansiGreen))
+			} else {
+				fmt.Println("    " + name)
+			}
+		}
+		for _, name := range previous[list] {
+			if !now[name] {
+				fmt.Println(colorize("  - "+name, ansiRed))
+			}
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// ClaudeDesktopConfig is the subset of Claude Desktop's config file that
+// describes MCP servers.
+type ClaudeDesktopConfig struct {
+	MCPServers map[string]ClaudeServer `json:"mcpServers"`
+}
+
+// ClaudeServer is a single entry in Claude Desktop's mcpServers map.
+// Entries either launch a command (stdio) or point at a URL (http).
+type ClaudeServer struct {
+	Type    string            `json:"type,omitempty"`
+	Command string            `json:"command,omitempty"`
+	Args    []string          `json:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty"`
+	URL     string            `json:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// ImportedProfileName is the name of the permissive profile created by
+// ImportClaudeDesktop.
+const ImportedProfileName = "default"
+
+// ImportClaudeDesktop converts a Claude Desktop config document into an mcp2
+// config with a permissive default profile that exposes every server.
+// Values are copied verbatim, so environment variable references such as
+// ${GITHUB_TOKEN} are preserved rather than expanded.
+func ImportClaudeDesktop(data []byte) (*RootConfig, error) {
+	var claude ClaudeDesktopConfig
+	if err := json.Unmarshal(data, &claude); err != nil {
+		return nil, fmt.Errorf("failed to parse Claude Desktop config: %w", err)
+	}
+	if len(claude.MCPServers) == 0 {
+		return nil, fmt.Errorf("no mcpServers found in Claude Desktop config")
+	}
+
+	cfg := &RootConfig{
+		DefaultProfile: ImportedProfileName,
+		Servers:        make(map[string]ServerConfig),
+		Profiles: map[string]ProfileConfig{
+			ImportedProfileName: {
+				Description: "Imported from Claude Desktop; allows everything",
+				Servers:     make(map[string]ServerProfileConfig),
+			},
+		},
+		Hub: HubConfig{
+			Enabled:         true,
+			PrefixServerIDs: true,
+		},
+	}
+
+	names := make([]string, 0, len(claude.MCPServers))
+	for name := range claude.MCPServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		entry := claude.MCPServers[name]
+
+		var transport ServerTransportConfig
+		switch {
+		case entry.Command != "":
+			transport = ServerTransportConfig{
+				Kind:    "stdio",
+				Command: entry.Command,
+				Args:    entry.Args,
+				Env:     entry.Env,
+			}
+		case entry.URL != "":
+			transport = ServerTransportConfig{
+				Kind:    "http",
+				URL:     entry.URL,
+				Headers: entry.Headers,
+			}
+		default:
+			return nil, fmt.Errorf("server %q: entry has neither 'command' nor 'url'", name)
+		}
+
+		cfg.Servers[name] = ServerConfig{
+			DisplayName: name,
+			Transport:   transport,
+		}
+		cfg.Profiles[ImportedProfileName].Servers[name] = ServerProfileConfig{}
+	}
+
+	return cfg, nil
+}
+
+// ExportClaudeDesktop converts the configured servers into Claude Desktop's
+// mcpServers format. If serverIDs is nil every server is exported, otherwise
+// only those listed, so an empty list exports none. Values are copied
+// verbatim, so environment variable references are preserved.
+func ExportClaudeDesktop(cfg *RootConfig, serverIDs []string) (*ClaudeDesktopConfig, error) {
+	out := &ClaudeDesktopConfig{MCPServers: make(map[string]ClaudeServer)}
+
+	if serverIDs == nil {
+		for id := range cfg.Servers {
+			serverIDs = append(serverIDs, id)
+		}
+	}
+
+	for _, id := range serverIDs {
+		server, ok := cfg.Servers[id]
+		if !ok {
+			return nil, fmt.Errorf("unknown server %q", id)
+		}
+
+		switch server.Transport.Kind {
+		case "stdio":
+			out.MCPServers[id] = ClaudeServer{
+				Command: server.Transport.Command,
+				Args:    server.Transport.Args,
+				Env:     server.Transport.Env,
+			}
+		case "http":
+			out.MCPServers[id] = ClaudeServer{
+				Type:    "http",
+				URL:     server.Transport.URL,
+				Headers: server.Transport.Headers,
+			}
+		default:
+			return nil, fmt.Errorf("server %q: unsupported transport kind %q", id, server.Transport.Kind)
+		}
+	}
+
+	return out, nil
+}
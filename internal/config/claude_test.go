@@ -0,0 +1,114 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestImportClaudeDesktop(t *testing.T) {
+	data := []byte(`{
+  "mcpServers": {
+    "filesystem": {
+      "command": "npx",
+      "args": ["-y", "@modelcontextprotocol/server-filesystem", "/home/user"],
+      "env": {"API_KEY": "${API_KEY}"}
+    },
+    "remote": {
+      "type": "http",
+      "url": "https://example.com/mcp",
+      "headers": {"Authorization": "Bearer ${TOKEN}"}
+    }
+  }
+}`)
+
+	cfg, err := ImportClaudeDesktop(data)
+	if err != nil {
+		t.Fatalf("ImportClaudeDesktop failed: %v", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Imported config should be valid: %v", err)
+	}
+
+	fs := cfg.Servers["filesystem"]
+	if fs.Transport.Kind != "stdio" || fs.Transport.Command != "npx" || len(fs.Transport.Args) != 3 {
+		t.Errorf("Unexpected stdio transport: %+v", fs.Transport)
+	}
+	if fs.Transport.Env["API_KEY"] != "${API_KEY}" {
+		t.Errorf("Env reference not preserved: got %q", fs.Transport.Env["API_KEY"])
+	}
+
+	remote := cfg.Servers["remote"]
+	if remote.Transport.Kind != "http" || remote.Transport.URL != "https://example.com/mcp" {
+		t.Errorf("Unexpected http transport: %+v", remote.Transport)
+	}
+	if remote.Transport.Headers["Authorization"] != "Bearer ${TOKEN}" {
+		t.Errorf("Header reference not preserved: got %q", remote.Transport.Headers["Authorization"])
+	}
+
+	profile := cfg.Profiles[cfg.DefaultProfile]
+	if len(profile.Servers) != 2 {
+		t.Errorf("Expected default profile to include both servers, got %d", len(profile.Servers))
+	}
+}
+
+func TestImportClaudeDesktop_InvalidEntry(t *testing.T) {
+	_, err := ImportClaudeDesktop([]byte(`{"mcpServers": {"broken": {"args": ["x"]}}}`))
+	if err == nil {
+		t.Error("Expected error for entry without command or url")
+	}
+}
+
+func TestExportClaudeDesktop_RoundTrip(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"local": {
+				Transport: ServerTransportConfig{
+					Kind:    "stdio",
+					Command: "uvx",
+					Args:    []string{"mcp-server-git"},
+					Env:     map[string]string{"TOKEN": "${TOKEN}"},
+				},
+			},
+			"remote": {
+				Transport: ServerTransportConfig{
+					Kind: "http",
+					URL:  "https://example.com/mcp",
+				},
+			},
+		},
+	}
+
+	out, err := ExportClaudeDesktop(cfg, nil)
+	if err != nil {
+		t.Fatalf("ExportClaudeDesktop failed: %v", err)
+	}
+	if len(out.MCPServers) != 2 {
+		t.Fatalf("Expected 2 servers, got %d", len(out.MCPServers))
+	}
+	if out.MCPServers["local"].Env["TOKEN"] != "${TOKEN}" {
+		t.Errorf("Env reference not preserved: got %q", out.MCPServers["local"].Env["TOKEN"])
+	}
+	if out.MCPServers["remote"].URL != "https://example.com/mcp" {
+		t.Errorf("URL = %q, want %q", out.MCPServers["remote"].URL, "https://example.com/mcp")
+	}
+
+	filtered, err := ExportClaudeDesktop(cfg, []string{"remote"})
+	if err != nil {
+		t.Fatalf("ExportClaudeDesktop failed: %v", err)
+	}
+	if _, ok := filtered.MCPServers["local"]; ok || len(filtered.MCPServers) != 1 {
+		t.Errorf("Expected only 'remote' to be exported, got %v", filtered.MCPServers)
+	}
+
+	empty, err := ExportClaudeDesktop(cfg, []string{})
+	if err != nil {
+		t.Fatalf("ExportClaudeDesktop failed: %v", err)
+	}
+	if len(empty.MCPServers) != 0 {
+		t.Errorf("Expected no servers for an empty list, got %v", empty.MCPServers)
+	}
+
+	if _, err := ExportClaudeDesktop(cfg, []string{"missing"}); err == nil {
+		t.Error("Expected error for unknown server")
+	}
+}
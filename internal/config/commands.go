@@ -0,0 +1,32 @@
+package config
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+)
+
+// CheckCommands verifies that every stdio server's command can be found,
+// either as a path or on PATH, and returns an error per missing command in
+// server ID order. Servers run through a shell are skipped since their
+// command is a shell command line. Call it after ExpandEnvVars so relative
+// commands are resolved.
+func (cfg *RootConfig) CheckCommands() []error {
+	serverIDs := make([]string, 0, len(cfg.Servers))
+	for serverID := range cfg.Servers {
+		serverIDs = append(serverIDs, serverID)
+	}
+	sort.Strings(serverIDs)
+
+	var errs []error
+	for _, serverID := range serverIDs {
+		transport := cfg.Servers[serverID].Transport
+		if transport.Kind != "stdio" || transport.Shell || transport.Command == "" {
+			continue
+		}
+		if _, err := exec.LookPath(transport.Command); err != nil {
+			errs = append(errs, fmt.Errorf("server %q: command %q not found", serverID, transport.Command))
+		}
+	}
+	return errs
+}
@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -288,6 +290,243 @@ func TestValidate_HTTPMissingURL(t *testing.T) {
 	}
 }
 
+func TestValidate_OAuthWithAuthorizationHeader(t *testing.T) {
+	for _, name := range []string{"Authorization", "authorization", "AUTHORIZATION"} {
+		cfg := &RootConfig{
+			DefaultProfile: "test",
+			Servers: map[string]ServerConfig{
+				"server1": {
+					Transport: ServerTransportConfig{
+						Kind:    "http",
+						URL:     "https://example.com/mcp",
+						Headers: map[string]string{name: "Bearer static"},
+						OAuth:   &OAuthConfig{TokenURL: "https://example.com/token", ClientID: "mcp2"},
+					},
+				},
+			},
+			Profiles: map[string]ProfileConfig{
+				"test": {},
+			},
+		}
+
+		err := cfg.Validate()
+		if err == nil || !strings.Contains(err.Error(), "oauth cannot be combined with an Authorization header") {
+			t.Errorf("Validate() with oauth and header %q = %v, want the conflict reported", name, err)
+		}
+	}
+}
+
+func TestValidate_Listeners(t *testing.T) {
+	tests := []struct {
+		name      string
+		listeners map[string]ListenerConfig
+		wantErr   string
+	}{
+		{"valid", map[string]ListenerConfig{
+			"public":   {Address: "0.0.0.0:9000", Profile: "test"},
+			"internal": {Address: "127.0.0.1:9001"},
+		}, ""},
+		{"shared address", map[string]ListenerConfig{
+			"a": {Address: "127.0.0.1:9000"},
+			"b": {Address: "127.0.0.1:9000"},
+		}, `listeners "a" and "b" share address`},
+		{"missing port", map[string]ListenerConfig{
+			"a": {Address: "127.0.0.1"},
+		}, "must be host:port"},
+		{"missing address", map[string]ListenerConfig{
+			"a": {Profile: "test"},
+		}, "'address' must be set"},
+		{"unknown profile", map[string]ListenerConfig{
+			"a": {Address: ":9000", Profile: "nope"},
+		}, `profile "nope" does not exist`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &RootConfig{
+				DefaultProfile: "test",
+				Profiles:       map[string]ProfileConfig{"test": {}},
+				Listeners:      tt.listeners,
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_DependsOn(t *testing.T) {
+	tests := []struct {
+		name      string
+		dependsOn map[string][]string
+		wantErr   string
+	}{
+		{"valid", map[string][]string{"app": {"base"}, "tool": {"app", "base"}}, ""},
+		{"self", map[string][]string{"app": {"app"}}, `server "app": dependsOn lists itself`},
+		{"unknown", map[string][]string{"app": {"nope"}}, `unknown server "nope"`},
+		{"cycle", map[string][]string{"app": {"tool"}, "base": {"app"}, "tool": {"base"}}, "cycle: app -> tool -> base -> app"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &RootConfig{
+				DefaultProfile: "test",
+				Profiles:       map[string]ProfileConfig{"test": {}},
+				Servers:        map[string]ServerConfig{},
+			}
+			for _, id := range []string{"app", "base", "tool"} {
+				cfg.Servers[id] = ServerConfig{
+					Transport: ServerTransportConfig{Kind: "stdio", Command: "true"},
+					DependsOn: tt.dependsOn[id],
+				}
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_RequestQueue(t *testing.T) {
+	tests := []struct {
+		name    string
+		server  ServerConfig
+		wantErr string
+	}{
+		{"bounded queue", ServerConfig{MaxConcurrentRequests: 4, MaxQueuedRequests: 16, MaxQueueWait: 5}, ""},
+		{"negative depth", ServerConfig{MaxConcurrentRequests: 4, MaxQueuedRequests: -1}, "maxQueuedRequests must not be negative"},
+		{"negative wait", ServerConfig{MaxConcurrentRequests: 4, MaxQueueWait: -1}, "maxQueueWait must not be negative"},
+		{"no concurrency limit", ServerConfig{MaxQueuedRequests: 16}, "need maxConcurrentRequests"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.server.Transport = ServerTransportConfig{Kind: "stdio", Command: "true"}
+			cfg := &RootConfig{
+				DefaultProfile: "test",
+				Profiles:       map[string]ProfileConfig{"test": {}},
+				Servers:        map[string]ServerConfig{"app": tt.server},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_ResourceMetadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  ResourceMetadataFilter
+		wantErr string
+	}{
+		{"valid", ResourceMetadataFilter{MaxSize: 1 << 20, Audience: []string{"assistant"}, MinPriority: 0.5}, ""},
+		{"negative size", ResourceMetadataFilter{MaxSize: -1}, "maxSize must not be negative"},
+		{"priority above 1", ResourceMetadataFilter{MinPriority: 2}, "minPriority must be between 0 and 1"},
+		{"unknown role", ResourceMetadataFilter{Audience: []string{"robot"}}, `audience "robot"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &RootConfig{
+				DefaultProfile: "test",
+				Servers: map[string]ServerConfig{
+					"docs": {Transport: ServerTransportConfig{Kind: "stdio", Command: "true"}},
+				},
+				Profiles: map[string]ProfileConfig{"test": {
+					Servers: map[string]ServerProfileConfig{"docs": {ResourceMetadata: tt.filter}},
+				}},
+			}
+			err := cfg.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidate_UnknownMethod(t *testing.T) {
+	tests := []struct {
+		setting string
+		wantErr bool
+	}{
+		{"", false},
+		{"passthrough", false},
+		{"reject", false},
+		{"forward:server1", false},
+		{"forward:missing", true},
+		{"forward", true},
+		{"reject:server1", true},
+		{"drop", true},
+	}
+	for _, tt := range tests {
+		cfg := &RootConfig{
+			DefaultProfile: "test",
+			Hub:            HubConfig{UnknownMethod: tt.setting},
+			Servers: map[string]ServerConfig{
+				"server1": {Transport: ServerTransportConfig{Kind: "stdio", Command: "server"}},
+			},
+			Profiles: map[string]ProfileConfig{
+				"test": {},
+			},
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("unknownMethod %q: Validate() error = %v, wantErr %v", tt.setting, err, tt.wantErr)
+		}
+	}
+}
+
+func TestValidate_ProtocolVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		wantErr bool
+	}{
+		{"", false},
+		{"2025-03-26", false},
+		{"2024-11-05", false},
+		{"2023-01-01", true},
+	}
+	for _, tt := range tests {
+		cfg := &RootConfig{
+			DefaultProfile: "test",
+			Servers: map[string]ServerConfig{
+				"server1": {
+					Transport: ServerTransportConfig{Kind: "http", URL: "http://localhost/mcp", ProtocolVersion: tt.version},
+				},
+			},
+			Profiles: map[string]ProfileConfig{
+				"test": {},
+			},
+		}
+		if err := cfg.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("protocolVersion %q: Validate() error = %v, wantErr %v", tt.version, err, tt.wantErr)
+		}
+	}
+}
+
 func TestExpandEnvVars(t *testing.T) {
 	// Set test environment variable
 	os.Setenv("TEST_TOKEN", "secret123")
@@ -349,3 +588,541 @@ servers:
 		t.Error("Expected error for invalid YAML, got nil")
 	}
 }
+
+func TestLoad_Stdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	go func() {
+		w.WriteString(`{"defaultProfile": "piped", "profiles": {"piped": {}}}`)
+		w.Close()
+	}()
+
+	cfg, err := Load(StdinPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if cfg.DefaultProfile != "piped" {
+		t.Errorf("DefaultProfile = %q, want %q", cfg.DefaultProfile, "piped")
+	}
+}
+
+func TestValidate_StdioCwd(t *testing.T) {
+	newConfig := func(cwd string) *RootConfig {
+		return &RootConfig{
+			DefaultProfile: "test",
+			Servers: map[string]ServerConfig{
+				"server1": {
+					Transport: ServerTransportConfig{
+						Kind:    "stdio",
+						Command: "test",
+						Cwd:     cwd,
+					},
+				},
+			},
+			Profiles: map[string]ProfileConfig{
+				"test": {},
+			},
+		}
+	}
+
+	if err := newConfig(t.TempDir()).Validate(); err != nil {
+		t.Errorf("Validate() failed for existing cwd: %v", err)
+	}
+
+	if err := newConfig("/nonexistent/dir").Validate(); err == nil {
+		t.Error("Expected validation error for nonexistent cwd, got nil")
+	}
+}
+
+func TestExpandEnvVars_Cwd(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+	t.Setenv("PROJECT", "myproject")
+
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"server1": {
+				Transport: ServerTransportConfig{
+					Kind:    "stdio",
+					Command: "test",
+					Cwd:     "~/src/${PROJECT}",
+				},
+			},
+		},
+	}
+
+	cfg.ExpandEnvVars()
+
+	want := filepath.Join(home, "src", "myproject")
+	if got := cfg.Servers["server1"].Transport.Cwd; got != want {
+		t.Errorf("Cwd = %q, want %q", got, want)
+	}
+}
+
+func TestExpandEnvVars_ResolvesRelativePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+
+	configContent := `
+defaultProfile: test
+servers:
+  relative:
+    transport:
+      kind: stdio
+      command: ./servers/myserver
+      cwd: data
+  bare:
+    transport:
+      kind: stdio
+      command: npx
+  absolute:
+    transport:
+      kind: stdio
+      command: /usr/bin/env
+profiles:
+  test: {}
+`
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := Load(configPath)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	cfg.ExpandEnvVars()
+
+	relative := cfg.Servers["relative"].Transport
+	if want := filepath.Join(tmpDir, "servers", "myserver"); relative.Command != want {
+		t.Errorf("Command = %q, want %q", relative.Command, want)
+	}
+	if want := filepath.Join(tmpDir, "data"); relative.Cwd != want {
+		t.Errorf("Cwd = %q, want %q", relative.Cwd, want)
+	}
+
+	if got := cfg.Servers["bare"].Transport.Command; got != "npx" {
+		t.Errorf("Bare command should be unchanged, got %q", got)
+	}
+	if got := cfg.Servers["absolute"].Transport.Command; got != "/usr/bin/env" {
+		t.Errorf("Absolute command should be unchanged, got %q", got)
+	}
+}
+
+func TestValidate_ServerIDWithSeparator(t *testing.T) {
+	cfg := &RootConfig{
+		DefaultProfile: "default",
+		Profiles: map[string]ProfileConfig{
+			"default": {Servers: map[string]ServerProfileConfig{"a:b": {}}},
+		},
+		Servers: map[string]ServerConfig{
+			"a:b": {Transport: ServerTransportConfig{Kind: "stdio", Command: "echo"}},
+		},
+		Hub: HubConfig{PrefixServerIDs: true},
+	}
+
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for server ID containing ':'")
+	}
+
+	cfg.Hub.PrefixSeparator = "__"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected ':' to be allowed with a different separator, got %v", err)
+	}
+
+	// A passthrough profile exposes servers it doesn't list
+	cfg.Hub.PrefixSeparator = ""
+	cfg.Profiles["default"] = ProfileConfig{Passthrough: true}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for server ID containing ':' in a passthrough profile")
+	}
+
+	// Replica group IDs are exposed in place of their members'
+	cfg.Servers = map[string]ServerConfig{
+		"a:b": {Transport: ServerTransportConfig{Kind: "stdio", Command: "echo"}},
+	}
+	cfg.Hub.ReplicaGroups = map[string]ReplicaGroupConfig{"ab": {Servers: []ReplicaConfig{{Server: "a:b"}}}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected a grouped server's ID to be allowed, got %v", err)
+	}
+	cfg.Hub.ReplicaGroups = map[string]ReplicaGroupConfig{"g:1": {Servers: []ReplicaConfig{{Server: "a:b"}}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected error for replica group ID containing ':'")
+	}
+}
+
+func TestPrefixSettings(t *testing.T) {
+	bare := false
+	cfg := &RootConfig{
+		Hub: HubConfig{PrefixServerIDs: true},
+		Profiles: map[string]ProfileConfig{
+			"dev":    {PrefixSeparator: "__"},
+			"single": {PrefixServerIDs: &bare},
+		},
+	}
+
+	tests := []struct {
+		profile   string
+		enabled   bool
+		separator string
+	}{
+		{"dev", true, "__"},
+		{"single", false, ":"},
+		{"missing", true, ":"},
+	}
+
+	for _, tt := range tests {
+		enabled, separator := cfg.PrefixSettings(tt.profile)
+		if enabled != tt.enabled || separator != tt.separator {
+			t.Errorf("PrefixSettings(%q) = %v, %q; want %v, %q", tt.profile, enabled, separator, tt.enabled, tt.separator)
+		}
+	}
+}
+
+func TestComponentPrefixes(t *testing.T) {
+	yes, no := true, false
+	cfg := &RootConfig{
+		Hub: HubConfig{PrefixServerIDs: true, Prefix: PrefixComponentsConfig{Resources: &no}},
+		Profiles: map[string]ProfileConfig{
+			"dev":    {},
+			"single": {PrefixServerIDs: &no},
+		},
+	}
+
+	if got, want := cfg.ComponentPrefixes("dev"), (PrefixedComponents{Tools: true, Prompts: true}); got != want {
+		t.Errorf("ComponentPrefixes(dev) = %+v, want %+v", got, want)
+	}
+	if got := cfg.ComponentPrefixes("single"); got.Any() {
+		t.Errorf("ComponentPrefixes(single) = %+v, want none prefixed", got)
+	}
+
+	cfg.Hub.Prefix.Tools = &yes
+	if got, want := cfg.ComponentPrefixes("single"), (PrefixedComponents{Tools: true}); got != want {
+		t.Errorf("ComponentPrefixes(single) with tools set = %+v, want %+v", got, want)
+	}
+}
+
+func TestServerConfig_IsRequired(t *testing.T) {
+	optional, required := false, true
+	for _, tt := range []struct {
+		setting *bool
+		want    bool
+	}{
+		{nil, true},
+		{&required, true},
+		{&optional, false},
+	} {
+		s := ServerConfig{Required: tt.setting}
+		if got := s.IsRequired(); got != tt.want {
+			t.Errorf("IsRequired() with required=%v = %v, want %v", tt.setting, got, tt.want)
+		}
+	}
+}
+
+func TestExposePerServerFor(t *testing.T) {
+	on, off := true, false
+	cfg := &RootConfig{
+		Profiles: map[string]ProfileConfig{
+			"tenant":  {ExposePerServer: &on},
+			"private": {ExposePerServer: &off},
+			"default": {},
+		},
+	}
+
+	if !cfg.ExposePerServerFor("tenant") {
+		t.Error("ExposePerServerFor(tenant) = false, want the profile's true")
+	}
+	if cfg.ExposePerServerFor("default") {
+		t.Error("ExposePerServerFor(default) = true, want the global false")
+	}
+
+	cfg.ExposePerServer = true
+	if cfg.ExposePerServerFor("private") {
+		t.Error("ExposePerServerFor(private) = true, want the profile's false")
+	}
+	if !cfg.ExposePerServerFor("default") {
+		t.Error("ExposePerServerFor(default) = false, want the global true")
+	}
+}
+
+func TestProfileFor(t *testing.T) {
+	cfg := &RootConfig{
+		DefaultProfile:    "safe",
+		TransportProfiles: TransportProfiles{Stdio: "dev"},
+		Profiles: map[string]ProfileConfig{
+			"safe": {},
+			"dev":  {},
+		},
+	}
+
+	if got := cfg.ProfileFor(TransportStdio); got != "dev" {
+		t.Errorf("ProfileFor(stdio) = %q, want dev", got)
+	}
+	if got := cfg.ProfileFor(TransportHTTP); got != "safe" {
+		t.Errorf("ProfileFor(http) = %q, want the default profile", got)
+	}
+
+	cfg.TransportProfiles.HTTP = "missing"
+	if err := cfg.Validate(); err == nil {
+		t.Error("Expected an error for a transport profile that does not exist")
+	}
+}
+
+func TestWarnings_DuplicateDisplayNames(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"fs1":    {DisplayName: "Files"},
+			"fs2":    {DisplayName: "Files"},
+			"github": {DisplayName: "GitHub"},
+		},
+		Profiles: map[string]ProfileConfig{
+			"all": {Servers: map[string]ServerProfileConfig{"fs1": {}, "fs2": {}, "github": {}}},
+		},
+	}
+
+	warnings := cfg.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "fs1, fs2") {
+		t.Errorf("Expected one duplicate displayName warning for fs1, fs2, got %v", warnings)
+	}
+}
+
+func TestWarnings_LongToolNames(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"github":                     {},
+			"a-very-long-server-name-id": {},
+		},
+		Profiles: map[string]ProfileConfig{
+			"main": {Servers: map[string]ServerProfileConfig{
+				"github":                     {Tools: ComponentFilter{Allow: []string{"read_*", "create_or_update_file", "list"}}},
+				"a-very-long-server-name-id": {},
+			}},
+		},
+		Hub: HubConfig{PrefixServerIDs: true, MaxNameLength: 24},
+	}
+
+	warnings := cfg.Warnings()
+	want := []string{
+		`profile "main": prefix "a-very-long-server-name-id:" leaves no room for tool names within hub.maxNameLength (24); use a shorter server ID`,
+		`profile "main": tool name "github:create_or_update_file" is 28 characters, over hub.maxNameLength (24); use a shorter server ID`,
+	}
+	if strings.Join(warnings, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Warnings() = %q, want %q", warnings, want)
+	}
+
+	cfg.Hub.PrefixServerIDs = false
+	if warnings := cfg.Warnings(); len(warnings) != 0 {
+		t.Errorf("Warnings() without prefixing = %q, want none", warnings)
+	}
+}
+
+func TestWarnings_UnreferencedServersAndEmptyProfiles(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"used":   {},
+			"unused": {},
+		},
+		Profiles: map[string]ProfileConfig{
+			"main":  {Servers: map[string]ServerProfileConfig{"used": {}}},
+			"empty": {},
+		},
+	}
+
+	warnings := cfg.Warnings()
+	want := []string{
+		`profile "empty" has no servers and exposes nothing`,
+		`server "unused" is not referenced by any profile`,
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("Warnings() = %v, want %v", warnings, want)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("Warnings()[%d] = %q, want %q", i, warnings[i], want[i])
+		}
+	}
+}
+
+func TestWarnings_PassthroughReferencesEveryServer(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"used":   {},
+			"unused": {},
+		},
+		Profiles: map[string]ProfileConfig{
+			"main": {Servers: map[string]ServerProfileConfig{"used": {}}},
+			"dev":  {Passthrough: true},
+		},
+	}
+
+	if warnings := cfg.Warnings(); len(warnings) != 0 {
+		t.Errorf("Warnings() = %v, want none", warnings)
+	}
+}
+
+func TestWarnings_EmptyEnvAndHeaderValues(t *testing.T) {
+	t.Setenv("MCP2_TEST_UNSET_TOKEN", "")
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"github": {Transport: ServerTransportConfig{
+				Kind: "http",
+				URL:  "https://api.example.com/mcp",
+				Headers: map[string]string{
+					"Authorization": "Bearer ${MCP2_TEST_UNSET_TOKEN}",
+					"X-Team":        "core",
+				},
+			}},
+			"fs": {Transport: ServerTransportConfig{
+				Kind:    "stdio",
+				Command: "fs-server",
+				Env:     map[string]string{"API_KEY": "${MCP2_TEST_UNSET_TOKEN}"},
+			}},
+		},
+		Profiles: map[string]ProfileConfig{
+			"main": {Servers: map[string]ServerProfileConfig{"github": {}, "fs": {}}},
+		},
+	}
+	cfg.ExpandEnvVars()
+
+	warnings := cfg.Warnings()
+	want := []string{
+		`server "fs": env API_KEY is empty (is a referenced variable unset?)`,
+		`server "github": header Authorization is empty (is a referenced variable unset?)`,
+	}
+	if len(warnings) != len(want) {
+		t.Fatalf("Warnings() = %v, want %v", warnings, want)
+	}
+	for i := range want {
+		if warnings[i] != want[i] {
+			t.Errorf("Warnings()[%d] = %q, want %q", i, warnings[i], want[i])
+		}
+	}
+}
+
+func TestCheckCommands(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"ok":      {Transport: ServerTransportConfig{Kind: "stdio", Command: "sh"}},
+			"missing": {Transport: ServerTransportConfig{Kind: "stdio", Command: "mcp2-no-such-command"}},
+			"shell":   {Transport: ServerTransportConfig{Kind: "stdio", Command: "mcp2-no-such-command | cat", Shell: true}},
+			"remote":  {Transport: ServerTransportConfig{Kind: "http", URL: "http://localhost"}},
+		},
+	}
+
+	errs := cfg.CheckCommands()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `server "missing"`) {
+		t.Errorf("Expected only the missing command to be reported, got %v", errs)
+	}
+}
+
+func TestValidate_AuthTokens(t *testing.T) {
+	newConfig := func(token AuthToken) *RootConfig {
+		return &RootConfig{
+			DefaultProfile: "default",
+			Profiles:       map[string]ProfileConfig{"default": {}},
+			Servers: map[string]ServerConfig{
+				"docs": {Transport: ServerTransportConfig{Kind: "http", URL: "http://localhost"}},
+			},
+			Auth: AuthConfig{Tokens: []AuthToken{token}},
+		}
+	}
+
+	if err := newConfig(AuthToken{Token: "t", Scopes: []string{"docs", "hub"}}).Validate(); err != nil {
+		t.Errorf("Expected valid auth config, got %v", err)
+	}
+	if err := newConfig(AuthToken{Token: "t", Scopes: []string{"unknown"}}).Validate(); err == nil {
+		t.Error("Expected error for scope naming an unknown server")
+	}
+	if err := newConfig(AuthToken{Token: "t"}).Validate(); err == nil {
+		t.Error("Expected error for token without scopes")
+	}
+	if err := newConfig(AuthToken{Scopes: []string{"*"}}).Validate(); err == nil {
+		t.Error("Expected error for empty token")
+	}
+}
+
+func TestValidate_ReplicaGroups(t *testing.T) {
+	newConfig := func(groups map[string]ReplicaGroupConfig) *RootConfig {
+		http := ServerTransportConfig{Kind: "http", URL: "http://localhost"}
+		return &RootConfig{
+			DefaultProfile: "default",
+			Profiles: map[string]ProfileConfig{
+				"default": {Servers: map[string]ServerProfileConfig{"search": {}}},
+			},
+			Servers: map[string]ServerConfig{
+				"search-1": {Transport: http},
+				"search-2": {Transport: http},
+			},
+			Hub: HubConfig{ReplicaGroups: groups},
+		}
+	}
+
+	valid := map[string]ReplicaGroupConfig{
+		"search": {Servers: []ReplicaConfig{{Server: "search-1", Weight: 2}, {Server: "search-2"}}},
+	}
+	cfg := newConfig(valid)
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid replica groups, got %v", err)
+	}
+	if warnings := cfg.Warnings(); len(warnings) != 0 {
+		t.Errorf("Expected members of a referenced group to count as referenced, got %v", warnings)
+	}
+
+	invalid := map[string]map[string]ReplicaGroupConfig{
+		"unknown member":  {"search": {Servers: []ReplicaConfig{{Server: "search-3"}}}},
+		"no members":      {"search": {}},
+		"negative weight": {"search": {Servers: []ReplicaConfig{{Server: "search-1", Weight: -1}}}},
+		"shared member": {
+			"search": {Servers: []ReplicaConfig{{Server: "search-1"}}},
+			"other":  {Servers: []ReplicaConfig{{Server: "search-1"}}},
+		},
+		"shadows server": {
+			"search":   {Servers: []ReplicaConfig{{Server: "search-1"}}},
+			"search-2": {Servers: []ReplicaConfig{{Server: "search-2"}}},
+		},
+	}
+	for name, groups := range invalid {
+		if err := newConfig(groups).Validate(); err == nil {
+			t.Errorf("%s: expected validation error", name)
+		}
+	}
+}
+
+func TestProfileServerIDs(t *testing.T) {
+	http := ServerTransportConfig{Kind: "http", URL: "http://localhost"}
+	cfg := &RootConfig{
+		Profiles: map[string]ProfileConfig{
+			"search": {Servers: map[string]ServerProfileConfig{"search": {}, "files": {}}},
+			"all":    {Passthrough: true},
+		},
+		Servers: map[string]ServerConfig{
+			"files":    {Transport: http},
+			"search-1": {Transport: http},
+			"search-2": {Transport: http},
+			"other":    {Transport: http},
+		},
+		Hub: HubConfig{ReplicaGroups: map[string]ReplicaGroupConfig{
+			"search": {Servers: []ReplicaConfig{{Server: "search-1"}, {Server: "search-2"}}},
+		}},
+	}
+
+	tests := map[string][]string{
+		"search":  {"files", "search-1", "search-2"},
+		"all":     {"files", "other", "search-1", "search-2"},
+		"missing": nil,
+	}
+	for profile, want := range tests {
+		if got := cfg.ProfileServerIDs(profile); !slices.Equal(got, want) {
+			t.Errorf("ProfileServerIDs(%q) = %v, want %v", profile, got, want)
+		}
+	}
+}
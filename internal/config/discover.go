@@ -0,0 +1,65 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// EnvConfigPath is the environment variable that points at a config file.
+const EnvConfigPath = "MCP2_CONFIG"
+
+// Candidate is a possible config file location.
+type Candidate struct {
+	Path   string
+	Source string // human-readable description of where the path came from
+}
+
+// SearchPaths returns the ordered list of locations searched for a config
+// file when none is given explicitly:
+//   - $MCP2_CONFIG
+//   - ./mcp2.yaml, ./mcp2.yml, ./mcp2.json
+//   - $XDG_CONFIG_HOME/mcp2/config.yaml
+//   - ~/.config/mcp2/config.yaml
+func SearchPaths() []Candidate {
+	var candidates []Candidate
+
+	if env := os.Getenv(EnvConfigPath); env != "" {
+		candidates = append(candidates, Candidate{Path: env, Source: "$" + EnvConfigPath})
+	}
+
+	for _, name := range []string{"mcp2.yaml", "mcp2.yml", "mcp2.json"} {
+		candidates = append(candidates, Candidate{Path: name, Source: "current directory"})
+	}
+
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		candidates = append(candidates, Candidate{
+			Path:   filepath.Join(xdg, "mcp2", "config.yaml"),
+			Source: "$XDG_CONFIG_HOME",
+		})
+	}
+
+	if home, err := os.UserHomeDir(); err == nil {
+		candidates = append(candidates, Candidate{
+			Path:   filepath.Join(home, ".config", "mcp2", "config.yaml"),
+			Source: "default location",
+		})
+	}
+
+	return candidates
+}
+
+// Discover returns the first search path that exists. A path given via
+// $MCP2_CONFIG is returned even if it does not exist, so that a typo surfaces
+// as a load error instead of silently falling through to another file.
+// The boolean result is false if no candidate exists.
+func Discover() (Candidate, bool) {
+	for _, c := range SearchPaths() {
+		if c.Source == "$"+EnvConfigPath {
+			return c, true
+		}
+		if info, err := os.Stat(c.Path); err == nil && !info.IsDir() {
+			return c, true
+		}
+	}
+	return Candidate{}, false
+}
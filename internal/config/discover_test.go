@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDiscover_Order(t *testing.T) {
+	home := t.TempDir()
+	xdg := t.TempDir()
+	work := t.TempDir()
+
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	t.Setenv(EnvConfigPath, "")
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(work); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(origDir)
+
+	writeFile := func(path string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("defaultProfile: x\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, ok := Discover(); ok {
+		t.Fatal("Expected no config to be discovered")
+	}
+
+	homePath := filepath.Join(home, ".config", "mcp2", "config.yaml")
+	writeFile(homePath)
+	if c, ok := Discover(); !ok || c.Path != homePath {
+		t.Errorf("Discover() = %q, want %q", c.Path, homePath)
+	}
+
+	xdgPath := filepath.Join(xdg, "mcp2", "config.yaml")
+	writeFile(xdgPath)
+	if c, ok := Discover(); !ok || c.Path != xdgPath {
+		t.Errorf("Discover() = %q, want %q", c.Path, xdgPath)
+	}
+
+	writeFile(filepath.Join(work, "mcp2.json"))
+	if c, ok := Discover(); !ok || c.Path != "mcp2.json" {
+		t.Errorf("Discover() = %q, want %q", c.Path, "mcp2.json")
+	}
+
+	writeFile(filepath.Join(work, "mcp2.yaml"))
+	if c, ok := Discover(); !ok || c.Path != "mcp2.yaml" {
+		t.Errorf("Discover() = %q, want %q", c.Path, "mcp2.yaml")
+	}
+
+	// The environment variable wins, even if the file does not exist
+	t.Setenv(EnvConfigPath, "/nonexistent/mcp2.yaml")
+	if c, ok := Discover(); !ok || c.Path != "/nonexistent/mcp2.yaml" {
+		t.Errorf("Discover() = %q, want %q", c.Path, "/nonexistent/mcp2.yaml")
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
@@ -10,9 +11,20 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// StdinPath is the config path that means "read the config from stdin".
+const StdinPath = "-"
+
 // Load reads and parses a configuration file (YAML or JSON).
+// If path is "-", the configuration is read from stdin; since there is no
+// extension to go by, it is parsed as YAML first and then as JSON.
 func Load(path string) (*RootConfig, error) {
-	data, err := os.ReadFile(path)
+	var data []byte
+	var err error
+	if path == StdinPath {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
@@ -39,11 +51,25 @@ func Load(path string) (*RootConfig, error) {
 		}
 	}
 
+	if path != StdinPath {
+		if abs, err := filepath.Abs(path); err == nil {
+			cfg.baseDir = filepath.Dir(abs)
+		}
+	}
+
 	return &cfg, nil
 }
 
+// BaseDir returns the directory relative paths in the config are resolved
+// against. It is empty for configs not loaded from a file.
+func (cfg *RootConfig) BaseDir() string {
+	return cfg.baseDir
+}
+
 // ExpandEnvVars expands environment variables in the configuration.
 // This is useful for things like ${GITHUB_TOKEN} in headers.
+// It also resolves relative stdio command and cwd paths against the config
+// file's directory, so configs work regardless of the launch directory.
 func (cfg *RootConfig) ExpandEnvVars() {
 	for serverID, server := range cfg.Servers {
 		// Expand environment variables in command
@@ -54,6 +80,18 @@ func (cfg *RootConfig) ExpandEnvVars() {
 			server.Transport.Args[i] = os.ExpandEnv(arg)
 		}
 
+		// Expand in working directory
+		server.Transport.Cwd = expandHome(os.ExpandEnv(server.Transport.Cwd))
+
+		// Resolve relative paths against the config directory. Bare command
+		// names are looked up on PATH and shell command lines are left as-is.
+		if !server.Transport.Shell && strings.ContainsRune(server.Transport.Command, filepath.Separator) {
+			server.Transport.Command = cfg.resolvePath(server.Transport.Command)
+		}
+		if server.Transport.Cwd != "" {
+			server.Transport.Cwd = cfg.resolvePath(server.Transport.Cwd)
+		}
+
 		// Expand in env values
 		for k, v := range server.Transport.Env {
 			server.Transport.Env[k] = os.ExpandEnv(v)
@@ -67,7 +105,37 @@ func (cfg *RootConfig) ExpandEnvVars() {
 			server.Transport.Headers[k] = os.ExpandEnv(v)
 		}
 
+		// Expand in OAuth settings
+		if oauth := server.Transport.OAuth; oauth != nil {
+			oauth.TokenURL = os.ExpandEnv(oauth.TokenURL)
+			oauth.ClientID = os.ExpandEnv(oauth.ClientID)
+			oauth.ClientSecret = os.ExpandEnv(oauth.ClientSecret)
+		}
+
 		// Write the modified server back to the map
 		cfg.Servers[serverID] = server
 	}
+
+	// Expand in auth tokens
+	for i := range cfg.Auth.Tokens {
+		cfg.Auth.Tokens[i].Token = os.ExpandEnv(cfg.Auth.Tokens[i].Token)
+	}
+}
+
+// resolvePath makes a relative path relative to the config directory.
+func (cfg *RootConfig) resolvePath(path string) string {
+	if cfg.baseDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cfg.baseDir, path)
+}
+
+// expandHome replaces a leading ~ with the user's home directory.
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
 }
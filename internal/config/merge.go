@@ -0,0 +1,286 @@
+package config
+
+// Merge applies other on top of cfg, as when layering a per-developer
+// override config over a shared base:
+//
+//   - Maps (servers, profiles, listeners, per-server profile filters, deny reasons,
+//     prompt argument constraints, env, headers) are merged key by key,
+//     recursively, with other's entries winning.
+//   - Strings and numbers in other replace cfg's when set (non-zero).
+//   - Bools in other can only turn a setting on, since false is
+//     indistinguishable from unset; profile prefixServerIDs, a pointer, can
+//     also turn prefixing off.
+//   - Lists (args, filter allow/deny, allowed prompt argument values, OAuth
+//     scopes, auth tokens, serverOrder, toolPriority, dependsOn) in other replace cfg's
+//     entirely when non-empty; they are never concatenated.
+//
+// Relative paths in other are resolved against cfg's directory.
+func (cfg *RootConfig) Merge(other *RootConfig) {
+	if other == nil {
+		return
+	}
+
+	mergeString(&cfg.DefaultProfile, other.DefaultProfile)
+	mergeString(&cfg.TransportProfiles.Stdio, other.TransportProfiles.Stdio)
+	mergeString(&cfg.TransportProfiles.HTTP, other.TransportProfiles.HTTP)
+	cfg.ExposePerServer = cfg.ExposePerServer || other.ExposePerServer
+
+	if len(other.Listeners) > 0 && cfg.Listeners == nil {
+		cfg.Listeners = make(map[string]ListenerConfig)
+	}
+	for name, l := range other.Listeners {
+		base := cfg.Listeners[name]
+		mergeString(&base.Address, l.Address)
+		mergeString(&base.Profile, l.Profile)
+		cfg.Listeners[name] = base
+	}
+
+	if len(other.Servers) > 0 && cfg.Servers == nil {
+		cfg.Servers = make(map[string]ServerConfig)
+	}
+	for id, server := range other.Servers {
+		base := cfg.Servers[id]
+		base.merge(&server)
+		cfg.Servers[id] = base
+	}
+
+	if len(other.Profiles) > 0 && cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]ProfileConfig)
+	}
+	for name, profile := range other.Profiles {
+		base := cfg.Profiles[name]
+		base.merge(&profile)
+		cfg.Profiles[name] = base
+	}
+
+	cfg.Hub.Enabled = cfg.Hub.Enabled || other.Hub.Enabled
+	cfg.Hub.PrefixServerIDs = cfg.Hub.PrefixServerIDs || other.Hub.PrefixServerIDs
+	cfg.Hub.ForwardClientInfo = cfg.Hub.ForwardClientInfo || other.Hub.ForwardClientInfo
+	cfg.Hub.RelayLogs = cfg.Hub.RelayLogs || other.Hub.RelayLogs
+	cfg.Hub.DisplayNameInTitles = cfg.Hub.DisplayNameInTitles || other.Hub.DisplayNameInTitles
+	cfg.Hub.AllowElicitation = cfg.Hub.AllowElicitation || other.Hub.AllowElicitation
+	cfg.Hub.AllowSampling = cfg.Hub.AllowSampling || other.Hub.AllowSampling
+	cfg.Hub.SafeMode = cfg.Hub.SafeMode || other.Hub.SafeMode
+	cfg.Hub.StatusTool = cfg.Hub.StatusTool || other.Hub.StatusTool
+	cfg.Hub.DiagnosticsTool = cfg.Hub.DiagnosticsTool || other.Hub.DiagnosticsTool
+	cfg.Hub.Compress = cfg.Hub.Compress || other.Hub.Compress
+	mergeString(&cfg.Hub.PrefixSeparator, other.Hub.PrefixSeparator)
+	cfg.Hub.Prefix.merge(&other.Hub.Prefix)
+	mergeInt(&cfg.Hub.MaxTools, other.Hub.MaxTools)
+	mergeInt(&cfg.Hub.MaxNameLength, other.Hub.MaxNameLength)
+	mergeInt(&cfg.Hub.MaxResourceBytes, other.Hub.MaxResourceBytes)
+	mergeInt(&cfg.Hub.MaxListBytes, other.Hub.MaxListBytes)
+	mergeInt(&cfg.Hub.CompressMinBytes, other.Hub.CompressMinBytes)
+	mergeInt(&cfg.Hub.ConnectConcurrency, other.Hub.ConnectConcurrency)
+	mergeString(&cfg.Hub.UnknownMethod, other.Hub.UnknownMethod)
+	cfg.Hub.HTTPClient.merge(&other.Hub.HTTPClient)
+	if other.Hub.InheritEnv != nil {
+		inherit := *other.Hub.InheritEnv
+		cfg.Hub.InheritEnv = &inherit
+	}
+	if len(other.Hub.ServerOrder) > 0 {
+		cfg.Hub.ServerOrder = append([]string(nil), other.Hub.ServerOrder...)
+	}
+	if len(other.Hub.ReplicaGroups) > 0 {
+		groups := make(map[string]ReplicaGroupConfig, len(cfg.Hub.ReplicaGroups)+len(other.Hub.ReplicaGroups))
+		for id, group := range cfg.Hub.ReplicaGroups {
+			groups[id] = group
+		}
+		for id, group := range other.Hub.ReplicaGroups {
+			groups[id] = ReplicaGroupConfig{Servers: append([]ReplicaConfig(nil), group.Servers...), Stateless: group.Stateless}
+		}
+		cfg.Hub.ReplicaGroups = groups
+	}
+
+	mergeString(&cfg.Logging.Format, other.Logging.Format)
+	mergeString(&cfg.Logging.Level, other.Logging.Level)
+	mergeString(&cfg.Logging.File, other.Logging.File)
+	mergeInt(&cfg.Logging.MaxSizeMB, other.Logging.MaxSizeMB)
+	mergeInt(&cfg.Logging.MaxBackups, other.Logging.MaxBackups)
+
+	if len(other.Auth.Tokens) > 0 {
+		cfg.Auth.Tokens = append([]AuthToken(nil), other.Auth.Tokens...)
+	}
+}
+
+func (s *ServerConfig) merge(other *ServerConfig) {
+	mergeString(&s.DisplayName, other.DisplayName)
+	mergeInt(&s.InitializationTimeout, other.InitializationTimeout)
+	if other.Required != nil {
+		required := *other.Required
+		s.Required = &required
+	}
+	mergeInt(&s.MaxConcurrentRequests, other.MaxConcurrentRequests)
+	mergeInt(&s.MaxQueuedRequests, other.MaxQueuedRequests)
+	mergeInt(&s.MaxQueueWait, other.MaxQueueWait)
+	mergeInt(&s.MaxTools, other.MaxTools)
+	mergeInt(&s.KeepaliveInterval, other.KeepaliveInterval)
+	if len(other.DependsOn) > 0 {
+		s.DependsOn = append([]string(nil), other.DependsOn...)
+	}
+	if len(other.ToolPriority) > 0 {
+		s.ToolPriority = append([]string(nil), other.ToolPriority...)
+	}
+
+	t, o := &s.Transport, &other.Transport
+	mergeString(&t.Kind, o.Kind)
+	mergeString(&t.Command, o.Command)
+	if len(o.Args) > 0 {
+		t.Args = append([]string(nil), o.Args...)
+	}
+	t.Env = mergeMap(t.Env, o.Env)
+	mergeString(&t.Cwd, o.Cwd)
+	t.Shell = t.Shell || o.Shell
+	if o.InheritEnv != nil {
+		inherit := *o.InheritEnv
+		t.InheritEnv = &inherit
+	}
+	mergeString(&t.ProtocolVersion, o.ProtocolVersion)
+	mergeString(&t.URL, o.URL)
+	t.Headers = mergeMap(t.Headers, o.Headers)
+	t.HTTPClient.merge(&o.HTTPClient)
+
+	if o.OAuth != nil {
+		if t.OAuth == nil {
+			t.OAuth = &OAuthConfig{}
+		} else {
+			oauth := *t.OAuth
+			t.OAuth = &oauth
+		}
+		mergeString(&t.OAuth.TokenURL, o.OAuth.TokenURL)
+		mergeString(&t.OAuth.ClientID, o.OAuth.ClientID)
+		mergeString(&t.OAuth.ClientSecret, o.OAuth.ClientSecret)
+		if len(o.OAuth.Scopes) > 0 {
+			t.OAuth.Scopes = append([]string(nil), o.OAuth.Scopes...)
+		}
+	}
+}
+
+func (p *ProfileConfig) merge(other *ProfileConfig) {
+	mergeString(&p.Description, other.Description)
+	mergeString(&p.PrefixSeparator, other.PrefixSeparator)
+	p.ListEnforcedOnly = p.ListEnforcedOnly || other.ListEnforcedOnly
+	p.Passthrough = p.Passthrough || other.Passthrough
+	if other.PrefixServerIDs != nil {
+		enabled := *other.PrefixServerIDs
+		p.PrefixServerIDs = &enabled
+	}
+	if other.ExposePerServer != nil {
+		enabled := *other.ExposePerServer
+		p.ExposePerServer = &enabled
+	}
+	mergeBool(&p.DiagnosticsTool, other.DiagnosticsTool)
+
+	if len(other.Servers) > 0 {
+		servers := make(map[string]ServerProfileConfig, len(p.Servers)+len(other.Servers))
+		for id, sp := range p.Servers {
+			servers[id] = sp
+		}
+		for id, sp := range other.Servers {
+			base := servers[id]
+			base.Tools.merge(&sp.Tools)
+			base.Resources.merge(&sp.Resources)
+			base.Prompts.merge(&sp.Prompts)
+			base.PromptArguments = mergePromptArguments(base.PromptArguments, sp.PromptArguments)
+			base.ResourceMetadata.merge(&sp.ResourceMetadata)
+			servers[id] = base
+		}
+		p.Servers = servers
+	}
+}
+
+func (f *ComponentFilter) merge(other *ComponentFilter) {
+	if len(other.Allow) > 0 {
+		f.Allow = append([]string(nil), other.Allow...)
+	}
+	if len(other.Deny) > 0 {
+		f.Deny = append([]string(nil), other.Deny...)
+	}
+	f.DenyReasons = mergeMap(f.DenyReasons, other.DenyReasons)
+	mergeString(&f.Message, other.Message)
+}
+
+func (f *ResourceMetadataFilter) merge(other *ResourceMetadataFilter) {
+	if other.MaxSize != 0 {
+		f.MaxSize = other.MaxSize
+	}
+	if len(other.Audience) > 0 {
+		f.Audience = append([]string(nil), other.Audience...)
+	}
+	if other.MinPriority != 0 {
+		f.MinPriority = other.MinPriority
+	}
+	mergeString(&f.Message, other.Message)
+}
+
+// mergePromptArguments returns a new map with src's prompt argument
+// constraints layered over dst's, prompt by prompt and argument by argument.
+func mergePromptArguments(dst, src map[string]map[string][]string) map[string]map[string][]string {
+	if len(src) == 0 {
+		return dst
+	}
+	merged := make(map[string]map[string][]string, len(dst)+len(src))
+	for prompt, args := range dst {
+		merged[prompt] = args
+	}
+	for prompt, args := range src {
+		combined := make(map[string][]string, len(merged[prompt])+len(args))
+		for arg, values := range merged[prompt] {
+			combined[arg] = values
+		}
+		for arg, values := range args {
+			combined[arg] = append([]string(nil), values...)
+		}
+		merged[prompt] = combined
+	}
+	return merged
+}
+
+func (pc *PrefixComponentsConfig) merge(other *PrefixComponentsConfig) {
+	mergeBool(&pc.Tools, other.Tools)
+	mergeBool(&pc.Resources, other.Resources)
+	mergeBool(&pc.Prompts, other.Prompts)
+}
+
+func (hc *HTTPClientConfig) merge(other *HTTPClientConfig) {
+	mergeInt(&hc.MaxIdleConns, other.MaxIdleConns)
+	mergeInt(&hc.MaxIdleConnsPerHost, other.MaxIdleConnsPerHost)
+	mergeInt(&hc.MaxConnsPerHost, other.MaxConnsPerHost)
+	mergeInt(&hc.IdleConnTimeout, other.IdleConnTimeout)
+	mergeInt(&hc.MaxRetries, other.MaxRetries)
+	mergeInt(&hc.MaxRetryWait, other.MaxRetryWait)
+}
+
+func mergeBool(dst **bool, src *bool) {
+	if src != nil {
+		v := *src
+		*dst = &v
+	}
+}
+
+func mergeString(dst *string, src string) {
+	if src != "" {
+		*dst = src
+	}
+}
+
+func mergeInt(dst *int, src int) {
+	if src != 0 {
+		*dst = src
+	}
+}
+
+// mergeMap returns a new map with src's entries layered over dst's.
+func mergeMap(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	merged := make(map[string]string, len(dst)+len(src))
+	for k, v := range dst {
+		merged[k] = v
+	}
+	for k, v := range src {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,101 @@
+package config
+
+import "testing"
+
+func TestMerge_OverridesAndDeepMerges(t *testing.T) {
+	base := &RootConfig{
+		DefaultProfile: "safe",
+		Servers: map[string]ServerConfig{
+			"github": {
+				DisplayName: "GitHub",
+				Transport: ServerTransportConfig{
+					Kind:    "http",
+					URL:     "https://api.example.com/mcp",
+					Headers: map[string]string{"Authorization": "Bearer base", "X-Team": "core"},
+				},
+			},
+			"fs": {Transport: ServerTransportConfig{Kind: "stdio", Command: "fs-server", Args: []string{"/srv"}}},
+		},
+		Profiles: map[string]ProfileConfig{
+			"safe": {
+				Description: "Safe",
+				Servers: map[string]ServerProfileConfig{
+					"github": {Tools: ComponentFilter{Allow: []string{"get_*"}, Deny: []string{"delete_*"}}},
+					"fs":     {},
+				},
+			},
+		},
+		Hub: HubConfig{Enabled: true},
+	}
+	overlay := &RootConfig{
+		DefaultProfile: "dev",
+		Servers: map[string]ServerConfig{
+			"github": {Transport: ServerTransportConfig{Headers: map[string]string{"Authorization": "Bearer mine"}}},
+			"local":  {Transport: ServerTransportConfig{Kind: "stdio", Command: "local-server"}},
+		},
+		Profiles: map[string]ProfileConfig{
+			"safe": {
+				Servers: map[string]ServerProfileConfig{
+					"github": {Tools: ComponentFilter{Allow: []string{"list_*"}}},
+				},
+			},
+			"dev": {Servers: map[string]ServerProfileConfig{"local": {}}},
+		},
+		Hub: HubConfig{PrefixServerIDs: true},
+	}
+
+	baseHeaders := base.Servers["github"].Transport.Headers
+	base.Merge(overlay)
+
+	if base.DefaultProfile != "dev" {
+		t.Errorf("DefaultProfile = %q, want dev", base.DefaultProfile)
+	}
+	if !base.Hub.Enabled || !base.Hub.PrefixServerIDs {
+		t.Errorf("Hub = %+v, want enabled with prefixing", base.Hub)
+	}
+
+	github := base.Servers["github"]
+	if github.DisplayName != "GitHub" || github.Transport.URL != "https://api.example.com/mcp" {
+		t.Errorf("Expected unset overlay fields to keep base values, got %+v", github)
+	}
+	if github.Transport.Headers["Authorization"] != "Bearer mine" || github.Transport.Headers["X-Team"] != "core" {
+		t.Errorf("Headers = %v, want merged with overlay winning", github.Transport.Headers)
+	}
+	if baseHeaders["Authorization"] != "Bearer base" {
+		t.Error("Merge should not modify maps shared with the base config")
+	}
+	if _, ok := base.Servers["local"]; !ok {
+		t.Error("Expected overlay-only server to be added")
+	}
+	if args := base.Servers["fs"].Transport.Args; len(args) != 1 || args[0] != "/srv" {
+		t.Errorf("Expected untouched server to be kept, got args %v", args)
+	}
+
+	safe := base.Profiles["safe"]
+	if safe.Description != "Safe" || len(safe.Servers) != 2 {
+		t.Errorf("Expected profile servers merged by key, got %+v", safe)
+	}
+	tools := safe.Servers["github"].Tools
+	if len(tools.Allow) != 1 || tools.Allow[0] != "list_*" {
+		t.Errorf("Allow = %v, want overlay list to replace base list", tools.Allow)
+	}
+	if len(tools.Deny) != 1 || tools.Deny[0] != "delete_*" {
+		t.Errorf("Deny = %v, want base list kept when overlay list is empty", tools.Deny)
+	}
+	if _, ok := base.Profiles["dev"]; !ok {
+		t.Error("Expected overlay-only profile to be added")
+	}
+}
+
+func TestMerge_ProfilePrefixCanTurnOff(t *testing.T) {
+	on, off := true, false
+	base := &RootConfig{Profiles: map[string]ProfileConfig{"p": {PrefixServerIDs: &on, ExposePerServer: &on}}}
+	base.Merge(&RootConfig{Profiles: map[string]ProfileConfig{"p": {PrefixServerIDs: &off, ExposePerServer: &off}}})
+
+	if got := base.Profiles["p"].PrefixServerIDs; got == nil || *got {
+		t.Errorf("PrefixServerIDs = %v, want false", got)
+	}
+	if got := base.Profiles["p"].ExposePerServer; got == nil || *got {
+		t.Errorf("ExposePerServer = %v, want false", got)
+	}
+}
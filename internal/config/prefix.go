@@ -0,0 +1,57 @@
+package config
+
+// DefaultPrefixSeparator joins server IDs and names when prefixing is enabled.
+const DefaultPrefixSeparator = ":"
+
+// PrefixSettings returns whether the hub prefixes names with server IDs for
+// the given profile, and the separator it uses. Profile settings override the
+// hub-wide ones.
+func (cfg *RootConfig) PrefixSettings(profileName string) (enabled bool, separator string) {
+	enabled = cfg.Hub.PrefixServerIDs
+	separator = cfg.Hub.PrefixSeparator
+
+	if profile, ok := cfg.Profiles[profileName]; ok {
+		if profile.PrefixServerIDs != nil {
+			enabled = *profile.PrefixServerIDs
+		}
+		if profile.PrefixSeparator != "" {
+			separator = profile.PrefixSeparator
+		}
+	}
+
+	if separator == "" {
+		separator = DefaultPrefixSeparator
+	}
+	return enabled, separator
+}
+
+// PrefixedComponents says which kinds of component the hub prefixes with
+// server IDs.
+type PrefixedComponents struct {
+	Tools     bool
+	Resources bool
+	Prompts   bool
+}
+
+// Any reports whether any kind of component is prefixed.
+func (p PrefixedComponents) Any() bool {
+	return p.Tools || p.Resources || p.Prompts
+}
+
+// ComponentPrefixes returns which components the hub prefixes for the given
+// profile: hub.prefix's setting for each kind if set, else whether
+// PrefixSettings enables prefixing.
+func (cfg *RootConfig) ComponentPrefixes(profileName string) PrefixedComponents {
+	enabled, _ := cfg.PrefixSettings(profileName)
+	pick := func(override *bool) bool {
+		if override != nil {
+			return *override
+		}
+		return enabled
+	}
+	return PrefixedComponents{
+		Tools:     pick(cfg.Hub.Prefix.Tools),
+		Resources: pick(cfg.Hub.Prefix.Resources),
+		Prompts:   pick(cfg.Hub.Prefix.Prompts),
+	}
+}
@@ -0,0 +1,33 @@
+package config
+
+// ProfileIncludes reports whether the profile exposes server id: whether it
+// lists id, or, for a passthrough profile, whether id is configured at all.
+func (cfg *RootConfig) ProfileIncludes(profileName, id string) bool {
+	profile, ok := cfg.Profiles[profileName]
+	if !ok {
+		return false
+	}
+	if profile.Passthrough {
+		return cfg.HasServer(id)
+	}
+	_, ok = profile.Servers[id]
+	return ok
+}
+
+// ExposePerServerFor reports whether per-server endpoints are served for the
+// given profile: its exposePerServer if set, else the top-level setting.
+func (cfg *RootConfig) ExposePerServerFor(profileName string) bool {
+	if profile, ok := cfg.Profiles[profileName]; ok && profile.ExposePerServer != nil {
+		return *profile.ExposePerServer
+	}
+	return cfg.ExposePerServer
+}
+
+// DiagnosticsToolFor reports whether the hub lists its diagnostics tool for
+// the given profile: its diagnosticsTool if set, else the hub setting.
+func (cfg *RootConfig) DiagnosticsToolFor(profileName string) bool {
+	if profile, ok := cfg.Profiles[profileName]; ok && profile.DiagnosticsTool != nil {
+		return *profile.DiagnosticsTool
+	}
+	return cfg.Hub.DiagnosticsTool
+}
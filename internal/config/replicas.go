@@ -0,0 +1,42 @@
+package config
+
+import "sort"
+
+// HasServer reports whether id names a configured server or replica group,
+// either of which profiles and serverOrder may refer to.
+func (cfg *RootConfig) HasServer(id string) bool {
+	if _, ok := cfg.Servers[id]; ok {
+		return true
+	}
+	_, ok := cfg.Hub.ReplicaGroups[id]
+	return ok
+}
+
+// ReplicaGroupOf returns the replica group serverID belongs to, if any.
+func (cfg *RootConfig) ReplicaGroupOf(serverID string) (groupID string, ok bool) {
+	for groupID, group := range cfg.Hub.ReplicaGroups {
+		for _, member := range group.Servers {
+			if member.Server == serverID {
+				return groupID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// ProfileServerIDs returns the IDs of the configured servers profileName
+// exposes, directly or through a replica group, sorted.
+func (cfg *RootConfig) ProfileServerIDs(profileName string) []string {
+	var ids []string
+	for serverID := range cfg.Servers {
+		included := cfg.ProfileIncludes(profileName, serverID)
+		if groupID, ok := cfg.ReplicaGroupOf(serverID); ok {
+			included = included || cfg.ProfileIncludes(profileName, groupID)
+		}
+		if included {
+			ids = append(ids, serverID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
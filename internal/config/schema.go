@@ -0,0 +1,47 @@
+package config
+
+import (
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// SchemaDialect is the JSON Schema dialect used by Schema.
+const SchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema returns a JSON Schema describing RootConfig.
+// It is generated from the struct tags so it stays in sync with the types.
+func Schema() (*jsonschema.Schema, error) {
+	schema, err := jsonschema.For[RootConfig](nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every field is optional in the config file unless noted below,
+	// so drop the required lists inferred from the struct tags.
+	clearRequired(schema)
+
+	schema.Schema = SchemaDialect
+	schema.Title = "mcp2 configuration"
+	schema.Required = []string{"defaultProfile"}
+
+	// Allow configs to point at the schema for editor support.
+	schema.Properties["$schema"] = &jsonschema.Schema{Type: "string"}
+
+	transport := schema.Properties["servers"].AdditionalProperties.Properties["transport"]
+	transport.Required = []string{"kind"}
+	transport.Properties["kind"].Enum = []any{"stdio", "http"}
+
+	return schema, nil
+}
+
+// clearRequired removes required lists from a schema and all its subschemas.
+func clearRequired(s *jsonschema.Schema) {
+	if s == nil {
+		return
+	}
+	s.Required = nil
+	for _, prop := range s.Properties {
+		clearRequired(prop)
+	}
+	clearRequired(s.AdditionalProperties)
+	clearRequired(s.Items)
+}
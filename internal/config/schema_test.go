@@ -0,0 +1,68 @@
+package config
+
+import (
+	"os"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestSchema_ValidatesExampleConfigs(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() failed: %v", err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	for _, path := range []string{"../../example-config.yaml", "../../example-config-perserver.yaml"} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", path, err)
+		}
+		var doc map[string]any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			t.Fatalf("Failed to parse %s: %v", path, err)
+		}
+		if err := resolved.Validate(doc); err != nil {
+			t.Errorf("%s does not match schema: %v", path, err)
+		}
+	}
+}
+
+func TestSchema_RejectsTypos(t *testing.T) {
+	schema, err := Schema()
+	if err != nil {
+		t.Fatalf("Schema() failed: %v", err)
+	}
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	doc := map[string]any{
+		"defaultProfile": "safe",
+		"servers": map[string]any{
+			"s": map[string]any{
+				"transport": map[string]any{"kind": "stdio", "comand": "echo"},
+			},
+		},
+	}
+	if err := resolved.Validate(doc); err == nil {
+		t.Error("Expected misspelled field to fail validation")
+	}
+
+	doc = map[string]any{
+		"defaultProfile": "safe",
+		"servers": map[string]any{
+			"s": map[string]any{
+				"transport": map[string]any{"kind": "websocket"},
+			},
+		},
+	}
+	if err := resolved.Validate(doc); err == nil {
+		t.Error("Expected unknown transport kind to fail validation")
+	}
+}
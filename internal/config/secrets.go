@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// SecretCommandPrefix marks an env or header value that should be replaced by
+// the output of a command, e.g. "cmd:op read op://vault/item/token".
+const SecretCommandPrefix = "cmd:"
+
+// ResolveSecrets replaces env, header, OAuth client secret, and auth token values of the form "cmd:<command>"
+// with the trimmed stdout of running <command> through /bin/sh. Each distinct
+// command runs at most once. It should be called after ExpandEnvVars.
+func (cfg *RootConfig) ResolveSecrets(ctx context.Context) error {
+	cache := make(map[string]string)
+
+	resolve := func(owner, kind, key, value string) (string, error) {
+		command, ok := strings.CutPrefix(value, SecretCommandPrefix)
+		if !ok {
+			return value, nil
+		}
+		if out, ok := cache[command]; ok {
+			return out, nil
+		}
+		out, err := runSecretCommand(ctx, command)
+		if err != nil {
+			return "", fmt.Errorf("%s: %s %q: %w", owner, kind, key, err)
+		}
+		cache[command] = out
+		return out, nil
+	}
+
+	for serverID, server := range cfg.Servers {
+		owner := fmt.Sprintf("server %q", serverID)
+		for k, v := range server.Transport.Env {
+			resolved, err := resolve(owner, "env", k, v)
+			if err != nil {
+				return err
+			}
+			server.Transport.Env[k] = resolved
+		}
+		for k, v := range server.Transport.Headers {
+			resolved, err := resolve(owner, "header", k, v)
+			if err != nil {
+				return err
+			}
+			server.Transport.Headers[k] = resolved
+		}
+		if oauth := server.Transport.OAuth; oauth != nil {
+			resolved, err := resolve(owner, "oauth", "clientSecret", oauth.ClientSecret)
+			if err != nil {
+				return err
+			}
+			oauth.ClientSecret = resolved
+		}
+	}
+
+	for i, token := range cfg.Auth.Tokens {
+		resolved, err := resolve(fmt.Sprintf("auth token %d", i), "token", token.Name, token.Token)
+		if err != nil {
+			return err
+		}
+		cfg.Auth.Tokens[i].Token = resolved
+	}
+
+	return nil
+}
+
+// runSecretCommand runs a command through the shell and returns its stdout
+// without the trailing newline.
+func runSecretCommand(ctx context.Context, command string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("secret command failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("secret command failed: %w", err)
+	}
+
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
@@ -0,0 +1,74 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestResolveSecrets(t *testing.T) {
+	counter := filepath.Join(t.TempDir(), "count")
+	command := "cmd:echo run >> " + counter + "; echo s3cret"
+
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"server1": {
+				Transport: ServerTransportConfig{
+					Kind:    "http",
+					Headers: map[string]string{"X-Api-Key": command, "Accept": "application/json"},
+				},
+			},
+			"server2": {
+				Transport: ServerTransportConfig{
+					Kind: "stdio",
+					Env:  map[string]string{"API_KEY": command},
+				},
+			},
+		},
+	}
+
+	if err := cfg.ResolveSecrets(context.Background()); err != nil {
+		t.Fatalf("ResolveSecrets failed: %v", err)
+	}
+
+	if got := cfg.Servers["server1"].Transport.Headers["X-Api-Key"]; got != "s3cret" {
+		t.Errorf("Header = %q, want %q", got, "s3cret")
+	}
+	if got := cfg.Servers["server1"].Transport.Headers["Accept"]; got != "application/json" {
+		t.Errorf("Plain header changed: %q", got)
+	}
+	if got := cfg.Servers["server2"].Transport.Env["API_KEY"]; got != "s3cret" {
+		t.Errorf("Env = %q, want %q", got, "s3cret")
+	}
+
+	data, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runs := strings.Count(string(data), "run"); runs != 1 {
+		t.Errorf("Secret command ran %d times, want 1", runs)
+	}
+}
+
+func TestResolveSecrets_FailureIncludesStderr(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"server1": {
+				Transport: ServerTransportConfig{
+					Kind: "stdio",
+					Env:  map[string]string{"TOKEN": "cmd:echo 'vault locked' >&2; exit 1"},
+				},
+			},
+		},
+	}
+
+	err := cfg.ResolveSecrets(context.Background())
+	if err == nil {
+		t.Fatal("Expected error for failing secret command")
+	}
+	if !strings.Contains(err.Error(), "vault locked") || !strings.Contains(err.Error(), "TOKEN") {
+		t.Errorf("Error should include key and stderr, got: %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ApplySet applies a "path=value" override, as given to --set, to an existing
+// server's settings. Supported paths are:
+//
+//	servers.<id>.displayName
+//	servers.<id>.transport.{kind,command,cwd,url}
+//	servers.<id>.transport.args        (JSON array, or comma-separated)
+//	servers.<id>.transport.env.<KEY>
+//	servers.<id>.transport.headers.<Name>
+//
+// Values are applied before environment variable expansion, so they may
+// reference ${VAR}.
+func (cfg *RootConfig) ApplySet(override string) error {
+	path, value, ok := strings.Cut(override, "=")
+	if !ok {
+		return fmt.Errorf("--set %q: expected path=value", override)
+	}
+
+	parts := strings.SplitN(path, ".", 5)
+	if len(parts) < 3 || parts[0] != "servers" {
+		return fmt.Errorf("--set %q: path must start with servers.<id>.", path)
+	}
+	serverID := parts[1]
+	server, ok := cfg.Servers[serverID]
+	if !ok {
+		return fmt.Errorf("--set %q: unknown server %q", path, serverID)
+	}
+
+	field := strings.Join(parts[2:], ".")
+	t := &server.Transport
+	switch {
+	case field == "displayName":
+		server.DisplayName = value
+	case field == "transport.kind":
+		t.Kind = value
+	case field == "transport.command":
+		t.Command = value
+	case field == "transport.cwd":
+		t.Cwd = value
+	case field == "transport.url":
+		t.URL = value
+	case field == "transport.args":
+		args, err := parseArgs(value)
+		if err != nil {
+			return fmt.Errorf("--set %q: %w", path, err)
+		}
+		t.Args = args
+	case len(parts) == 5 && parts[2] == "transport" && parts[3] == "env":
+		t.Env = mergeMap(t.Env, map[string]string{parts[4]: value})
+	case len(parts) == 5 && parts[2] == "transport" && parts[3] == "headers":
+		t.Headers = mergeMap(t.Headers, map[string]string{parts[4]: value})
+	default:
+		return fmt.Errorf("--set %q: unsupported path", path)
+	}
+
+	cfg.Servers[serverID] = server
+	return nil
+}
+
+// parseArgs parses a JSON array of strings, or else a comma-separated list.
+func parseArgs(value string) ([]string, error) {
+	if strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var args []string
+		if err := json.Unmarshal([]byte(value), &args); err != nil {
+			return nil, fmt.Errorf("invalid args array: %w", err)
+		}
+		return args, nil
+	}
+	if value == "" {
+		return nil, nil
+	}
+	return strings.Split(value, ","), nil
+}
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplySet(t *testing.T) {
+	cfg := &RootConfig{
+		Servers: map[string]ServerConfig{
+			"github": {Transport: ServerTransportConfig{
+				Kind:    "http",
+				URL:     "https://api.example.com/mcp",
+				Headers: map[string]string{"X-Team": "core"},
+			}},
+			"fs": {Transport: ServerTransportConfig{Kind: "stdio", Command: "fs-server"}},
+		},
+	}
+
+	overrides := []string{
+		"servers.github.transport.url=http://staging:8080/mcp?a=b",
+		"servers.github.transport.headers.Authorization=Bearer ${TOKEN}",
+		"servers.fs.transport.command=./fs-dev",
+		`servers.fs.transport.args=["--root", "/tmp"]`,
+		"servers.fs.transport.env.DEBUG=1",
+	}
+	for _, o := range overrides {
+		if err := cfg.ApplySet(o); err != nil {
+			t.Fatalf("ApplySet(%q) failed: %v", o, err)
+		}
+	}
+
+	github := cfg.Servers["github"].Transport
+	if github.URL != "http://staging:8080/mcp?a=b" {
+		t.Errorf("URL = %q", github.URL)
+	}
+	if github.Headers["Authorization"] != "Bearer ${TOKEN}" || github.Headers["X-Team"] != "core" {
+		t.Errorf("Headers = %v", github.Headers)
+	}
+	fs := cfg.Servers["fs"].Transport
+	if fs.Command != "./fs-dev" || strings.Join(fs.Args, " ") != "--root /tmp" || fs.Env["DEBUG"] != "1" {
+		t.Errorf("fs transport = %+v", fs)
+	}
+
+	if err := cfg.ApplySet("servers.fs.transport.args=a,b"); err != nil || len(cfg.Servers["fs"].Transport.Args) != 2 {
+		t.Errorf("Expected comma-separated args, got %v (err %v)", cfg.Servers["fs"].Transport.Args, err)
+	}
+}
+
+func TestApplySet_Errors(t *testing.T) {
+	cfg := &RootConfig{Servers: map[string]ServerConfig{"fs": {}}}
+
+	for _, o := range []string{
+		"servers.fs.transport.url",
+		"hub.enabled=true",
+		"servers.missing.transport.url=x",
+		"servers.fs.transport.port=1",
+		"servers.fs.transport.args=[oops",
+	} {
+		if err := cfg.ApplySet(o); err == nil {
+			t.Errorf("ApplySet(%q) should fail", o)
+		}
+	}
+}
@@ -1,57 +1,444 @@
 // Package config handles configuration loading and validation for mcp2.
 package config
 
+import "strings"
+
 // ComponentFilter defines allow/deny rules for tools, resources, or prompts.
 type ComponentFilter struct {
-	Allow []string `json:"allow" yaml:"allow"` // names or globs
-	Deny  []string `json:"deny" yaml:"deny"`
+	Allow []string `json:"allow,omitempty" yaml:"allow,omitempty"` // names or globs
+	Deny  []string `json:"deny,omitempty" yaml:"deny,omitempty"`
+
+	// DenyReasons maps deny patterns to a message returned to the client
+	// when that pattern blocks a request
+	DenyReasons map[string]string `json:"denyReasons,omitempty" yaml:"denyReasons,omitempty"`
+
+	// Message is returned to the client when this filter blocks a request
+	// and no DenyReasons entry applies
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
 }
 
 // ServerProfileConfig defines per-server filtering rules for a profile.
 type ServerProfileConfig struct {
-	Tools     ComponentFilter `json:"tools" yaml:"tools"`
-	Resources ComponentFilter `json:"resources" yaml:"resources"`
-	Prompts   ComponentFilter `json:"prompts" yaml:"prompts"`
+	Tools     ComponentFilter `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Resources ComponentFilter `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Prompts   ComponentFilter `json:"prompts,omitempty" yaml:"prompts,omitempty"`
+
+	// PromptArguments restricts the values allowed prompt arguments take,
+	// keyed by prompt name and then argument name, as allowed value patterns.
+	// Prompts and arguments not listed are unrestricted.
+	PromptArguments map[string]map[string][]string `json:"promptArguments,omitempty" yaml:"promptArguments,omitempty"`
+
+	// ResourceMetadata denies resources by what they declare in
+	// resources/list, so they are neither listed nor read
+	ResourceMetadata ResourceMetadataFilter `json:"resourceMetadata,omitempty" yaml:"resourceMetadata,omitempty"`
+}
+
+// ResourceMetadataFilter denies resources by their declared size and
+// annotations. A resource that doesn't declare a field isn't judged on it.
+type ResourceMetadataFilter struct {
+	// MaxSize denies resources declaring a larger size, in bytes (0 = no limit)
+	MaxSize int64 `json:"maxSize,omitempty" yaml:"maxSize,omitempty"`
+
+	// Audience denies resources annotated for an audience that includes
+	// none of these roles ("user", "assistant")
+	Audience []string `json:"audience,omitempty" yaml:"audience,omitempty"`
+
+	// MinPriority denies resources annotated with a lower priority (0-1)
+	MinPriority float64 `json:"minPriority,omitempty" yaml:"minPriority,omitempty"`
+
+	// Message is returned to the client when this filter blocks a read
+	Message string `json:"message,omitempty" yaml:"message,omitempty"`
+}
+
+// IsSet reports whether the filter has any rules.
+func (f *ResourceMetadataFilter) IsSet() bool {
+	return f.MaxSize > 0 || len(f.Audience) > 0 || f.MinPriority > 0
 }
 
 // ServerTransportConfig defines how to connect to an upstream MCP server.
 type ServerTransportConfig struct {
 	// Kind is either "stdio" or "http"
-	Kind string `json:"kind" yaml:"kind"`
+	Kind string `json:"kind,omitempty" yaml:"kind,omitempty"`
 
 	// For stdio transport
-	Command string            `json:"command" yaml:"command"`
-	Args    []string          `json:"args" yaml:"args"`
-	Env     map[string]string `json:"env" yaml:"env"`
+	Command string            `json:"command,omitempty" yaml:"command,omitempty"`
+	Args    []string          `json:"args,omitempty" yaml:"args,omitempty"`
+	Env     map[string]string `json:"env,omitempty" yaml:"env,omitempty"`
+	Cwd     string            `json:"cwd,omitempty" yaml:"cwd,omitempty"`     // working directory for the command
+	Shell   bool              `json:"shell,omitempty" yaml:"shell,omitempty"` // run command through the user's shell
+
+	// InheritEnv passes mcp2's environment to the command. When false, the
+	// command gets only PATH and Env. Defaults to hub.inheritEnv.
+	InheritEnv *bool `json:"inheritEnv,omitempty" yaml:"inheritEnv,omitempty"`
+
+	// ProtocolVersion pins the MCP protocol version mcp2 requests when
+	// initializing this server, instead of the latest it supports
+	ProtocolVersion string `json:"protocolVersion,omitempty" yaml:"protocolVersion,omitempty"`
 
 	// For HTTP transport (Streamable HTTP / SSE)
-	URL     string            `json:"url" yaml:"url"`
-	Headers map[string]string `json:"headers" yaml:"headers"`
+	URL     string            `json:"url,omitempty" yaml:"url,omitempty"`
+	Headers map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+
+	// HTTPClient overrides the hub-wide connection pool settings for this server
+	HTTPClient HTTPClientConfig `json:"httpClient,omitempty" yaml:"httpClient,omitempty"`
+
+	// OAuth obtains and refreshes a bearer token via the client-credentials flow
+	OAuth *OAuthConfig `json:"oauth,omitempty" yaml:"oauth,omitempty"`
+}
+
+// OAuthConfig configures the OAuth 2.0 client-credentials flow for an HTTP upstream.
+type OAuthConfig struct {
+	TokenURL     string   `json:"tokenURL,omitempty" yaml:"tokenURL,omitempty"`
+	ClientID     string   `json:"clientID,omitempty" yaml:"clientID,omitempty"`
+	ClientSecret string   `json:"clientSecret,omitempty" yaml:"clientSecret,omitempty"`
+	Scopes       []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+}
+
+// HTTPClientConfig tunes the connection pool used for HTTP upstreams.
+// Zero values fall back to the hub-wide setting, then to Go's defaults.
+type HTTPClientConfig struct {
+	MaxIdleConns        int `json:"maxIdleConns,omitempty" yaml:"maxIdleConns,omitempty"`
+	MaxIdleConnsPerHost int `json:"maxIdleConnsPerHost,omitempty" yaml:"maxIdleConnsPerHost,omitempty"`
+	MaxConnsPerHost     int `json:"maxConnsPerHost,omitempty" yaml:"maxConnsPerHost,omitempty"`
+	// IdleConnTimeout is in seconds
+	IdleConnTimeout int `json:"idleConnTimeout,omitempty" yaml:"idleConnTimeout,omitempty"`
+
+	// MaxRetries retries requests rejected with 429/503 and a Retry-After
+	// header up to this many times (0 = no retries)
+	MaxRetries int `json:"maxRetries,omitempty" yaml:"maxRetries,omitempty"`
+	// MaxRetryWait caps the Retry-After delay honored, in seconds (default 30);
+	// responses asking for a longer wait are returned to the caller
+	MaxRetryWait int `json:"maxRetryWait,omitempty" yaml:"maxRetryWait,omitempty"`
 }
 
 // ServerConfig defines an upstream MCP server.
 type ServerConfig struct {
-	DisplayName string                `json:"displayName" yaml:"displayName"`
-	Transport   ServerTransportConfig `json:"transport" yaml:"transport"`
+	DisplayName string                `json:"displayName,omitempty" yaml:"displayName,omitempty"`
+	Transport   ServerTransportConfig `json:"transport,omitempty" yaml:"transport,omitempty"`
+
+	// Required makes serve abort if the server fails to connect. Optional
+	// servers are logged and skipped instead, and /readyz ignores them.
+	// Defaults to true.
+	Required *bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// DependsOn lists servers that must connect before this one starts,
+	// e.g. one it proxies. If any of them fails, so does this server
+	DependsOn []string `json:"dependsOn,omitempty" yaml:"dependsOn,omitempty"`
+
+	// InitializationTimeout, in seconds, makes serve wait for the server to
+	// answer tools/list after connecting; servers that don't answer in time
+	// are excluded (0 = don't wait)
+	InitializationTimeout int `json:"initializationTimeout,omitempty" yaml:"initializationTimeout,omitempty"`
+
+	// MaxConcurrentRequests limits requests in flight to this server; further
+	// requests wait for a free slot (0 = unlimited)
+	MaxConcurrentRequests int `json:"maxConcurrentRequests,omitempty" yaml:"maxConcurrentRequests,omitempty"`
+
+	// MaxQueuedRequests bounds how many requests wait for a slot under
+	// maxConcurrentRequests; further requests fail at once as overloaded
+	// (0 = unlimited)
+	MaxQueuedRequests int `json:"maxQueuedRequests,omitempty" yaml:"maxQueuedRequests,omitempty"`
+
+	// MaxQueueWait, in seconds, is how long a request waits for a slot
+	// before failing as overloaded (0 = as long as the caller waits)
+	MaxQueueWait int `json:"maxQueueWait,omitempty" yaml:"maxQueueWait,omitempty"`
+
+	// ToolPriority lists tool names or globs listed first, in this order;
+	// other tools follow alphabetically
+	ToolPriority []string `json:"toolPriority,omitempty" yaml:"toolPriority,omitempty"`
+
+	// KeepaliveInterval pings an HTTP upstream after this many seconds
+	// without a request, so idle connections aren't dropped by
+	// intermediaries (0 = disabled)
+	KeepaliveInterval int `json:"keepaliveInterval,omitempty" yaml:"keepaliveInterval,omitempty"`
+
+	// MaxTools caps how many of this server's allowed tools the hub lists,
+	// keeping the first by toolPriority order (0 = no limit)
+	MaxTools int `json:"maxTools,omitempty" yaml:"maxTools,omitempty"`
 }
 
+// IsRequired reports whether serve must connect to the server to start:
+// its required setting, true if unset.
+func (s *ServerConfig) IsRequired() bool {
+	return s.Required == nil || *s.Required
+}
+
+// SupportedProtocolVersions are the MCP protocol versions an upstream's
+// protocolVersion can pin, newest first. These are the versions the MCP
+// client library accepts in an initialize response.
+var SupportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
 // ProfileConfig defines a profile with per-server filtering rules.
 type ProfileConfig struct {
-	Description string                         `json:"description" yaml:"description"`
-	Servers     map[string]ServerProfileConfig `json:"servers" yaml:"servers"`
+	Description string                         `json:"description,omitempty" yaml:"description,omitempty"`
+	Servers     map[string]ServerProfileConfig `json:"servers,omitempty" yaml:"servers,omitempty"`
+
+	// PrefixServerIDs and PrefixSeparator override the hub settings for this
+	// profile when set
+	PrefixServerIDs *bool  `json:"prefixServerIDs,omitempty" yaml:"prefixServerIDs,omitempty"`
+	PrefixSeparator string `json:"prefixSeparator,omitempty" yaml:"prefixSeparator,omitempty"`
+
+	// ExposePerServer overrides the top-level exposePerServer for this
+	// profile when set
+	ExposePerServer *bool `json:"exposePerServer,omitempty" yaml:"exposePerServer,omitempty"`
+
+	// DiagnosticsTool overrides hub.diagnosticsTool for this profile when
+	// set
+	DiagnosticsTool *bool `json:"diagnosticsTool,omitempty" yaml:"diagnosticsTool,omitempty"`
+
+	// Passthrough allows everything on every configured server, listed in
+	// Servers or not, for trusted local use. Hub safe mode still applies.
+	Passthrough bool `json:"passthrough,omitempty" yaml:"passthrough,omitempty"`
+
+	// ListEnforcedOnly skips the call-time profile check for prefixed tool
+	// calls, relying on tools/list filtering alone. A client that knows the
+	// name of an unlisted tool can call it, so only set this for trusted
+	// clients.
+	ListEnforcedOnly bool `json:"listEnforcedOnly,omitempty" yaml:"listEnforcedOnly,omitempty"`
 }
 
 // HubConfig defines hub behavior.
 type HubConfig struct {
-	Enabled         bool `json:"enabled" yaml:"enabled"`
-	PrefixServerIDs bool `json:"prefixServerIDs" yaml:"prefixServerIDs"`
+	Enabled         bool `json:"enabled,omitempty" yaml:"enabled,omitempty"`
+	PrefixServerIDs bool `json:"prefixServerIDs,omitempty" yaml:"prefixServerIDs,omitempty"`
+	// PrefixSeparator joins server IDs and names when prefixing (default ":")
+	PrefixSeparator string `json:"prefixSeparator,omitempty" yaml:"prefixSeparator,omitempty"`
+	// Prefix overrides prefixServerIDs for tools, resources, or prompts
+	Prefix PrefixComponentsConfig `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+
+	// ForwardClientInfo tells upstreams which downstream client a request is
+	// made on behalf of. Off by default for privacy.
+	ForwardClientInfo bool `json:"forwardClientInfo,omitempty" yaml:"forwardClientInfo,omitempty"`
+
+	// DisplayNameInTitles sets each listed tool's title to include its
+	// server's displayName, e.g. "search (Filesystem)", leaving the name
+	// used for routing unchanged
+	DisplayNameInTitles bool `json:"displayNameInTitles,omitempty" yaml:"displayNameInTitles,omitempty"`
+
+	// RelayLogs forwards upstream log messages (notifications/message) to
+	// clients that set a log level. Off by default since it can be noisy.
+	RelayLogs bool `json:"relayLogs,omitempty" yaml:"relayLogs,omitempty"`
+
+	// InheritEnv is the default for stdio servers that don't set
+	// inheritEnv (true if unset)
+	InheritEnv *bool `json:"inheritEnv,omitempty" yaml:"inheritEnv,omitempty"`
+
+	// HTTPClient configures the connection pool shared by all HTTP upstreams
+	HTTPClient HTTPClientConfig `json:"httpClient,omitempty" yaml:"httpClient,omitempty"`
+
+	// ServerOrder lists server IDs in the order their tools, resources, and
+	// prompts are listed and unprefixed calls are tried; unlisted servers
+	// follow alphabetically
+	ServerOrder []string `json:"serverOrder,omitempty" yaml:"serverOrder,omitempty"`
+
+	// AllowElicitation forwards elicitation/create requests from upstreams to
+	// the downstream client whose request the upstream is serving
+	AllowElicitation bool `json:"allowElicitation,omitempty" yaml:"allowElicitation,omitempty"`
+
+	// AllowSampling likewise forwards sampling/createMessage requests, so
+	// upstreams can ask the client's model for a completion
+	AllowSampling bool `json:"allowSampling,omitempty" yaml:"allowSampling,omitempty"`
+
+	// SafeMode forces every profile read-only for incident response: tools
+	// not annotated readOnlyHint are neither listed nor callable, whatever
+	// the profile allows
+	SafeMode bool `json:"safeMode,omitempty" yaml:"safeMode,omitempty"`
+
+	// StatusTool lists a read-only mcp2_status tool, answered by the hub
+	// itself, while none of the profile's servers are connected, so agents
+	// can tell the user the backends are down instead of seeing no tools
+	StatusTool bool `json:"statusTool,omitempty" yaml:"statusTool,omitempty"`
+
+	// DiagnosticsTool lists a read-only mcp2_ping tool, answered by the hub
+	// itself, reporting its version, profile, and connected servers, so
+	// monitors that speak MCP can check the proxy is alive. Profiles can
+	// override it.
+	DiagnosticsTool bool `json:"diagnosticsTool,omitempty" yaml:"diagnosticsTool,omitempty"`
+
+	// MaxTools caps how many tools the hub lists in total, keeping the first
+	// by serverOrder and toolPriority order (0 = no limit)
+	MaxTools int `json:"maxTools,omitempty" yaml:"maxTools,omitempty"`
+
+	// MaxNameLength is the longest tool name clients accept (e.g. 64).
+	// Exposed names over it are flagged by validate and logged by the hub
+	// (0 = no check)
+	MaxNameLength int `json:"maxNameLength,omitempty" yaml:"maxNameLength,omitempty"`
+
+	// MaxResourceBytes caps the size of each resource content the hub
+	// returns; larger contents must be read in ranges (0 = no limit)
+	MaxResourceBytes int `json:"maxResourceBytes,omitempty" yaml:"maxResourceBytes,omitempty"`
+
+	// MaxListBytes caps the marshalled size of each aggregated tools,
+	// resources, and prompts list; the entries of the last servers in
+	// serverOrder order are dropped until it fits (0 = no limit)
+	MaxListBytes int `json:"maxListBytes,omitempty" yaml:"maxListBytes,omitempty"`
+
+	// Compress gzip- or deflate-encodes HTTP hub responses of at least
+	// CompressMinBytes (default 1024) for clients that accept it
+	Compress         bool `json:"compress,omitempty" yaml:"compress,omitempty"`
+	CompressMinBytes int  `json:"compressMinBytes,omitempty" yaml:"compressMinBytes,omitempty"`
+
+	// ConnectConcurrency limits how many servers serve connects to at once;
+	// servers wait for those in their dependsOn either way (0 = no limit)
+	ConnectConcurrency int `json:"connectConcurrency,omitempty" yaml:"connectConcurrency,omitempty"`
+
+	// UnknownMethod sets how the hub answers MCP methods it doesn't proxy,
+	// such as completion/complete: "passthrough" (default) logs them and
+	// leaves them to the hub's built-in handling, "reject" returns method
+	// not found, and "forward:<server>" sends them to that server
+	UnknownMethod string `json:"unknownMethod,omitempty" yaml:"unknownMethod,omitempty"`
+
+	// ReplicaGroups maps a logical server ID to interchangeable servers the
+	// hub balances calls across. Profiles and serverOrder refer to the
+	// group ID, and its members are not exposed individually.
+	ReplicaGroups map[string]ReplicaGroupConfig `json:"replicaGroups,omitempty" yaml:"replicaGroups,omitempty"`
+}
+
+// DefaultInheritEnv reports whether stdio servers that don't set inheritEnv
+// get mcp2's environment: hub.inheritEnv if set, else true.
+func (h *HubConfig) DefaultInheritEnv() bool {
+	return h.InheritEnv == nil || *h.InheritEnv
+}
+
+// Hub behaviors for methods it doesn't proxy; see HubConfig.UnknownMethod.
+const (
+	UnknownMethodPassthrough = "passthrough"
+	UnknownMethodReject      = "reject"
+	UnknownMethodForward     = "forward"
+)
+
+// UnknownMethodMode splits hub.unknownMethod into its mode and, for
+// forward, the server methods are forwarded to. Unset is passthrough.
+func (h *HubConfig) UnknownMethodMode() (mode, serverID string) {
+	if h.UnknownMethod == "" {
+		return UnknownMethodPassthrough, ""
+	}
+	mode, serverID, _ = strings.Cut(h.UnknownMethod, ":")
+	return mode, serverID
+}
+
+// PrefixComponentsConfig sets whether each kind of component is prefixed
+// with server IDs. Unset kinds follow prefixServerIDs, so resources can be
+// left unprefixed when their URIs are already unique, for example.
+type PrefixComponentsConfig struct {
+	Tools     *bool `json:"tools,omitempty" yaml:"tools,omitempty"`
+	Resources *bool `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Prompts   *bool `json:"prompts,omitempty" yaml:"prompts,omitempty"`
+}
+
+// ReplicaGroupConfig lists the servers that serve one logical server ID.
+type ReplicaGroupConfig struct {
+	Servers []ReplicaConfig `json:"servers" yaml:"servers"`
+	// Stateless spreads each client's calls across members. By default a
+	// client sticks to one member, moving only if it disconnects, for
+	// servers that keep per-session state.
+	Stateless bool `json:"stateless,omitempty" yaml:"stateless,omitempty"`
+}
+
+// ReplicaConfig is one member of a replica group.
+type ReplicaConfig struct {
+	Server string `json:"server" yaml:"server"`
+	// Weight is the member's share of calls relative to the others (default 1)
+	Weight int `json:"weight,omitempty" yaml:"weight,omitempty"`
+}
+
+// LoggingConfig defines log output settings.
+type LoggingConfig struct {
+	// Format is "text" (default) or "json"
+	Format string `json:"format,omitempty" yaml:"format,omitempty"`
+	// Level is "info" (default) or "debug"
+	Level string `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// File redirects logs from stderr to a file
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+	// MaxSizeMB rotates the log file once it exceeds this size (0 = never)
+	MaxSizeMB int `json:"maxSizeMB,omitempty" yaml:"maxSizeMB,omitempty"`
+	// MaxBackups is the number of rotated log files to keep
+	MaxBackups int `json:"maxBackups,omitempty" yaml:"maxBackups,omitempty"`
+}
+
+// AuthScopeHub and AuthScopeAll are the special AuthToken scopes for the hub
+// endpoint and for every endpoint. Any other scope is a server ID, granting
+// access to that server's per-server endpoint.
+const (
+	AuthScopeHub = "hub"
+	AuthScopeAll = "*"
+)
+
+// AuthConfig requires bearer tokens on the HTTP endpoints served by `serve`.
+// Auth is disabled when no tokens are configured.
+type AuthConfig struct {
+	Tokens []AuthToken `json:"tokens,omitempty" yaml:"tokens,omitempty"`
+}
+
+// AuthToken is a bearer token and the endpoints it may access.
+type AuthToken struct {
+	// Name identifies the token in logs
+	Name  string `json:"name,omitempty" yaml:"name,omitempty"`
+	Token string `json:"token,omitempty" yaml:"token,omitempty"`
+	// Scopes lists "hub", server IDs, or "*"
+	Scopes []string `json:"scopes,omitempty" yaml:"scopes,omitempty"`
+}
+
+// ListenerConfig is an HTTP listener serving a single profile, so
+// deployments can separate profiles by port, e.g. for firewall rules.
+type ListenerConfig struct {
+	// Address is the host:port to listen on; an empty host listens on every
+	// interface
+	Address string `json:"address,omitempty" yaml:"address,omitempty"`
+	// Profile is the profile served; defaults to the HTTP transport's
+	Profile string `json:"profile,omitempty" yaml:"profile,omitempty"`
+}
+
+// TransportProfiles names the default profile for each client transport.
+// Empty entries fall back to defaultProfile.
+type TransportProfiles struct {
+	Stdio string `json:"stdio,omitempty" yaml:"stdio,omitempty"`
+	HTTP  string `json:"http,omitempty" yaml:"http,omitempty"`
+}
+
+// Client transports that can have their own default profile.
+const (
+	TransportStdio = "stdio"
+	TransportHTTP  = "http"
+)
+
+// ProfileFor returns the default profile for clients on transport:
+// transportProfiles' entry if set, else defaultProfile.
+func (cfg *RootConfig) ProfileFor(transport string) string {
+	var profile string
+	switch transport {
+	case TransportStdio:
+		profile = cfg.TransportProfiles.Stdio
+	case TransportHTTP:
+		profile = cfg.TransportProfiles.HTTP
+	}
+	if profile == "" {
+		return cfg.DefaultProfile
+	}
+	return profile
 }
 
 // RootConfig is the top-level configuration structure.
 type RootConfig struct {
-	DefaultProfile  string                   `json:"defaultProfile" yaml:"defaultProfile"`
-	Servers         map[string]ServerConfig  `json:"servers" yaml:"servers"`
-	Profiles        map[string]ProfileConfig `json:"profiles" yaml:"profiles"`
-	Hub             HubConfig                `json:"hub" yaml:"hub"`
-	ExposePerServer bool                     `json:"exposePerServer" yaml:"exposePerServer"`
+	DefaultProfile  string                   `json:"defaultProfile,omitempty" yaml:"defaultProfile,omitempty"`
+	Servers         map[string]ServerConfig  `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Profiles        map[string]ProfileConfig `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+	Hub             HubConfig                `json:"hub,omitempty" yaml:"hub,omitempty"`
+	ExposePerServer bool                     `json:"exposePerServer,omitempty" yaml:"exposePerServer,omitempty"`
+	Logging         LoggingConfig            `json:"logging,omitempty" yaml:"logging,omitempty"`
+	Auth            AuthConfig               `json:"auth,omitempty" yaml:"auth,omitempty"`
+
+	// TransportProfiles overrides DefaultProfile for clients on a given
+	// transport, e.g. a trusted local stdio client and restricted HTTP ones
+	TransportProfiles TransportProfiles `json:"transportProfiles,omitempty" yaml:"transportProfiles,omitempty"`
+
+	// Listeners are named HTTP listeners, each serving one profile on its
+	// own address and sharing the upstream sessions. When set, serve starts
+	// them instead of the --port listener
+	Listeners map[string]ListenerConfig `json:"listeners,omitempty" yaml:"listeners,omitempty"`
+
+	// baseDir is the directory of the loaded config file. Relative stdio
+	// command and cwd paths are resolved against it.
+	baseDir string
 }
@@ -2,6 +2,11 @@ package config
 
 import (
 	"fmt"
+	"net"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 )
 
 // Validate checks the configuration for errors and inconsistencies.
@@ -14,12 +19,64 @@ func (cfg *RootConfig) Validate() error {
 		return fmt.Errorf("defaultProfile %q does not exist in profiles", cfg.DefaultProfile)
 	}
 
+	for transport, profile := range map[string]string{TransportStdio: cfg.TransportProfiles.Stdio, TransportHTTP: cfg.TransportProfiles.HTTP} {
+		if _, ok := cfg.Profiles[profile]; profile != "" && !ok {
+			return fmt.Errorf("transportProfiles.%s %q does not exist in profiles", transport, profile)
+		}
+	}
+
+	if err := validateDependencies(cfg); err != nil {
+		return err
+	}
+
+	if err := validateListeners(cfg); err != nil {
+		return err
+	}
+
+	if err := validateReplicaGroups(cfg); err != nil {
+		return err
+	}
+
 	// Check that all servers referenced in profiles exist
 	for profileName, profile := range cfg.Profiles {
-		for serverID := range profile.Servers {
-			if _, ok := cfg.Servers[serverID]; !ok {
+		for serverID, sp := range profile.Servers {
+			if !cfg.HasServer(serverID) {
 				return fmt.Errorf("profile %q references unknown server %q", profileName, serverID)
 			}
+			for kind, filter := range map[string]ComponentFilter{"tools": sp.Tools, "resources": sp.Resources, "prompts": sp.Prompts} {
+				if err := validateDenyReasons(&filter); err != nil {
+					return fmt.Errorf("profile %q server %q %s: %w", profileName, serverID, kind, err)
+				}
+			}
+			if err := validateResourceMetadata(&sp.ResourceMetadata); err != nil {
+				return fmt.Errorf("profile %q server %q resourceMetadata: %w", profileName, serverID, err)
+			}
+		}
+	}
+
+	// Server IDs prefix tool, resource, and prompt names as "server:name",
+	// so an ID containing a profile's separator would be routed to the
+	// wrong server. Replica group IDs stand in for their members' IDs, and
+	// a passthrough profile exposes every ID without listing any.
+	serverIDs := make([]string, 0, len(cfg.Servers)+len(cfg.Hub.ReplicaGroups))
+	for serverID := range cfg.Servers {
+		if _, grouped := cfg.ReplicaGroupOf(serverID); !grouped {
+			serverIDs = append(serverIDs, serverID)
+		}
+	}
+	for groupID := range cfg.Hub.ReplicaGroups {
+		serverIDs = append(serverIDs, groupID)
+	}
+	sort.Strings(serverIDs)
+	for profileName := range cfg.Profiles {
+		_, separator := cfg.PrefixSettings(profileName)
+		if !cfg.ComponentPrefixes(profileName).Any() {
+			continue
+		}
+		for _, serverID := range serverIDs {
+			if cfg.ProfileIncludes(profileName, serverID) && strings.Contains(serverID, separator) {
+				return fmt.Errorf("server ID %q must not contain the prefix separator %q used by profile %q", serverID, separator, profileName)
+			}
 		}
 	}
 
@@ -30,8 +87,67 @@ func (cfg *RootConfig) Validate() error {
 		}
 	}
 
-	// Check for name collisions if hub is enabled without prefixing
-	if cfg.Hub.Enabled && !cfg.Hub.PrefixServerIDs {
+	// Validate logging settings
+	switch cfg.Logging.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("logging.format %q is invalid (must be 'text' or 'json')", cfg.Logging.Format)
+	}
+	switch cfg.Logging.Level {
+	case "", "info", "debug":
+	default:
+		return fmt.Errorf("logging.level %q is invalid (must be 'info' or 'debug')", cfg.Logging.Level)
+	}
+	if cfg.Logging.MaxSizeMB < 0 || cfg.Logging.MaxBackups < 0 {
+		return fmt.Errorf("logging.maxSizeMB and logging.maxBackups must not be negative")
+	}
+
+	if err := validateAuthConfig(cfg); err != nil {
+		return err
+	}
+
+	if err := validateHTTPClientConfig(&cfg.Hub.HTTPClient); err != nil {
+		return fmt.Errorf("hub: %w", err)
+	}
+
+	if cfg.Hub.MaxTools < 0 {
+		return fmt.Errorf("hub.maxTools must not be negative")
+	}
+	if cfg.Hub.MaxNameLength < 0 {
+		return fmt.Errorf("hub.maxNameLength must not be negative")
+	}
+	if cfg.Hub.ConnectConcurrency < 0 {
+		return fmt.Errorf("hub.connectConcurrency must not be negative")
+	}
+	if cfg.Hub.MaxResourceBytes < 0 {
+		return fmt.Errorf("hub.maxResourceBytes must not be negative")
+	}
+	if cfg.Hub.MaxListBytes < 0 {
+		return fmt.Errorf("hub.maxListBytes must not be negative")
+	}
+	if cfg.Hub.CompressMinBytes < 0 {
+		return fmt.Errorf("hub.compressMinBytes must not be negative")
+	}
+	switch mode, serverID := cfg.Hub.UnknownMethodMode(); mode {
+	case UnknownMethodPassthrough, UnknownMethodReject:
+		if serverID != "" {
+			return fmt.Errorf("hub.unknownMethod %q takes no server", mode)
+		}
+	case UnknownMethodForward:
+		if !cfg.HasServer(serverID) {
+			return fmt.Errorf("hub.unknownMethod forwards to unknown server %q", serverID)
+		}
+	default:
+		return fmt.Errorf("hub.unknownMethod %q must be passthrough, reject, or forward:<server>", cfg.Hub.UnknownMethod)
+	}
+	for _, serverID := range cfg.Hub.ServerOrder {
+		if !cfg.HasServer(serverID) {
+			return fmt.Errorf("hub.serverOrder references unknown server %q", serverID)
+		}
+	}
+
+	// Check for name collisions in profiles served without prefixing
+	if cfg.Hub.Enabled {
 		if err := checkNameCollisions(cfg); err != nil {
 			return err
 		}
@@ -41,15 +157,63 @@ func (cfg *RootConfig) Validate() error {
 }
 
 func validateServerConfig(serverID string, server *ServerConfig) error {
+	if server.InitializationTimeout < 0 {
+		return fmt.Errorf("server %q: initializationTimeout must not be negative", serverID)
+	}
+	if server.MaxConcurrentRequests < 0 {
+		return fmt.Errorf("server %q: maxConcurrentRequests must not be negative", serverID)
+	}
+	if server.MaxQueuedRequests < 0 {
+		return fmt.Errorf("server %q: maxQueuedRequests must not be negative", serverID)
+	}
+	if server.MaxQueueWait < 0 {
+		return fmt.Errorf("server %q: maxQueueWait must not be negative", serverID)
+	}
+	if (server.MaxQueuedRequests > 0 || server.MaxQueueWait > 0) && server.MaxConcurrentRequests == 0 {
+		return fmt.Errorf("server %q: maxQueuedRequests and maxQueueWait need maxConcurrentRequests", serverID)
+	}
+	if server.MaxTools < 0 {
+		return fmt.Errorf("server %q: maxTools must not be negative", serverID)
+	}
+	if server.KeepaliveInterval < 0 {
+		return fmt.Errorf("server %q: keepaliveInterval must not be negative", serverID)
+	}
+	if server.KeepaliveInterval > 0 && server.Transport.Kind != "http" {
+		return fmt.Errorf("server %q: keepaliveInterval is only supported for http transport", serverID)
+	}
+	if v := server.Transport.ProtocolVersion; v != "" && !slices.Contains(SupportedProtocolVersions, v) {
+		return fmt.Errorf("server %q: unsupported protocolVersion %q (must be one of %s)",
+			serverID, v, strings.Join(SupportedProtocolVersions, ", "))
+	}
 	switch server.Transport.Kind {
 	case "stdio":
 		if server.Transport.Command == "" {
 			return fmt.Errorf("server %q: stdio transport requires 'command' to be set", serverID)
 		}
+		if server.Transport.Cwd != "" {
+			info, err := os.Stat(server.Transport.Cwd)
+			if err != nil {
+				return fmt.Errorf("server %q: cwd %q does not exist", serverID, server.Transport.Cwd)
+			}
+			if !info.IsDir() {
+				return fmt.Errorf("server %q: cwd %q is not a directory", serverID, server.Transport.Cwd)
+			}
+		}
 	case "http":
 		if server.Transport.URL == "" {
 			return fmt.Errorf("server %q: http transport requires 'url' to be set", serverID)
 		}
+		if err := validateHTTPClientConfig(&server.Transport.HTTPClient); err != nil {
+			return fmt.Errorf("server %q: %w", serverID, err)
+		}
+		if oauth := server.Transport.OAuth; oauth != nil {
+			if oauth.TokenURL == "" || oauth.ClientID == "" {
+				return fmt.Errorf("server %q: oauth requires 'tokenURL' and 'clientID' to be set", serverID)
+			}
+			if hasHeader(server.Transport.Headers, "Authorization") {
+				return fmt.Errorf("server %q: oauth cannot be combined with an Authorization header", serverID)
+			}
+		}
 	case "":
 		return fmt.Errorf("server %q: transport 'kind' must be specified (stdio or http)", serverID)
 	default:
@@ -58,15 +222,206 @@ func validateServerConfig(serverID string, server *ServerConfig) error {
 	return nil
 }
 
+// hasHeader reports whether headers sets name. HTTP header names are case
+// insensitive, so "authorization" sets Authorization too.
+func hasHeader(headers map[string]string, name string) bool {
+	for key := range headers {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
 func checkNameCollisions(cfg *RootConfig) error {
 	// This is a simplified check. In a full implementation, we would need to
 	// actually connect to servers and query their tools/resources/prompts.
 	// For now, we just warn that collision detection requires prefix mode.
 
-	// Count servers - if more than 1, recommend prefix mode
-	if len(cfg.Servers) > 1 {
-		return fmt.Errorf("hub is enabled with multiple servers but prefixServerIDs is false; " +
-			"this may cause name collisions. Consider setting hub.prefixServerIDs to true")
+	// Count servers per profile with unprefixed tools or prompts - if more
+	// than 1, recommend prefix mode. Resource URIs are usually unique on
+	// their own, so they may be left unprefixed.
+	for profileName, profile := range cfg.Profiles {
+		if prefixed := cfg.ComponentPrefixes(profileName); prefixed.Tools && prefixed.Prompts {
+			continue
+		}
+		if len(profile.Servers) > 1 || profile.Passthrough && len(cfg.Servers)+len(cfg.Hub.ReplicaGroups) > 1 {
+			return fmt.Errorf("profile %q uses multiple servers but doesn't prefix tool and prompt names; "+
+				"this may cause name collisions. Consider setting prefixServerIDs to true", profileName)
+		}
+	}
+	return nil
+}
+
+// validateDenyReasons checks that every denyReasons entry names a pattern in
+// the filter's deny list, since others could never apply.
+func validateDenyReasons(f *ComponentFilter) error {
+	for pattern := range f.DenyReasons {
+		found := false
+		for _, deny := range f.Deny {
+			if deny == pattern {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("denyReasons pattern %q is not in deny", pattern)
+		}
+	}
+	return nil
+}
+
+func validateHTTPClientConfig(hc *HTTPClientConfig) error {
+	if hc.MaxIdleConns < 0 || hc.MaxIdleConnsPerHost < 0 || hc.MaxConnsPerHost < 0 || hc.IdleConnTimeout < 0 ||
+		hc.MaxRetries < 0 || hc.MaxRetryWait < 0 {
+		return fmt.Errorf("httpClient settings must not be negative")
+	}
+	return nil
+}
+
+func validateAuthConfig(cfg *RootConfig) error {
+	for i, token := range cfg.Auth.Tokens {
+		if token.Token == "" {
+			return fmt.Errorf("auth.tokens[%d]: 'token' must be set", i)
+		}
+		if len(token.Scopes) == 0 {
+			return fmt.Errorf("auth.tokens[%d]: at least one scope is required", i)
+		}
+		for _, scope := range token.Scopes {
+			if scope == AuthScopeHub || scope == AuthScopeAll {
+				continue
+			}
+			if _, ok := cfg.Servers[scope]; !ok {
+				return fmt.Errorf("auth.tokens[%d]: scope %q is not %q, %q, or a server ID", i, scope, AuthScopeHub, AuthScopeAll)
+			}
+		}
+	}
+	return nil
+}
+
+// validateReplicaGroups checks that each replica group has members, that
+// they are configured servers belonging to no other group, and that group
+// IDs don't shadow server IDs.
+func validateReplicaGroups(cfg *RootConfig) error {
+	groupOf := make(map[string]string)
+	for groupID, group := range cfg.Hub.ReplicaGroups {
+		if _, ok := cfg.Servers[groupID]; ok {
+			return fmt.Errorf("hub.replicaGroups: group %q has the same ID as a server", groupID)
+		}
+		if len(group.Servers) == 0 {
+			return fmt.Errorf("hub.replicaGroups: group %q has no servers", groupID)
+		}
+		for _, member := range group.Servers {
+			if _, ok := cfg.Servers[member.Server]; !ok {
+				return fmt.Errorf("hub.replicaGroups: group %q references unknown server %q", groupID, member.Server)
+			}
+			if member.Weight < 0 {
+				return fmt.Errorf("hub.replicaGroups: group %q server %q: weight must not be negative", groupID, member.Server)
+			}
+			if other, ok := groupOf[member.Server]; ok {
+				return fmt.Errorf("hub.replicaGroups: server %q is in both %q and %q", member.Server, other, groupID)
+			}
+			groupOf[member.Server] = groupID
+		}
+	}
+	return nil
+}
+
+// validateListeners checks that each listener has a usable address, that no
+// two listeners share one, and that their profiles exist.
+func validateListeners(cfg *RootConfig) error {
+	names := make([]string, 0, len(cfg.Listeners))
+	for name := range cfg.Listeners {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	byAddress := make(map[string]string)
+	for _, name := range names {
+		l := cfg.Listeners[name]
+		if l.Address == "" {
+			return fmt.Errorf("listener %q: 'address' must be set", name)
+		}
+		if _, port, err := net.SplitHostPort(l.Address); err != nil || port == "" {
+			return fmt.Errorf("listener %q: address %q must be host:port", name, l.Address)
+		}
+		if other, ok := byAddress[l.Address]; ok {
+			return fmt.Errorf("listeners %q and %q share address %q", other, name, l.Address)
+		}
+		byAddress[l.Address] = name
+		if _, ok := cfg.Profiles[l.Profile]; l.Profile != "" && !ok {
+			return fmt.Errorf("listener %q: profile %q does not exist in profiles", name, l.Profile)
+		}
+	}
+	return nil
+}
+
+// validateDependencies checks that each server's dependsOn names other
+// configured servers and that the dependencies have no cycles.
+func validateDependencies(cfg *RootConfig) error {
+	ids := make([]string, 0, len(cfg.Servers))
+	for id, server := range cfg.Servers {
+		ids = append(ids, id)
+		for _, dep := range server.DependsOn {
+			if dep == id {
+				return fmt.Errorf("server %q: dependsOn lists itself", id)
+			}
+			if _, ok := cfg.Servers[dep]; !ok {
+				return fmt.Errorf("server %q: dependsOn references unknown server %q", id, dep)
+			}
+		}
+	}
+	sort.Strings(ids)
+
+	// Depth-first search, reporting the first cycle found as a path
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int, len(ids))
+	var path []string
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			start := slices.Index(path, id)
+			return fmt.Errorf("servers have a dependsOn cycle: %s", strings.Join(append(path[start:], id), " -> "))
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		path = append(path, id)
+		for _, dep := range cfg.Servers[id].DependsOn {
+			if err := visit(dep); err != nil {
+				return err
+			}
+		}
+		path = path[:len(path)-1]
+		state[id] = visited
+		return nil
+	}
+	for _, id := range ids {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateResourceMetadata checks a resource metadata filter's limits and
+// roles.
+func validateResourceMetadata(f *ResourceMetadataFilter) error {
+	if f.MaxSize < 0 {
+		return fmt.Errorf("maxSize must not be negative")
+	}
+	if f.MinPriority < 0 || f.MinPriority > 1 {
+		return fmt.Errorf("minPriority must be between 0 and 1")
+	}
+	for _, role := range f.Audience {
+		if role != "user" && role != "assistant" {
+			return fmt.Errorf("audience %q must be \"user\" or \"assistant\"", role)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Warnings reports likely mistakes that do not make the configuration
+// invalid. The result is sorted so output is stable.
+func (cfg *RootConfig) Warnings() []string {
+	var warnings []string
+	warnings = append(warnings, duplicateDisplayNames(cfg)...)
+	warnings = append(warnings, unreferencedServers(cfg)...)
+	warnings = append(warnings, emptyProfiles(cfg)...)
+	warnings = append(warnings, emptyValues(cfg)...)
+	warnings = append(warnings, longToolNames(cfg)...)
+
+	sort.Strings(warnings)
+	return warnings
+}
+
+// duplicateDisplayNames warns about servers sharing a display name, which
+// makes them indistinguishable in `profiles` output.
+func duplicateDisplayNames(cfg *RootConfig) []string {
+	byName := make(map[string][]string)
+	for serverID, server := range cfg.Servers {
+		if server.DisplayName != "" {
+			byName[server.DisplayName] = append(byName[server.DisplayName], serverID)
+		}
+	}
+
+	var warnings []string
+	for name, serverIDs := range byName {
+		if len(serverIDs) > 1 {
+			sort.Strings(serverIDs)
+			warnings = append(warnings, fmt.Sprintf("servers %s share displayName %q", strings.Join(serverIDs, ", "), name))
+		}
+	}
+	return warnings
+}
+
+// unreferencedServers warns about servers that no profile references, since
+// they are connected but never exposed. Replica group members count as
+// referenced when their group is.
+func unreferencedServers(cfg *RootConfig) []string {
+	var warnings []string
+	for serverID := range cfg.Servers {
+		id := serverID
+		if groupID, ok := cfg.ReplicaGroupOf(serverID); ok {
+			id = groupID
+		}
+		referenced := false
+		for name := range cfg.Profiles {
+			if cfg.ProfileIncludes(name, id) {
+				referenced = true
+				break
+			}
+		}
+		if !referenced {
+			warnings = append(warnings, fmt.Sprintf("server %q is not referenced by any profile", serverID))
+		}
+	}
+	return warnings
+}
+
+// emptyProfiles warns about profiles without servers, which expose nothing.
+func emptyProfiles(cfg *RootConfig) []string {
+	var warnings []string
+	for name, profile := range cfg.Profiles {
+		if len(profile.Servers) == 0 && !profile.Passthrough {
+			warnings = append(warnings, fmt.Sprintf("profile %q has no servers and exposes nothing", name))
+		}
+	}
+	return warnings
+}
+
+// emptyValues warns about env and header values that are empty after
+// expansion, which usually means a referenced variable is unset, e.g. an
+// Authorization header of "Bearer ${TOKEN}" that expanded to "Bearer ".
+func emptyValues(cfg *RootConfig) []string {
+	var warnings []string
+	for serverID, server := range cfg.Servers {
+		for k, v := range server.Transport.Env {
+			if isEmptyValue(v) {
+				warnings = append(warnings, fmt.Sprintf("server %q: env %s is empty (is a referenced variable unset?)", serverID, k))
+			}
+		}
+		for k, v := range server.Transport.Headers {
+			if isEmptyValue(v) {
+				warnings = append(warnings, fmt.Sprintf("server %q: header %s is empty (is a referenced variable unset?)", serverID, k))
+			}
+		}
+	}
+	return warnings
+}
+
+func isEmptyValue(v string) bool {
+	v = strings.TrimSpace(v)
+	return v == "" || strings.EqualFold(v, "Bearer") || strings.EqualFold(v, "Basic")
+}
+
+// longToolNames warns about prefixed tool names that will exceed
+// hub.maxNameLength. Actual tool names are only known at runtime, so this
+// checks the prefix alone and tools named literally in allow lists; the hub
+// logs the rest as it lists them.
+func longToolNames(cfg *RootConfig) []string {
+	limit := cfg.Hub.MaxNameLength
+	if limit == 0 {
+		return nil
+	}
+
+	var warnings []string
+	for name, profile := range cfg.Profiles {
+		_, separator := cfg.PrefixSettings(name)
+		if !cfg.ComponentPrefixes(name).Tools {
+			continue
+		}
+		for serverID, sp := range profile.Servers {
+			prefix := serverID + separator
+			if len(prefix) >= limit {
+				warnings = append(warnings, fmt.Sprintf("profile %q: prefix %q leaves no room for tool names within hub.maxNameLength (%d); use a shorter server ID", name, prefix, limit))
+				continue
+			}
+			for _, pattern := range sp.Tools.Allow {
+				if strings.ContainsAny(pattern, "*?[") {
+					continue
+				}
+				if exposed := prefix + pattern; len(exposed) > limit {
+					warnings = append(warnings, fmt.Sprintf("profile %q: tool name %q is %d characters, over hub.maxNameLength (%d); use a shorter server ID", name, exposed, len(exposed), limit))
+				}
+			}
+		}
+	}
+	return warnings
+}
@@ -0,0 +1,61 @@
+// Package logging configures log output for mcp2.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+)
+
+// Supported log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Supported log levels.
+const (
+	LevelInfo  = "info"
+	LevelDebug = "debug"
+)
+
+// stdHandler is slog's built-in handler, which writes through the standard
+// log package. It is captured before any call to slog.SetDefault.
+var stdHandler = slog.Default().Handler()
+
+// ParseLevel converts a level name into a slog level.
+func ParseLevel(level string) (slog.Level, error) {
+	switch level {
+	case "", LevelInfo:
+		return slog.LevelInfo, nil
+	case LevelDebug:
+		return slog.LevelDebug, nil
+	default:
+		return 0, fmt.Errorf("invalid log level %q (must be 'info' or 'debug')", level)
+	}
+}
+
+// Setup configures the default slog logger (and, through it, the standard
+// log package) to write to w in the given format and level.
+//
+// The text format keeps the standard log package's line format so existing
+// output is unchanged; the json format emits one JSON object per line.
+func Setup(w io.Writer, format, level string) error {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case "", FormatText:
+		log.SetOutput(w)
+		slog.SetLogLoggerLevel(lvl)
+		slog.SetDefault(slog.New(stdHandler))
+	case FormatJSON:
+		slog.SetDefault(slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: lvl})))
+	default:
+		return fmt.Errorf("invalid log format %q (must be 'text' or 'json')", format)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSetup_JSON(t *testing.T) {
+	defer Setup(os.Stderr, FormatText, LevelInfo)
+
+	var buf bytes.Buffer
+	if err := Setup(&buf, FormatJSON, LevelInfo); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	slog.Info("connected", "server", "github")
+	slog.Debug("hidden")
+	log.Printf("legacy line")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 log lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("Log line is not JSON: %v", err)
+	}
+	if entry["msg"] != "connected" || entry["server"] != "github" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatalf("Standard log line is not JSON: %v", err)
+	}
+	if entry["msg"] != "legacy line" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+}
+
+func TestSetup_TextDebug(t *testing.T) {
+	defer Setup(os.Stderr, FormatText, LevelInfo)
+
+	var buf bytes.Buffer
+	if err := Setup(&buf, FormatText, LevelDebug); err != nil {
+		t.Fatalf("Setup failed: %v", err)
+	}
+
+	slog.Debug("probe", "server", "github")
+	if !strings.Contains(buf.String(), "DEBUG probe server=github") {
+		t.Errorf("Expected debug line, got %q", buf.String())
+	}
+}
+
+func TestSetup_Invalid(t *testing.T) {
+	if err := Setup(os.Stderr, "xml", LevelInfo); err == nil {
+		t.Error("Expected error for invalid format")
+	}
+	if err := Setup(os.Stderr, FormatText, "trace"); err == nil {
+		t.Error("Expected error for invalid level")
+	}
+}
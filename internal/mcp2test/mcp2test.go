@@ -0,0 +1,223 @@
+// Package mcp2test provides in-memory upstream MCP servers and helpers for
+// testing what a hub or per-server endpoint exposes.
+//
+// A typical test builds a manager from specs, creates a hub for a profile
+// and asserts its surface:
+//
+//	manager := mcp2test.NewManager(t,
+//		mcp2test.Upstream{ID: "files", Tools: mcp2test.Tools("read_file", "write_file")},
+//	)
+//	hub := proxy.NewHub(cfg, manager, "readonly")
+//	mcp2test.AssertSurface(t, hub.Server(), mcp2test.Surface{Tools: []string{"files:read_file"}})
+package mcp2test
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Upstream describes an in-memory upstream server. Its tools answer with
+// the text "<id>:<tool>", its resources with the text "<id>", and its
+// prompts with a user message "<id>:<prompt>".
+type Upstream struct {
+	ID          string
+	DisplayName string
+	Tools       []*mcp.Tool
+	Resources   []*mcp.Resource
+	Prompts     []*mcp.Prompt
+
+	// Setup, if set, is called with the server once the components above
+	// are added, to add ones that behave differently, such as tools that
+	// log or send progress, resource templates, or middleware.
+	Setup func(server *mcp.Server)
+}
+
+// Tools returns tool definitions with the given names that take any
+// object as input.
+func Tools(names ...string) []*mcp.Tool {
+	tools := make([]*mcp.Tool, len(names))
+	for i, name := range names {
+		tools[i] = &mcp.Tool{Name: name, InputSchema: map[string]any{"type": "object"}}
+	}
+	return tools
+}
+
+// Resources returns resource definitions for the given URIs, each named
+// after its URI.
+func Resources(uris ...string) []*mcp.Resource {
+	resources := make([]*mcp.Resource, len(uris))
+	for i, uri := range uris {
+		resources[i] = &mcp.Resource{Name: uri, URI: uri}
+	}
+	return resources
+}
+
+// Prompts returns prompt definitions with the given names and no
+// arguments.
+func Prompts(names ...string) []*mcp.Prompt {
+	prompts := make([]*mcp.Prompt, len(names))
+	for i, name := range names {
+		prompts[i] = &mcp.Prompt{Name: name}
+	}
+	return prompts
+}
+
+// NewServer returns an MCP server implementing spec. Tools without an input
+// schema take any object.
+func NewServer(spec Upstream) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: spec.ID, Version: "1.0.0"}, nil)
+	for _, tool := range spec.Tools {
+		if tool.InputSchema == nil {
+			t := *tool
+			t.InputSchema = map[string]any{"type": "object"}
+			tool = &t
+		}
+		text := spec.ID + ":" + tool.Name
+		server.AddTool(tool, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}}, nil
+		})
+	}
+	for _, resource := range spec.Resources {
+		server.AddResource(resource, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{URI: req.Params.URI, Text: spec.ID}}}, nil
+		})
+	}
+	for _, prompt := range spec.Prompts {
+		text := spec.ID + ":" + prompt.Name
+		server.AddPrompt(prompt, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+				{Role: "user", Content: &mcp.TextContent{Text: text}},
+			}}, nil
+		})
+	}
+	if spec.Setup != nil {
+		spec.Setup(server)
+	}
+	return server
+}
+
+// NewUpstream starts an in-memory server for spec and returns an upstream
+// connected to it. Both are closed when the test ends.
+func NewUpstream(t testing.TB, spec Upstream) *upstream.Upstream {
+	t.Helper()
+	u := upstream.NewUpstream(spec.ID, Connect(t, NewServer(spec)))
+	u.DisplayName = spec.DisplayName
+	return u
+}
+
+// NewManager returns a manager holding an upstream for each spec.
+func NewManager(t testing.TB, specs ...Upstream) *upstream.Manager {
+	t.Helper()
+	manager := upstream.NewManager()
+	Add(t, manager, specs...)
+	return manager
+}
+
+// Add adds an upstream for each spec to manager. Their clients use the
+// manager's client options, as connected upstreams' do, so progress, log
+// messages, list changes, elicitation, and sampling reach the manager's
+// callbacks; enable elicitation or sampling on manager before adding.
+func Add(t testing.TB, manager *upstream.Manager, specs ...Upstream) {
+	t.Helper()
+	for _, spec := range specs {
+		u := upstream.NewUpstream(spec.ID, ConnectWith(t, NewServer(spec), manager.ClientOptions(spec.ID)))
+		u.DisplayName = spec.DisplayName
+		if err := manager.Add(u); err != nil {
+			t.Fatalf("Failed to add upstream %q: %v", spec.ID, err)
+		}
+	}
+}
+
+// Connect connects an in-memory client to server, such as an upstream
+// server or a hub. The session is closed when the test ends.
+func Connect(t testing.TB, server *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+	return ConnectWith(t, server, nil)
+}
+
+// ConnectWith is Connect with a client using opts, such as handlers for
+// notifications or elicitation the server sends.
+func ConnectWith(t testing.TB, server *mcp.Server, opts *mcp.ClientOptions) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp2test", Version: "1.0.0"}, opts)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+// Surface is what a server exposes: its tool and prompt names and resource
+// URIs, each sorted.
+type Surface struct {
+	Tools     []string
+	Resources []string
+	Prompts   []string
+}
+
+// ListSurface connects to server and lists everything it exposes.
+func ListSurface(t testing.TB, server *mcp.Server) Surface {
+	t.Helper()
+
+	ctx := context.Background()
+	session := Connect(t, server)
+
+	var s Surface
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Failed to list tools: %v", err)
+		}
+		s.Tools = append(s.Tools, tool.Name)
+	}
+	for resource, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Failed to list resources: %v", err)
+		}
+		s.Resources = append(s.Resources, resource.URI)
+	}
+	for prompt, err := range session.Prompts(ctx, nil) {
+		if err != nil {
+			t.Fatalf("Failed to list prompts: %v", err)
+		}
+		s.Prompts = append(s.Prompts, prompt.Name)
+	}
+	sort.Strings(s.Tools)
+	sort.Strings(s.Resources)
+	sort.Strings(s.Prompts)
+	return s
+}
+
+// AssertSurface fails the test unless server exposes exactly want. The
+// order of want's lists doesn't matter.
+func AssertSurface(t testing.TB, server *mcp.Server, want Surface) {
+	t.Helper()
+
+	got := ListSurface(t, server)
+	assertNames(t, "tools", got.Tools, want.Tools)
+	assertNames(t, "resources", got.Resources, want.Resources)
+	assertNames(t, "prompts", got.Prompts, want.Prompts)
+}
+
+func assertNames(t testing.TB, kind string, got, want []string) {
+	t.Helper()
+	want = slices.Sorted(slices.Values(want))
+	if !slices.Equal(got, want) {
+		t.Errorf("Exposed %s = %v, want %v", kind, got, want)
+	}
+}
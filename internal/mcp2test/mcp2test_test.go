@@ -0,0 +1,118 @@
+package mcp2test_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/ain3sh/mcp2/internal/proxy"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAssertSurface_HubProfile(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"readonly": {
+				Servers: map[string]config.ServerProfileConfig{
+					"files": {
+						Tools:   config.ComponentFilter{Allow: []string{"read_*"}},
+						Prompts: config.ComponentFilter{Deny: []string{"*"}},
+					},
+					"docs": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+	manager := mcp2test.NewManager(t,
+		mcp2test.Upstream{
+			ID:      "files",
+			Tools:   mcp2test.Tools("read_file", "write_file"),
+			Prompts: mcp2test.Prompts("summarize"),
+		},
+		mcp2test.Upstream{
+			ID:        "docs",
+			Resources: mcp2test.Resources("docs:///readme"),
+			Prompts:   mcp2test.Prompts("explain"),
+		},
+	)
+	hub := proxy.NewHub(cfg, manager, "readonly")
+
+	mcp2test.AssertSurface(t, hub.Server(), mcp2test.Surface{
+		Tools:     []string{"files:read_file"},
+		Resources: []string{"docs:docs:///readme"},
+		Prompts:   []string{"docs:explain"},
+	})
+}
+
+func TestNewUpstream_Answers(t *testing.T) {
+	ctx := context.Background()
+	u := mcp2test.NewUpstream(t, mcp2test.Upstream{
+		ID:        "files",
+		Tools:     mcp2test.Tools("read_file"),
+		Resources: mcp2test.Resources("file:///a"),
+		Prompts:   mcp2test.Prompts("summarize"),
+	})
+
+	call, err := u.Session().CallTool(ctx, &mcp.CallToolParams{Name: "read_file"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if text := call.Content[0].(*mcp.TextContent).Text; text != "files:read_file" {
+		t.Errorf("CallTool text = %q, want files:read_file", text)
+	}
+
+	read, err := u.Session().ReadResource(ctx, &mcp.ReadResourceParams{URI: "file:///a"})
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if read.Contents[0].Text != "files" {
+		t.Errorf("ReadResource text = %q, want files", read.Contents[0].Text)
+	}
+
+	prompt, err := u.Session().GetPrompt(ctx, &mcp.GetPromptParams{Name: "summarize"})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if text := prompt.Messages[0].Content.(*mcp.TextContent).Text; text != "files:summarize" {
+		t.Errorf("GetPrompt text = %q, want files:summarize", text)
+	}
+}
+
+func TestAdd_RoutesNotificationsToManager(t *testing.T) {
+	ctx := context.Background()
+	manager := upstream.NewManager()
+	logs := make(chan string, 1)
+	manager.OnLog(func(serverID string, params *mcp.LoggingMessageParams) {
+		logs <- serverID
+	})
+	mcp2test.Add(t, manager, mcp2test.Upstream{ID: "files", Setup: func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: "noisy"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "error", Data: "disk full"})
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	}})
+
+	u, err := manager.Get("files")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	if err := u.Session().SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+	if _, err := u.Session().CallTool(ctx, &mcp.CallToolParams{Name: "noisy"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	select {
+	case serverID := <-logs:
+		if serverID != "files" {
+			t.Errorf("Log routed for %q, want files", serverID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the log message to reach the manager")
+	}
+}
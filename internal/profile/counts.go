@@ -0,0 +1,77 @@
+package profile
+
+import (
+	"sort"
+	"sync/atomic"
+)
+
+// Component names the kind of name a decision was made about.
+type Component string
+
+const (
+	ComponentTool     Component = "tool"
+	ComponentResource Component = "resource"
+	ComponentPrompt   Component = "prompt"
+)
+
+// DecisionCount is how many times the engine allowed and denied names of one
+// component on one server.
+type DecisionCount struct {
+	Profile   string    `json:"profile"`
+	Server    string    `json:"server"`
+	Component Component `json:"component"`
+	Allowed   uint64    `json:"allowed"`
+	Denied    uint64    `json:"denied"`
+}
+
+type countKey struct {
+	server    string
+	component Component
+}
+
+type decisionCounter struct {
+	allowed, denied atomic.Uint64
+}
+
+// record counts d against serverID and component and returns it. The
+// counter is created on first use; after that a decision costs a map load
+// and an atomic add.
+func (e *Engine) record(serverID string, component Component, d Decision) Decision {
+	key := countKey{server: serverID, component: component}
+	c, ok := e.counts.Load(key)
+	if !ok {
+		c, _ = e.counts.LoadOrStore(key, &decisionCounter{})
+	}
+	counter := c.(*decisionCounter)
+	if d.Allowed {
+		counter.allowed.Add(1)
+	} else {
+		counter.denied.Add(1)
+	}
+	return d
+}
+
+// Counts returns how many times each server's tools, resources, and prompts
+// were allowed and denied, across list and call phases, sorted by server and
+// component.
+func (e *Engine) Counts() []DecisionCount {
+	var counts []DecisionCount
+	e.counts.Range(func(k, v any) bool {
+		key, counter := k.(countKey), v.(*decisionCounter)
+		counts = append(counts, DecisionCount{
+			Profile:   e.profile,
+			Server:    key.server,
+			Component: key.component,
+			Allowed:   counter.allowed.Load(),
+			Denied:    counter.denied.Load(),
+		})
+		return true
+	})
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Server != counts[j].Server {
+			return counts[i].Server < counts[j].Server
+		}
+		return counts[i].Component < counts[j].Component
+	})
+	return counts
+}
@@ -0,0 +1,36 @@
+package profile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+func TestEngine_Counts(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"fs": {Tools: config.ComponentFilter{Deny: []string{"delete_*"}}},
+				},
+			},
+		},
+	}
+
+	engine := NewEngine(cfg, "test")
+	engine.IsToolAllowed("fs", "read_file")
+	engine.IsToolAllowed("fs", "read_dir")
+	engine.ExplainTool("fs", "delete_file")
+	engine.IsPromptAllowed("fs", "review")
+	engine.IsResourceAllowed("web", "https://example.com")
+
+	want := []DecisionCount{
+		{Profile: "test", Server: "fs", Component: ComponentPrompt, Allowed: 1},
+		{Profile: "test", Server: "fs", Component: ComponentTool, Allowed: 2, Denied: 1},
+		{Profile: "test", Server: "web", Component: ComponentResource, Denied: 1},
+	}
+	if got := engine.Counts(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Counts() = %+v, want %+v", got, want)
+	}
+}
@@ -2,16 +2,28 @@
 package profile
 
 import (
+	"fmt"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/redact"
 )
 
 // Engine provides policy queries for filtering MCP components based on profiles.
 type Engine struct {
 	config  *config.RootConfig
 	profile string
+
+	// counts holds a *decisionCounter per server and component.
+	counts sync.Map
+
+	// literals caches the literalSet of each pattern list explain has
+	// matched against; see matchList.
+	literals sync.Map
 }
 
 // NewEngine creates a new profile engine.
@@ -22,72 +34,324 @@ func NewEngine(cfg *config.RootConfig, profileName string) *Engine {
 	}
 }
 
+// Reason explains why a Decision allowed or denied a name.
+type Reason string
+
+const (
+	// ReasonProfileNotFound: the active profile does not exist.
+	ReasonProfileNotFound Reason = "profile not found"
+	// ReasonServerNotInProfile: the server is not listed in the profile.
+	ReasonServerNotInProfile Reason = "server not in profile"
+	// ReasonDenied: a deny pattern matched.
+	ReasonDenied Reason = "matched deny pattern"
+	// ReasonAllowListEmpty: no deny pattern matched and the allow list is empty.
+	ReasonAllowListEmpty Reason = "allow list is empty"
+	// ReasonAllowed: an allow pattern matched.
+	ReasonAllowed Reason = "matched allow pattern"
+	// ReasonNotAllowed: the allow list is non-empty and no pattern matched.
+	ReasonNotAllowed Reason = "no allow pattern matched"
+	// ReasonPassthrough: the profile is a passthrough profile.
+	ReasonPassthrough Reason = "passthrough profile"
+	// ReasonSafeMode: hub.safeMode is on and the tool is not annotated
+	// read-only.
+	ReasonSafeMode Reason = "safe mode: tool is not read-only"
+	// ReasonResourceMetadata: the resource's declared size or annotations
+	// are denied by the server's resourceMetadata filter.
+	ReasonResourceMetadata Reason = "denied by resource metadata"
+)
+
+// Decision is the result of evaluating a name against a profile, recording
+// how the decision was reached.
+type Decision struct {
+	Allowed bool
+	Reason  Reason
+	// Pattern is the deny or allow pattern that decided the outcome, if any.
+	Pattern string
+	// Filter is the component filter that was evaluated, if the server is
+	// in the profile.
+	Filter *config.ComponentFilter
+	// Message is the configured explanation for a denial: the deny reason
+	// for Pattern, or else the filter's message. Empty if none is set.
+	Message string
+}
+
+// HasServer reports whether the server is part of the active profile.
+func (e *Engine) HasServer(serverID string) bool {
+	return e.config.ProfileIncludes(e.profile, serverID)
+}
+
+// ListEnforcedOnly reports whether the active profile skips call-time checks
+// of tool calls, trusting list-time filtering.
+func (e *Engine) ListEnforcedOnly() bool {
+	return e.config.Profiles[e.profile].ListEnforcedOnly
+}
+
+// SafeMode reports whether hub.safeMode restricts every profile to tools
+// annotated read-only.
+func (e *Engine) SafeMode() bool {
+	return e.config.Hub.SafeMode
+}
+
 // IsToolAllowed checks if a tool is allowed for the given server in the active profile.
 func (e *Engine) IsToolAllowed(serverID, toolName string) bool {
-	return e.isAllowed(serverID, toolName, func(spc *config.ServerProfileConfig) *config.ComponentFilter {
-		return &spc.Tools
-	})
+	return e.ExplainTool(serverID, toolName).Allowed
 }
 
 // IsResourceAllowed checks if a resource URI is allowed for the given server in the active profile.
 func (e *Engine) IsResourceAllowed(serverID, uri string) bool {
-	return e.isAllowed(serverID, uri, func(spc *config.ServerProfileConfig) *config.ComponentFilter {
-		return &spc.Resources
-	})
+	return e.ExplainResource(serverID, uri).Allowed
 }
 
 // IsPromptAllowed checks if a prompt is allowed for the given server in the active profile.
 func (e *Engine) IsPromptAllowed(serverID, promptName string) bool {
-	return e.isAllowed(serverID, promptName, func(spc *config.ServerProfileConfig) *config.ComponentFilter {
-		return &spc.Prompts
+	return e.ExplainPrompt(serverID, promptName).Allowed
+}
+
+// CheckPromptArguments returns an error if any of args takes a value the
+// active profile's promptArguments constraints for the prompt don't allow.
+// Arguments without constraints, and constrained arguments that are absent,
+// are not checked, nor is anything in a passthrough profile.
+func (e *Engine) CheckPromptArguments(serverID, promptName string, args map[string]string) error {
+	profile := e.config.Profiles[e.profile]
+	if profile.Passthrough {
+		return nil
+	}
+	constraints := profile.Servers[serverID].PromptArguments[promptName]
+
+	names := make([]string, 0, len(constraints))
+	for name := range constraints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := args[name]
+		if !ok {
+			continue
+		}
+		if !matchesAny(value, constraints[name]) {
+			return fmt.Errorf("value %q for argument %q of prompt %q is not allowed by profile", redact.Value(name, value), name, promptName)
+		}
+	}
+	return nil
+}
+
+// ExplainTool reports how the active profile decides on a tool.
+func (e *Engine) ExplainTool(serverID, toolName string) Decision {
+	return e.record(serverID, ComponentTool, e.explain(serverID, toolName, func(spc *config.ServerProfileConfig) *config.ComponentFilter {
+		return &spc.Tools
+	}))
+}
+
+// ExplainToolCall is ExplainTool for a tool whose annotations are known. In
+// safe mode a tool not annotated read-only is denied whatever the profile
+// says; otherwise readOnly is ignored.
+func (e *Engine) ExplainToolCall(serverID, toolName string, readOnly bool) Decision {
+	if e.SafeMode() && !readOnly {
+		return e.record(serverID, ComponentTool, Decision{
+			Reason:  ReasonSafeMode,
+			Message: "safe mode is active, only read-only tools can be called",
+		})
+	}
+	return e.ExplainTool(serverID, toolName)
+}
+
+// ExplainResource reports how the active profile decides on a resource URI.
+func (e *Engine) ExplainResource(serverID, uri string) Decision {
+	return e.record(serverID, ComponentResource, e.explain(serverID, uri, func(spc *config.ServerProfileConfig) *config.ComponentFilter {
+		return &spc.Resources
+	}))
+}
+
+// ResourceMetadata is what a resource declares about itself in
+// resources/list. Zero values are fields it doesn't declare.
+type ResourceMetadata struct {
+	Size     int64
+	Audience []string
+	Priority float64
+}
+
+// HasResourceMetadataRules reports whether the active profile filters the
+// server's resources by their metadata, so reads need it to be decided.
+func (e *Engine) HasResourceMetadataRules(serverID string) bool {
+	profile := e.config.Profiles[e.profile]
+	if profile.Passthrough {
+		return false
+	}
+	filter := profile.Servers[serverID].ResourceMetadata
+	return filter.IsSet()
+}
+
+// ExplainResourceMetadata is ExplainResource for a resource whose metadata
+// is known: a resource the profile allows by URI is still denied if md
+// breaks the server's resourceMetadata filter. A nil md is not checked.
+func (e *Engine) ExplainResourceMetadata(serverID, uri string, md *ResourceMetadata) Decision {
+	d := e.explain(serverID, uri, func(spc *config.ServerProfileConfig) *config.ComponentFilter {
+		return &spc.Resources
 	})
+	if d.Allowed && md != nil && e.HasResourceMetadataRules(serverID) {
+		filter := e.config.Profiles[e.profile].Servers[serverID].ResourceMetadata
+		if message := resourceMetadataDenial(&filter, md); message != "" {
+			if filter.Message != "" {
+				message = filter.Message
+			}
+			d = Decision{Reason: ReasonResourceMetadata, Filter: d.Filter, Message: message}
+		}
+	}
+	return e.record(serverID, ComponentResource, d)
+}
+
+// resourceMetadataDenial returns why filter denies a resource with md, or
+// "" if it doesn't.
+func resourceMetadataDenial(filter *config.ResourceMetadataFilter, md *ResourceMetadata) string {
+	if filter.MaxSize > 0 && md.Size > filter.MaxSize {
+		return fmt.Sprintf("resource declares %d bytes, over the profile's limit of %d", md.Size, filter.MaxSize)
+	}
+	if len(filter.Audience) > 0 && len(md.Audience) > 0 {
+		matched := false
+		for _, role := range md.Audience {
+			matched = matched || slices.Contains(filter.Audience, role)
+		}
+		if !matched {
+			return fmt.Sprintf("resource is for audience %s, not %s", strings.Join(md.Audience, ", "), strings.Join(filter.Audience, ", "))
+		}
+	}
+	if filter.MinPriority > 0 && md.Priority > 0 && md.Priority < filter.MinPriority {
+		return fmt.Sprintf("resource priority %g is below the profile's minimum of %g", md.Priority, filter.MinPriority)
+	}
+	return ""
 }
 
-// isAllowed implements the core filtering logic.
-// Behavior:
-// - If allow list is empty: allow all except those in deny list
-// - If allow list is non-empty: allow only those matching allow patterns, then subtract deny patterns
-func (e *Engine) isAllowed(serverID, name string, getFilter func(*config.ServerProfileConfig) *config.ComponentFilter) bool {
+// ExplainPrompt reports how the active profile decides on a prompt.
+func (e *Engine) ExplainPrompt(serverID, promptName string) Decision {
+	return e.record(serverID, ComponentPrompt, e.explain(serverID, promptName, func(spc *config.ServerProfileConfig) *config.ComponentFilter {
+		return &spc.Prompts
+	}))
+}
+
+// explain evaluates name against the active profile.
+// Behavior, the same for tools, resources, and prompts:
+//   - Deny always wins: a name matching any deny pattern is denied, however
+//     specific the allow pattern it also matches
+//   - If allow list is empty: allow all except those in deny list
+//   - If allow list is non-empty: allow only those matching allow patterns, then subtract deny patterns
+func (e *Engine) explain(serverID, name string, getFilter func(*config.ServerProfileConfig) *config.ComponentFilter) Decision {
 	// Get the profile
 	profile, ok := e.config.Profiles[e.profile]
 	if !ok {
 		// If profile doesn't exist, deny by default
-		return false
+		return Decision{Reason: ReasonProfileNotFound}
+	}
+
+	// Passthrough profiles allow everything on configured servers
+	if profile.Passthrough {
+		if !e.config.HasServer(serverID) {
+			return Decision{Reason: ReasonServerNotInProfile}
+		}
+		return Decision{Allowed: true, Reason: ReasonPassthrough}
 	}
 
 	// Get the server profile config
 	serverProfile, ok := profile.Servers[serverID]
 	if !ok {
 		// If server not in profile, deny by default
-		return false
+		return Decision{Reason: ReasonServerNotInProfile}
 	}
 
 	// Get the component filter
 	filter := getFilter(&serverProfile)
 
 	// Check deny list first
-	if matchesAny(name, filter.Deny) {
-		return false
+	if pattern, ok := e.matchList(name, filter.Deny); ok {
+		message := filter.DenyReasons[pattern]
+		if message == "" {
+			message = filter.Message
+		}
+		return Decision{Reason: ReasonDenied, Pattern: pattern, Filter: filter, Message: message}
 	}
 
 	// If allow list is empty, allow everything (except what's denied)
 	if len(filter.Allow) == 0 {
-		return true
+		return Decision{Allowed: true, Reason: ReasonAllowListEmpty, Filter: filter}
 	}
 
 	// If allow list is non-empty, only allow what matches
-	return matchesAny(name, filter.Allow)
+	if pattern, ok := e.matchList(name, filter.Allow); ok {
+		return Decision{Allowed: true, Reason: ReasonAllowed, Pattern: pattern, Filter: filter}
+	}
+	return Decision{Reason: ReasonNotAllowed, Filter: filter, Message: filter.Message}
+}
+
+// Match reports whether name matches pattern using the same glob rules as
+// profile filters.
+func Match(name, pattern string) bool {
+	return matchPattern(name, pattern)
 }
 
 // matchesAny checks if a name matches any pattern in the list.
 // Supports glob patterns: *, **, and filepath-style globs.
 func matchesAny(name string, patterns []string) bool {
+	_, ok := firstMatch(name, patterns)
+	return ok
+}
+
+// firstMatch returns the first pattern in the list that matches name.
+func firstMatch(name string, patterns []string) (string, bool) {
 	for _, pattern := range patterns {
 		if matchPattern(name, pattern) {
-			return true
+			return pattern, true
 		}
 	}
-	return false
+	return "", false
+}
+
+// globChars are the characters that make a pattern more than a literal name.
+const globChars = "*?[{"
+
+// literalSet holds the names in a pattern list with no glob characters, or
+// is nil for a list with any.
+type literalSet map[string]bool
+
+// patternsKey identifies a pattern list by its backing array and length, so
+// a list replaced in the config gets its own cache entry.
+type patternsKey struct {
+	first *string
+	n     int
+}
+
+// matchList is firstMatch with a fast path for lists of literal names,
+// which most filters are: their names are looked up in a set, built on
+// first use, instead of matching each pattern in turn.
+func (e *Engine) matchList(name string, patterns []string) (string, bool) {
+	if len(patterns) == 0 {
+		return "", false
+	}
+	key := patternsKey{first: &patterns[0], n: len(patterns)}
+	v, ok := e.literals.Load(key)
+	if !ok {
+		v, _ = e.literals.LoadOrStore(key, newLiteralSet(patterns))
+	}
+	set := v.(literalSet)
+	if set == nil {
+		return firstMatch(name, patterns)
+	}
+	if set[name] {
+		return name, true
+	}
+	return "", false
+}
+
+// newLiteralSet returns the set of patterns, or nil if any of them is a
+// glob.
+func newLiteralSet(patterns []string) literalSet {
+	set := make(literalSet, len(patterns))
+	for _, pattern := range patterns {
+		if strings.ContainsAny(pattern, globChars) {
+			return nil
+		}
+		set[pattern] = true
+	}
+	return set
 }
 
 // matchPattern checks if a name matches a pattern.
@@ -1,6 +1,7 @@
 package profile
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/ain3sh/mcp2/internal/config"
@@ -187,6 +188,80 @@ func TestIsPromptAllowed(t *testing.T) {
 	}
 }
 
+func TestCheckPromptArguments(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"github": {
+						PromptArguments: map[string]map[string][]string{
+							"review_pr": {"repo": {"ain3sh/*", "golang/go"}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	engine := NewEngine(cfg, "test")
+
+	tests := []struct {
+		prompt  string
+		args    map[string]string
+		allowed bool
+	}{
+		{"review_pr", map[string]string{"repo": "ain3sh/mcp2", "pr": "12"}, true},
+		{"review_pr", map[string]string{"repo": "golang/go"}, true},
+		{"review_pr", map[string]string{"repo": "someone/else"}, false},
+		{"review_pr", map[string]string{"pr": "12"}, true},
+		{"summarize", map[string]string{"repo": "someone/else"}, true},
+	}
+
+	for _, tt := range tests {
+		err := engine.CheckPromptArguments("github", tt.prompt, tt.args)
+		if (err == nil) != tt.allowed {
+			t.Errorf("CheckPromptArguments(%q, %v) = %v, want allowed=%v", tt.prompt, tt.args, err, tt.allowed)
+		}
+	}
+}
+
+func TestExplain_DenyMessages(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"safe": {
+				Servers: map[string]config.ServerProfileConfig{
+					"fs": {
+						Tools: config.ComponentFilter{
+							Allow:       []string{"read_*", "delete_*", "move_*"},
+							Deny:        []string{"delete_*", "move_*"},
+							DenyReasons: map[string]string{"delete_*": "Deletion tools are disabled in the safe profile"},
+							Message:     "Not available in the safe profile",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	engine := NewEngine(cfg, "safe")
+
+	tests := []struct {
+		tool    string
+		message string
+	}{
+		{"read_file", ""},
+		{"delete_file", "Deletion tools are disabled in the safe profile"},
+		{"move_file", "Not available in the safe profile"},
+		{"write_file", "Not available in the safe profile"},
+	}
+
+	for _, tt := range tests {
+		if d := engine.ExplainTool("fs", tt.tool); d.Message != tt.message {
+			t.Errorf("ExplainTool(%q).Message = %q, want %q", tt.tool, d.Message, tt.message)
+		}
+	}
+}
+
 func TestIsAllowed_ProfileNotFound(t *testing.T) {
 	cfg := &config.RootConfig{
 		Profiles: map[string]config.ProfileConfig{},
@@ -287,3 +362,295 @@ func TestMatchesAny(t *testing.T) {
 		})
 	}
 }
+
+func TestExplainTool(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {
+						Tools: config.ComponentFilter{
+							Allow: []string{"read_*", "list_directory"},
+							Deny:  []string{"read_secret"},
+						},
+					},
+					"server2": {},
+				},
+			},
+		},
+	}
+
+	engine := NewEngine(cfg, "test")
+
+	tests := []struct {
+		server  string
+		tool    string
+		allowed bool
+		reason  Reason
+		pattern string
+	}{
+		{"server1", "read_file", true, ReasonAllowed, "read_*"},
+		{"server1", "read_secret", false, ReasonDenied, "read_secret"},
+		{"server1", "write_file", false, ReasonNotAllowed, ""},
+		{"server2", "anything", true, ReasonAllowListEmpty, ""},
+		{"server3", "anything", false, ReasonServerNotInProfile, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.server+"/"+tt.tool, func(t *testing.T) {
+			d := engine.ExplainTool(tt.server, tt.tool)
+			if d.Allowed != tt.allowed || d.Reason != tt.reason || d.Pattern != tt.pattern {
+				t.Errorf("ExplainTool(%q, %q) = %+v, want allowed=%v reason=%q pattern=%q",
+					tt.server, tt.tool, d, tt.allowed, tt.reason, tt.pattern)
+			}
+		})
+	}
+
+	if d := NewEngine(cfg, "missing").ExplainTool("server1", "read_file"); d.Reason != ReasonProfileNotFound {
+		t.Errorf("Expected %q for missing profile, got %q", ReasonProfileNotFound, d.Reason)
+	}
+}
+
+func TestExplainToolCall_SafeMode(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {Tools: config.ComponentFilter{Deny: []string{"read_secret"}}},
+				},
+			},
+		},
+	}
+	engine := NewEngine(cfg, "test")
+
+	if d := engine.ExplainToolCall("server1", "write_file", false); !d.Allowed {
+		t.Errorf("without safe mode, ExplainToolCall(write_file) = %+v, want allowed", d)
+	}
+
+	cfg.Hub.SafeMode = true
+	tests := []struct {
+		tool     string
+		readOnly bool
+		allowed  bool
+		reason   Reason
+	}{
+		{"read_file", true, true, ReasonAllowListEmpty},
+		{"write_file", false, false, ReasonSafeMode},
+		// Safe mode only narrows the profile
+		{"read_secret", true, false, ReasonDenied},
+	}
+	for _, tt := range tests {
+		d := engine.ExplainToolCall("server1", tt.tool, tt.readOnly)
+		if d.Allowed != tt.allowed || d.Reason != tt.reason {
+			t.Errorf("ExplainToolCall(%q, readOnly=%v) = %+v, want allowed=%v reason=%q",
+				tt.tool, tt.readOnly, d, tt.allowed, tt.reason)
+		}
+	}
+}
+
+func TestExplainResourceMetadata(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {
+						Resources: config.ComponentFilter{Deny: []string{"file:///secret"}},
+						ResourceMetadata: config.ResourceMetadataFilter{
+							MaxSize:     1000,
+							Audience:    []string{"assistant"},
+							MinPriority: 0.5,
+						},
+					},
+					"server2": {},
+				},
+			},
+		},
+	}
+	engine := NewEngine(cfg, "test")
+
+	if !engine.HasResourceMetadataRules("server1") || engine.HasResourceMetadataRules("server2") {
+		t.Error("HasResourceMetadataRules: want server1 only")
+	}
+
+	tests := []struct {
+		name    string
+		uri     string
+		md      *ResourceMetadata
+		allowed bool
+		reason  Reason
+	}{
+		{"small", "file:///a", &ResourceMetadata{Size: 1000}, true, ReasonAllowListEmpty},
+		{"oversized", "file:///a", &ResourceMetadata{Size: 1001}, false, ReasonResourceMetadata},
+		{"undeclared size", "file:///a", &ResourceMetadata{}, true, ReasonAllowListEmpty},
+		{"user only", "file:///a", &ResourceMetadata{Audience: []string{"user"}}, false, ReasonResourceMetadata},
+		{"shared audience", "file:///a", &ResourceMetadata{Audience: []string{"user", "assistant"}}, true, ReasonAllowListEmpty},
+		{"low priority", "file:///a", &ResourceMetadata{Priority: 0.2}, false, ReasonResourceMetadata},
+		{"unknown metadata", "file:///a", nil, true, ReasonAllowListEmpty},
+		// Metadata only narrows the URI filter
+		{"denied by URI", "file:///secret", &ResourceMetadata{Size: 1}, false, ReasonDenied},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := engine.ExplainResourceMetadata("server1", tt.uri, tt.md)
+			if d.Allowed != tt.allowed || d.Reason != tt.reason {
+				t.Errorf("ExplainResourceMetadata(%q, %+v) = %+v, want allowed=%v reason=%q", tt.uri, tt.md, d, tt.allowed, tt.reason)
+			}
+			if !d.Allowed && d.Reason == ReasonResourceMetadata && d.Message == "" {
+				t.Error("metadata denial has no message")
+			}
+		})
+	}
+}
+
+func TestExplain_Passthrough(t *testing.T) {
+	cfg := &config.RootConfig{
+		Servers: map[string]config.ServerConfig{
+			"listed":   {},
+			"unlisted": {},
+		},
+		Profiles: map[string]config.ProfileConfig{
+			"dev": {
+				Passthrough: true,
+				Servers: map[string]config.ServerProfileConfig{
+					// Filters are ignored by passthrough profiles
+					"listed": {Tools: config.ComponentFilter{Deny: []string{"*"}}},
+				},
+			},
+		},
+	}
+	engine := NewEngine(cfg, "dev")
+
+	for _, server := range []string{"listed", "unlisted"} {
+		if !engine.HasServer(server) {
+			t.Errorf("HasServer(%q) = false, want true", server)
+		}
+		if d := engine.ExplainTool(server, "write_file"); !d.Allowed || d.Reason != ReasonPassthrough {
+			t.Errorf("ExplainTool(%q, write_file) = %+v, want allowed by passthrough", server, d)
+		}
+		if !engine.IsResourceAllowed(server, "file:///etc/passwd") || !engine.IsPromptAllowed(server, "any") {
+			t.Errorf("resources or prompts of %q denied, want allowed by passthrough", server)
+		}
+	}
+
+	if d := engine.ExplainTool("unknown", "read_file"); d.Allowed || d.Reason != ReasonServerNotInProfile {
+		t.Errorf("ExplainTool(unknown) = %+v, want %q", d, ReasonServerNotInProfile)
+	}
+}
+
+// TestPrecedence pins down how allow and deny lists combine: deny always
+// wins, an empty allow list allows everything not denied, and a non-empty
+// one allows only what it matches. Tools, resources, and prompts share the
+// same rules, so every case is checked against all three.
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter config.ComponentFilter
+		input  string
+		reason Reason
+	}{
+		// Overlapping wildcard allow and exact deny
+		{"deny beats overlapping allow", config.ComponentFilter{Allow: []string{"read_*"}, Deny: []string{"read_secret"}}, "read_secret", ReasonDenied},
+		{"allow glob matches", config.ComponentFilter{Allow: []string{"read_*"}, Deny: []string{"read_secret"}}, "read_file", ReasonAllowed},
+		{"allow glob needs its literal prefix", config.ComponentFilter{Allow: []string{"read_*"}, Deny: []string{"read_secret"}}, "readme", ReasonNotAllowed},
+
+		// Deny wins regardless of how specific either pattern is
+		{"wildcard deny beats exact allow", config.ComponentFilter{Allow: []string{"read_file"}, Deny: []string{"*"}}, "read_file", ReasonDenied},
+		{"same pattern in both lists", config.ComponentFilter{Allow: []string{"read_*"}, Deny: []string{"read_*"}}, "read_file", ReasonDenied},
+
+		// Empty allow list means allow all minus deny
+		{"empty allow allows the rest", config.ComponentFilter{Deny: []string{"delete_*"}}, "write_file", ReasonAllowListEmpty},
+		{"empty allow still denies", config.ComponentFilter{Deny: []string{"delete_*"}}, "delete_file", ReasonDenied},
+		{"no rules allow everything", config.ComponentFilter{}, "anything", ReasonAllowListEmpty},
+	}
+
+	components := []struct {
+		kind    string
+		set     func(*config.ServerProfileConfig, config.ComponentFilter)
+		explain func(*Engine, string) Decision
+	}{
+		{"tool", func(sp *config.ServerProfileConfig, f config.ComponentFilter) { sp.Tools = f }, func(e *Engine, name string) Decision { return e.ExplainTool("server1", name) }},
+		{"resource", func(sp *config.ServerProfileConfig, f config.ComponentFilter) { sp.Resources = f }, func(e *Engine, name string) Decision { return e.ExplainResource("server1", name) }},
+		{"prompt", func(sp *config.ServerProfileConfig, f config.ComponentFilter) { sp.Prompts = f }, func(e *Engine, name string) Decision { return e.ExplainPrompt("server1", name) }},
+	}
+
+	for _, c := range components {
+		for _, tt := range tests {
+			t.Run(c.kind+"/"+tt.name, func(t *testing.T) {
+				var sp config.ServerProfileConfig
+				c.set(&sp, tt.filter)
+				engine := NewEngine(&config.RootConfig{
+					Profiles: map[string]config.ProfileConfig{
+						"test": {Servers: map[string]config.ServerProfileConfig{"server1": sp}},
+					},
+				}, "test")
+
+				d := c.explain(engine, tt.input)
+				wantAllowed := tt.reason == ReasonAllowed || tt.reason == ReasonAllowListEmpty
+				if d.Allowed != wantAllowed || d.Reason != tt.reason {
+					t.Errorf("%s %q = allowed %v (%s), want allowed %v (%s)", c.kind, tt.input, d.Allowed, d.Reason, wantAllowed, tt.reason)
+				}
+			})
+		}
+	}
+}
+
+func TestMatchList_AgreesWithFirstMatch(t *testing.T) {
+	e := NewEngine(&config.RootConfig{}, "test")
+	lists := [][]string{
+		{"read_file", "write_file"},         // literal: looked up in a set
+		{"read_file", "write_*"},            // glob: matched in turn
+		{"read_file", "a?b", "list_[a-z]*"}, // glob characters without *
+	}
+	names := []string{"read_file", "write_file", "write_dir", "a?b", "axb", "list_dirs", "delete"}
+
+	for _, patterns := range lists {
+		for _, name := range names {
+			wantPattern, wantOK := firstMatch(name, patterns)
+			if pattern, ok := e.matchList(name, patterns); pattern != wantPattern || ok != wantOK {
+				t.Errorf("matchList(%q, %v) = %q, %v; want %q, %v", name, patterns, pattern, ok, wantPattern, wantOK)
+			}
+		}
+	}
+}
+
+func TestExplain_LiteralFilterReplaced(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{
+				"server1": {Tools: config.ComponentFilter{Allow: []string{"read_file"}}},
+			}},
+		},
+	}
+	e := NewEngine(cfg, "test")
+	if !e.IsToolAllowed("server1", "read_file") {
+		t.Fatal("read_file denied before the filter changed")
+	}
+
+	cfg.Profiles["test"].Servers["server1"] = config.ServerProfileConfig{
+		Tools: config.ComponentFilter{Allow: []string{"write_file"}},
+	}
+	if e.IsToolAllowed("server1", "read_file") || !e.IsToolAllowed("server1", "write_file") {
+		t.Error("decisions still follow the replaced allow list")
+	}
+}
+
+func BenchmarkMatchList_LiteralAllowList(b *testing.B) {
+	allow := make([]string, 100)
+	for i := range allow {
+		allow[i] = fmt.Sprintf("tool_%03d", i)
+	}
+	e := NewEngine(&config.RootConfig{}, "test")
+	// The last entry, and a name on no list: the worst cases for a scan
+	names := []string{"tool_099", "other_tool"}
+
+	b.Run("set", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			e.matchList(names[i%2], allow)
+		}
+	})
+	b.Run("scan", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			firstMatch(names[i%2], allow)
+		}
+	})
+}
@@ -0,0 +1,136 @@
+package profile
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+// Catalog is what each server offered before any filtering, keyed by server
+// ID, or by replica group ID for group members.
+type Catalog map[string]ServerCatalog
+
+// ServerCatalog is the unfiltered names one server offered.
+type ServerCatalog struct {
+	Tools     []string `json:"tools"`
+	Resources []string `json:"resources"` // URIs
+	Prompts   []string `json:"prompts"`
+}
+
+// names returns the catalog's names of component.
+func (c *ServerCatalog) names(component Component) []string {
+	switch component {
+	case ComponentTool:
+		return c.Tools
+	case ComponentResource:
+		return c.Resources
+	default:
+		return c.Prompts
+	}
+}
+
+// LintWarning is a filter pattern that doesn't do what it appears to
+// against a catalog.
+type LintWarning struct {
+	Profile   string    `json:"profile"`
+	Server    string    `json:"server"`
+	Component Component `json:"component,omitempty"`
+	// List is "allow" or "deny", or empty for warnings about the server
+	List    string `json:"list,omitempty"`
+	Pattern string `json:"pattern,omitempty"`
+	Problem string `json:"problem"`
+}
+
+func (w LintWarning) String() string {
+	if w.Pattern == "" {
+		return fmt.Sprintf("%s: server %s: %s", w.Profile, w.Server, w.Problem)
+	}
+	return fmt.Sprintf("%s: server %s: %s %s pattern %q %s", w.Profile, w.Server, w.Component, w.List, w.Pattern, w.Problem)
+}
+
+// Lint checks the allow and deny patterns of profileName against catalog,
+// flagging allow patterns that match nothing, which are likely typos or
+// filters left behind by upstream renames, and deny patterns that are
+// redundant: they match nothing, only names the allow list already
+// excludes, or only names earlier deny patterns already deny. Servers
+// missing from the catalog are flagged and not checked. Warnings are
+// sorted by server, then component in tool, resource, prompt order.
+func Lint(cfg *config.RootConfig, profileName string, catalog Catalog) []LintWarning {
+	profile, ok := cfg.Profiles[profileName]
+	if !ok || profile.Passthrough {
+		return nil
+	}
+
+	serverIDs := make([]string, 0, len(profile.Servers))
+	for serverID := range profile.Servers {
+		serverIDs = append(serverIDs, serverID)
+	}
+	sort.Strings(serverIDs)
+
+	var warnings []LintWarning
+	for _, serverID := range serverIDs {
+		serverCatalog, ok := catalog[serverID]
+		if !ok {
+			warnings = append(warnings, LintWarning{
+				Profile: profileName,
+				Server:  serverID,
+				Problem: "is not in the catalog, so its filters were not checked",
+			})
+			continue
+		}
+
+		serverProfile := profile.Servers[serverID]
+		filters := []struct {
+			component Component
+			filter    *config.ComponentFilter
+		}{
+			{ComponentTool, &serverProfile.Tools},
+			{ComponentResource, &serverProfile.Resources},
+			{ComponentPrompt, &serverProfile.Prompts},
+		}
+		for _, f := range filters {
+			for _, w := range lintFilter(f.filter, serverCatalog.names(f.component)) {
+				w.Profile, w.Server, w.Component = profileName, serverID, f.component
+				warnings = append(warnings, w)
+			}
+		}
+	}
+	return warnings
+}
+
+// lintFilter checks filter's patterns against names, returning warnings
+// with only List, Pattern, and Problem set.
+func lintFilter(filter *config.ComponentFilter, names []string) []LintWarning {
+	var warnings []LintWarning
+	for _, pattern := range filter.Allow {
+		if len(matching(names, pattern)) == 0 {
+			warnings = append(warnings, LintWarning{List: "allow", Pattern: pattern, Problem: "matches nothing"})
+		}
+	}
+
+	for i, pattern := range filter.Deny {
+		matched := matching(names, pattern)
+		switch {
+		case len(matched) == 0:
+			warnings = append(warnings, LintWarning{List: "deny", Pattern: pattern, Problem: "matches nothing"})
+		case len(filter.Allow) > 0 && !slices.ContainsFunc(matched, func(name string) bool { return matchesAny(name, filter.Allow) }):
+			warnings = append(warnings, LintWarning{List: "deny", Pattern: pattern, Problem: "only matches names the allow list already excludes"})
+		case !slices.ContainsFunc(matched, func(name string) bool { return !matchesAny(name, filter.Deny[:i]) }):
+			warnings = append(warnings, LintWarning{List: "deny", Pattern: pattern, Problem: "only matches names earlier deny patterns already deny"})
+		}
+	}
+	return warnings
+}
+
+// matching returns the names that match pattern.
+func matching(names []string, pattern string) []string {
+	var matched []string
+	for _, name := range names {
+		if matchPattern(name, pattern) {
+			matched = append(matched, name)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,80 @@
+package profile
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+func TestLint(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"dev": {
+				Servers: map[string]config.ServerProfileConfig{
+					"github": {
+						Tools: config.ComponentFilter{
+							Allow: []string{"create_issue", "crate_pr", "list_*"},
+							Deny:  []string{"delete_repo", "list_secrets", "list_*_keys", "list_secrets*"},
+						},
+						Prompts: config.ComponentFilter{Deny: []string{"*"}},
+					},
+					"files": {
+						Resources: config.ComponentFilter{Allow: []string{"file:///docs/*"}},
+					},
+					"search": {},
+				},
+			},
+			"all": {Passthrough: true},
+		},
+	}
+	catalog := Catalog{
+		"github": {
+			Tools:   []string{"create_issue", "create_pr", "delete_repo", "list_issues", "list_secrets", "list_deploy_keys"},
+			Prompts: []string{"triage"},
+		},
+		"files": {
+			Resources: []string{"file:///docs/readme.md"},
+		},
+	}
+
+	var got []string
+	for _, w := range Lint(cfg, "dev", catalog) {
+		got = append(got, w.String())
+	}
+	want := []string{
+		`dev: server github: tool allow pattern "crate_pr" matches nothing`,
+		`dev: server github: tool deny pattern "delete_repo" only matches names the allow list already excludes`,
+		`dev: server github: tool deny pattern "list_secrets*" only matches names earlier deny patterns already deny`,
+		`dev: server search: is not in the catalog, so its filters were not checked`,
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Lint() =\n%q\nwant\n%q", got, want)
+	}
+
+	if warnings := Lint(cfg, "all", catalog); len(warnings) != 0 {
+		t.Errorf("Lint() of a passthrough profile = %v, want no warnings", warnings)
+	}
+}
+
+func TestLint_DenyMatchesNothing(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"dev": {
+				Servers: map[string]config.ServerProfileConfig{
+					"github": {Tools: config.ComponentFilter{Deny: []string{"delete_repository"}}},
+				},
+			},
+		},
+	}
+	catalog := Catalog{"github": {Tools: []string{"delete_repo"}}}
+
+	warnings := Lint(cfg, "dev", catalog)
+	if len(warnings) != 1 {
+		t.Fatalf("Lint() = %v, want one warning", warnings)
+	}
+	want := LintWarning{Profile: "dev", Server: "github", Component: ComponentTool, List: "deny", Pattern: "delete_repository", Problem: "matches nothing"}
+	if warnings[0] != want {
+		t.Errorf("Lint() = %+v, want %+v", warnings[0], want)
+	}
+}
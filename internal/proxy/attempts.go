@@ -0,0 +1,37 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AttemptsMetaKey is the _meta key of a failed tool call result listing each
+// upstream the hub tried and how it failed.
+const AttemptsMetaKey = "mcp2/attempts"
+
+// CallAttempt is one upstream's failure in a tool call the hub routed to
+// several upstreams.
+type CallAttempt struct {
+	Server string `json:"server"`
+	Error  string `json:"error"`
+}
+
+// failedAttemptsResult reports a tool call that failed on every upstream the
+// hub tried. It is a tool error result rather than a protocol error so the
+// per-server breakdown can travel in _meta alongside a readable summary.
+func failedAttemptsResult(toolName string, attempts []CallAttempt) *mcp.CallToolResult {
+	lines := make([]string, 0, len(attempts))
+	for _, a := range attempts {
+		lines = append(lines, fmt.Sprintf("  %s: %s", a.Server, a.Error))
+	}
+	text := fmt.Sprintf("tool %q allowed by profile but call failed on %d server(s):\n%s",
+		toolName, len(attempts), strings.Join(lines, "\n"))
+
+	return &mcp.CallToolResult{
+		Meta:    mcp.Meta{AttemptsMetaKey: attempts},
+		IsError: true,
+		Content: []mcp.Content{&mcp.TextContent{Text: text}},
+	}
+}
@@ -0,0 +1,53 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_ReportsPerServerFailures(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"alpha": {}, "beta": {},
+				},
+			},
+		},
+	}
+
+	alpha := newTestUpstream(t, "alpha", "search")
+	beta := newTestUpstream(t, "beta", "search")
+	manager := newTestManager(t, alpha, beta)
+	hub := NewHub(cfg, manager, "test")
+
+	// Both upstreams go away, so every attempt fails
+	alpha.Session().Close()
+	beta.Session().Close()
+
+	result, err := connectTestClient(t, hub.Server()).CallTool(context.Background(), &mcp.CallToolParams{Name: "search"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Fatal("Expected an error result")
+	}
+
+	data, _ := json.Marshal(result.Meta[AttemptsMetaKey])
+	var attempts []CallAttempt
+	if err := json.Unmarshal(data, &attempts); err != nil {
+		t.Fatalf("Failed to decode attempts %s: %v", data, err)
+	}
+	if len(attempts) != 2 || attempts[0].Server != "alpha" || attempts[1].Server != "beta" {
+		t.Fatalf("Attempts = %+v, want alpha then beta", attempts)
+	}
+	for _, a := range attempts {
+		if a.Error == "" {
+			t.Errorf("Attempt on %s has no error", a.Server)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package proxy
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/requestid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// AuditEventType identifies what an AuditEvent records.
+type AuditEventType string
+
+const (
+	// AuditDecision records a call-phase allow/deny decision.
+	AuditDecision AuditEventType = "decision"
+	// AuditToolCall records the outcome of a tool call forwarded upstream.
+	AuditToolCall AuditEventType = "toolCall"
+)
+
+// AuditEvent is a structured record of a hub decision or tool call outcome.
+type AuditEvent struct {
+	Time      time.Time      `json:"time"`
+	Type      AuditEventType `json:"type"`
+	RequestID string         `json:"requestId,omitempty"`
+	Profile   string         `json:"profile"`
+	Server    string         `json:"server"`
+	// Component is "tool", "resource", or "prompt".
+	Component string `json:"component"`
+	// Name is the tool or prompt name, or the resource URI, as known to the
+	// upstream (without the server prefix).
+	Name string `json:"name"`
+
+	// Allowed is the decision for AuditDecision events.
+	Allowed bool `json:"allowed,omitempty"`
+
+	// Duration and Error describe AuditToolCall events. Error is set when
+	// the call failed or the tool reported an error result.
+	Duration time.Duration `json:"duration,omitempty"`
+	Error    string        `json:"error,omitempty"`
+}
+
+// AuditBackpressure controls what happens when the audit channel is full.
+type AuditBackpressure int
+
+const (
+	// AuditDrop drops events when the channel is full. Dropped events are
+	// counted and reported by AuditDropped.
+	AuditDrop AuditBackpressure = iota
+	// AuditBlock waits for room in the channel, delaying the request until
+	// the consumer catches up or the request is cancelled.
+	AuditBlock
+)
+
+// auditSink delivers audit events to a consumer channel.
+type auditSink struct {
+	events       chan<- AuditEvent
+	backpressure AuditBackpressure
+	dropped      atomic.Uint64
+}
+
+// SetAuditSink sends an AuditEvent to events for every call-phase allow/deny
+// decision and every tool call outcome. List filtering does not emit events.
+// backpressure selects whether events are dropped or requests block when
+// events is full. It must be called before the hub serves requests.
+func (h *Hub) SetAuditSink(events chan<- AuditEvent, backpressure AuditBackpressure) {
+	h.audit = &auditSink{events: events, backpressure: backpressure}
+}
+
+// AuditDropped returns the number of audit events dropped because the
+// audit channel was full.
+func (h *Hub) AuditDropped() uint64 {
+	if h.audit == nil {
+		return 0
+	}
+	return h.audit.dropped.Load()
+}
+
+// emitAudit fills in the common fields of ev and delivers it to the audit
+// sink, if any.
+func (h *Hub) emitAudit(ctx context.Context, ev AuditEvent) {
+	if h.audit == nil {
+		return
+	}
+
+	ev.Time = time.Now()
+	ev.RequestID = requestid.From(ctx)
+	ev.Profile = h.view(ctx).name
+
+	if h.audit.backpressure == AuditBlock {
+		select {
+		case h.audit.events <- ev:
+		case <-ctx.Done():
+			h.audit.dropped.Add(1)
+		}
+		return
+	}
+
+	select {
+	case h.audit.events <- ev:
+	default:
+		h.audit.dropped.Add(1)
+	}
+}
+
+// auditDecision records a call-phase decision and returns it.
+func (h *Hub) auditDecision(ctx context.Context, component, serverID, name string, allowed bool) bool {
+	h.emitAudit(ctx, AuditEvent{
+		Type:      AuditDecision,
+		Server:    serverID,
+		Component: component,
+		Name:      name,
+		Allowed:   allowed,
+	})
+	return allowed
+}
+
+// auditToolCall records the outcome of a tool call started at start.
+func (h *Hub) auditToolCall(ctx context.Context, serverID, name string, start time.Time, result *mcp.CallToolResult, err error) {
+	ev := AuditEvent{
+		Type:      AuditToolCall,
+		Server:    serverID,
+		Component: "tool",
+		Name:      name,
+		Duration:  time.Since(start),
+	}
+	if err != nil {
+		ev.Error = scrub(ctx, err.Error())
+	} else if result != nil && result.IsError {
+		ev.Error = "tool returned an error result"
+	}
+	h.emitAudit(ctx, ev)
+}
@@ -0,0 +1,89 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_EmitsAuditEvents(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {
+						Tools: config.ComponentFilter{Deny: []string{"write_file"}},
+					},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file", "write_file"))
+	hub := NewHub(cfg, manager, "test")
+
+	events := make(chan AuditEvent, 10)
+	hub.SetAuditSink(events, AuditDrop)
+
+	session := connectTestClient(t, hub.Server())
+	ctx := context.Background()
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1:read_file"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1:write_file"}); err == nil {
+		t.Fatal("Expected write_file to be denied")
+	}
+	close(events)
+
+	var got []AuditEvent
+	for ev := range events {
+		got = append(got, ev)
+	}
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 audit events, got %d: %+v", len(got), got)
+	}
+
+	if got[0].Type != AuditDecision || !got[0].Allowed || got[0].Name != "read_file" {
+		t.Errorf("Expected allow decision for read_file, got %+v", got[0])
+	}
+	if got[1].Type != AuditToolCall || got[1].Error != "" || got[1].Server != "server1" {
+		t.Errorf("Expected successful tool call event, got %+v", got[1])
+	}
+	if got[2].Type != AuditDecision || got[2].Allowed || got[2].Name != "write_file" {
+		t.Errorf("Expected deny decision for write_file, got %+v", got[2])
+	}
+	for _, ev := range got {
+		if ev.Profile != "test" || ev.RequestID == "" {
+			t.Errorf("Expected profile and request ID on event, got %+v", ev)
+		}
+	}
+}
+
+func TestHub_AuditDropsWhenFull(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file"))
+	hub := NewHub(cfg, manager, "test")
+	hub.SetAuditSink(make(chan AuditEvent), AuditDrop)
+
+	session := connectTestClient(t, hub.Server())
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "server1:read_file"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	if hub.AuditDropped() != 2 {
+		t.Errorf("AuditDropped() = %d, want 2", hub.AuditDropped())
+	}
+}
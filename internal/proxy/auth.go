@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+// RequireToken wraps next so requests must carry a bearer token from tokens
+// that is scoped to scope ("hub" or a server ID). Requests without a known
+// token get 401; known tokens not scoped to this endpoint get 403. With no
+// tokens configured, next is returned unchanged.
+func RequireToken(tokens []config.AuthToken, scope string, next http.Handler) http.Handler {
+	if len(tokens) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Auth schemes are case-insensitive (RFC 7235 section 2.1)
+		scheme, presented, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+		if !strings.EqualFold(scheme, "Bearer") || presented == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "missing bearer token", http.StatusUnauthorized)
+			return
+		}
+
+		token := findToken(tokens, presented)
+		if token == nil {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "invalid bearer token", http.StatusUnauthorized)
+			return
+		}
+		if !slices.Contains(token.Scopes, scope) && !slices.Contains(token.Scopes, config.AuthScopeAll) {
+			http.Error(w, "token is not authorized for this endpoint", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// findToken returns the configured token matching presented, comparing in
+// constant time.
+func findToken(tokens []config.AuthToken, presented string) *config.AuthToken {
+	var found *config.AuthToken
+	for i := range tokens {
+		if subtle.ConstantTimeCompare([]byte(tokens[i].Token), []byte(presented)) == 1 {
+			found = &tokens[i]
+		}
+	}
+	return found
+}
@@ -0,0 +1,57 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+func TestRequireToken(t *testing.T) {
+	tokens := []config.AuthToken{
+		{Name: "docs-only", Token: "docs-token", Scopes: []string{"docs"}},
+		{Name: "admin", Token: "admin-token", Scopes: []string{"*"}},
+	}
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	tests := []struct {
+		name   string
+		scope  string
+		header string
+		want   int
+	}{
+		{"scoped token on its endpoint", "docs", "Bearer docs-token", http.StatusOK},
+		{"scoped token on another endpoint", "filesystem", "Bearer docs-token", http.StatusForbidden},
+		{"scoped token on hub", config.AuthScopeHub, "Bearer docs-token", http.StatusForbidden},
+		{"wildcard token", "filesystem", "Bearer admin-token", http.StatusOK},
+		{"unknown token", "docs", "Bearer nope", http.StatusUnauthorized},
+		{"lowercase scheme", "docs", "bearer docs-token", http.StatusOK},
+		{"uppercase scheme", "docs", "BEARER docs-token", http.StatusOK},
+		{"other scheme", "docs", "Basic docs-token", http.StatusUnauthorized},
+		{"missing token", "docs", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/mcp", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			RequireToken(tokens, tt.scope, ok).ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequireToken_NoTokensConfigured(t *testing.T) {
+	rec := httptest.NewRecorder()
+	RequireToken(nil, "docs", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})).
+		ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/mcp/docs", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// postJSONRPC posts a JSON-RPC payload to an MCP endpoint using the
+// 2025-03-26 protocol version, the last one that allows batching.
+func postJSONRPC(t *testing.T, url, sessionID, payload string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Mcp-Protocol-Version", "2025-03-26")
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	return resp
+}
+
+func TestHub_BatchRequestsFilteredIndividually(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {
+						Tools: config.ComponentFilter{Deny: []string{"write_file"}},
+					},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file", "write_file"))
+	hub := NewHub(cfg, manager, "test")
+
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return hub.Server()
+	}, &mcp.StreamableHTTPOptions{JSONResponse: true})
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	resp := postJSONRPC(t, srv.URL, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"batch-client","version":"1.0.0"}}}`)
+	resp.Body.Close()
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("Initialize failed: status %d, session %q", resp.StatusCode, sessionID)
+	}
+
+	resp = postJSONRPC(t, srv.URL, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized","params":{}}`)
+	resp.Body.Close()
+
+	resp = postJSONRPC(t, srv.URL, sessionID, `[
+		{"jsonrpc":"2.0","id":"allowed","method":"tools/call","params":{"name":"server1:read_file","arguments":{}}},
+		{"jsonrpc":"2.0","id":"denied","method":"tools/call","params":{"name":"server1:write_file","arguments":{}}}
+	]`)
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Batch failed: status %d: %s", resp.StatusCode, body)
+	}
+
+	var responses []struct {
+		ID     string          `json:"id"`
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &responses); err != nil {
+		t.Fatalf("Expected a batch response array, got %s: %v", body, err)
+	}
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d: %s", len(responses), body)
+	}
+
+	for _, r := range responses {
+		switch r.ID {
+		case "allowed":
+			if r.Error != nil || !bytes.Contains(r.Result, []byte("server1:read_file")) {
+				t.Errorf("Expected result for allowed call, got %s", body)
+			}
+		case "denied":
+			if r.Error == nil {
+				t.Errorf("Expected error for denied call, got %s", body)
+			}
+		default:
+			t.Errorf("Unexpected response ID %q", r.ID)
+		}
+	}
+}
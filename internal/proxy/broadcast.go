@@ -0,0 +1,202 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// BroadcastMetaKey is the _meta key that asks the hub to fan a tools/call or
+// resources/read out to every profile-allowed server. Its value is
+// BroadcastAll or BroadcastFirst.
+const BroadcastMetaKey = "mcp2/broadcast"
+
+const (
+	// BroadcastAll combines the results from every server.
+	BroadcastAll = "all"
+	// BroadcastFirst returns the first successful result in server ID order.
+	BroadcastFirst = "first"
+)
+
+// broadcastMode returns the broadcast mode requested in meta, or "" if the
+// request is not a broadcast.
+func broadcastMode(meta mcp.Meta) string {
+	switch v := meta[BroadcastMetaKey].(type) {
+	case bool:
+		if v {
+			return BroadcastAll
+		}
+	case string:
+		if v == BroadcastAll || v == BroadcastFirst {
+			return v
+		}
+	}
+	return ""
+}
+
+// broadcastName strips the "*" wildcard server prefix, if present.
+func (v *profileView) broadcastName(name string) string {
+	return strings.TrimPrefix(name, v.prefixName("*", ""))
+}
+
+// broadcastResult is one server's outcome in a broadcast.
+type broadcastResult[T any] struct {
+	serverID string
+	result   T
+	err      error
+}
+
+// broadcast calls fn concurrently for each upstream and returns the outcomes
+// in server ID order.
+func broadcast[T any](upstreams []*upstream.Upstream, fn func(u *upstream.Upstream) (T, error)) []broadcastResult[T] {
+	sort.Slice(upstreams, func(i, j int) bool {
+		return upstreams[i].ID < upstreams[j].ID
+	})
+
+	results := make([]broadcastResult[T], len(upstreams))
+	var wg sync.WaitGroup
+	for i, u := range upstreams {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := fn(u)
+			results[i] = broadcastResult[T]{serverID: u.ID, result: result, err: err}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// exposesTool reports whether the upstream lists a tool with the given name.
+func exposesTool(ctx context.Context, u *upstream.Upstream, name string) bool {
+	_, err := u.Tool(ctx, name)
+	return err == nil
+}
+
+// broadcastToolCall calls a tool on every profile-allowed server that exposes
+// it. In BroadcastAll mode the content from each server is combined in server
+// ID order, each preceded by a "[server]" header; servers whose call failed
+// contribute an error line, and the result is an error only if every call
+// failed. In BroadcastFirst mode the first success in server ID order is
+// returned.
+func (h *Hub) broadcastToolCall(ctx context.Context, mode string, callReq *mcp.CallToolRequest, toolName string) (mcp.Result, error) {
+	v := h.view(ctx)
+	var targets []*upstream.Upstream
+	for _, u := range h.logicalUpstreams(ctx) {
+		if !h.auditDecision(ctx, "tool", u.ID, toolName, explainToolCall(ctx, v.engine, u, toolName).Allowed) {
+			continue
+		}
+		if exposesTool(ctx, u, toolName) {
+			targets = append(targets, u)
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("tool %q not found in any upstream or not allowed by profile", toolName)
+	}
+
+	results := broadcast(targets, func(u *upstream.Upstream) (*mcp.CallToolResult, error) {
+		params, done := h.toolCallParams(ctx, callReq, u.ID, toolName)
+		defer done()
+		start := time.Now()
+		result, err := u.Session().CallTool(ctx, params)
+		h.auditToolCall(ctx, u.ID, toolName, start, result, err)
+		return result, err
+	})
+
+	if mode == BroadcastFirst {
+		var errs []error
+		for _, r := range results {
+			if r.err == nil && !r.result.IsError {
+				setRequestServer(ctx, r.serverID)
+				return r.result, nil
+			}
+			if r.err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", r.serverID, r.err))
+			} else {
+				errs = append(errs, fmt.Errorf("%s: tool returned an error result", r.serverID))
+			}
+		}
+		return nil, fmt.Errorf("tool %q failed on every server: %w", toolName, errors.Join(errs...))
+	}
+
+	combined := &mcp.CallToolResult{IsError: true}
+	for _, r := range results {
+		if r.err != nil {
+			combined.Content = append(combined.Content, &mcp.TextContent{
+				Text: fmt.Sprintf("[%s] error: %s", r.serverID, scrub(ctx, r.err.Error())),
+			})
+			continue
+		}
+		if !r.result.IsError {
+			combined.IsError = false
+		}
+		combined.Content = append(combined.Content, &mcp.TextContent{Text: fmt.Sprintf("[%s]", r.serverID)})
+		combined.Content = append(combined.Content, r.result.Content...)
+	}
+	return combined, nil
+}
+
+// broadcastResourceRead reads a resource from every profile-allowed server.
+// In BroadcastAll mode the contents from every successful read are combined
+// in server ID order, with URIs prefixed by the server ID when prefixing is
+// enabled; it fails only if every read failed. In BroadcastFirst mode the
+// first success in server ID order is returned.
+func (h *Hub) broadcastResourceRead(ctx context.Context, mode string, readReq *mcp.ReadResourceRequest, uri string) (mcp.Result, error) {
+	v := h.view(ctx)
+	var targets []*upstream.Upstream
+	var checkErrs []error
+	for _, u := range h.logicalUpstreams(ctx) {
+		decision, err := explainResourceRead(ctx, v.engine, u, uri)
+		if err != nil {
+			checkErrs = append(checkErrs, fmt.Errorf("%s: %w", u.ID, err))
+		}
+		if h.auditDecision(ctx, "resource", u.ID, uri, err == nil && decision.Allowed) {
+			targets = append(targets, u)
+		}
+	}
+	if len(targets) == 0 {
+		if len(checkErrs) > 0 {
+			return nil, fmt.Errorf("resource %q could not be checked against the profile: %w", uri, errors.Join(checkErrs...))
+		}
+		return nil, fmt.Errorf("resource %q not allowed by profile on any upstream", uri)
+	}
+
+	results := broadcast(targets, func(u *upstream.Upstream) (*mcp.ReadResourceResult, error) {
+		params, done := h.readResourceParams(ctx, readReq, u.ID, uri)
+		defer done()
+		return u.Session().ReadResource(ctx, params)
+	})
+
+	combined := &mcp.ReadResourceResult{}
+	var errs []error
+	for _, r := range results {
+		if r.err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.serverID, r.err))
+			continue
+		}
+		if mode == BroadcastFirst {
+			setRequestServer(ctx, r.serverID)
+			return r.result, nil
+		}
+		for _, content := range r.result.Contents {
+			if v.prefix.Resources {
+				content.URI = v.prefixName(r.serverID, content.URI)
+			}
+			combined.Contents = append(combined.Contents, content)
+		}
+	}
+	if len(errs) == len(results) {
+		return nil, fmt.Errorf("resource %q read failed on every server: %w", uri, errors.Join(errs...))
+	}
+	if len(errs) > 0 {
+		h.logger().Warn("broadcast read partially failed", "uri", uri, "error", errors.Join(errs...))
+	}
+	return combined, nil
+}
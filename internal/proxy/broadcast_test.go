@@ -0,0 +1,97 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func newBroadcastTestHub(t *testing.T) *Hub {
+	t.Helper()
+
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"a": {},
+					"b": {},
+					"c": {},
+				},
+			},
+		},
+	}
+
+	manager := newTestManager(t,
+		newTestUpstream(t, "b", "search"),
+		newTestUpstream(t, "a", "search"),
+		newTestUpstream(t, "c", "other"),
+	)
+	return NewHub(cfg, manager, "test")
+}
+
+func textContents(result *mcp.CallToolResult) []string {
+	var texts []string
+	for _, c := range result.Content {
+		if tc, ok := c.(*mcp.TextContent); ok {
+			texts = append(texts, tc.Text)
+		}
+	}
+	return texts
+}
+
+func TestHub_BroadcastToolCallAll(t *testing.T) {
+	session := connectTestClient(t, newBroadcastTestHub(t).Server())
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta: mcp.Meta{BroadcastMetaKey: BroadcastAll},
+		Name: "search",
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Error("Expected combined result not to be an error")
+	}
+
+	got := textContents(result)
+	want := []string{"[a]", "a:search", "[b]", "b:search"}
+	if len(got) != len(want) {
+		t.Fatalf("Content = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Content[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestHub_BroadcastToolCallFirst(t *testing.T) {
+	session := connectTestClient(t, newBroadcastTestHub(t).Server())
+
+	result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta: mcp.Meta{BroadcastMetaKey: BroadcastFirst},
+		Name: "*:search",
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	got := textContents(result)
+	if len(got) != 1 || got[0] != "a:search" {
+		t.Errorf("Content = %v, want [a:search]", got)
+	}
+}
+
+func TestHub_BroadcastToolCallNotFound(t *testing.T) {
+	session := connectTestClient(t, newBroadcastTestHub(t).Server())
+
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+		Meta: mcp.Meta{BroadcastMetaKey: true},
+		Name: "missing",
+	})
+	if err == nil {
+		t.Error("Expected error for tool exposed by no server")
+	}
+}
@@ -0,0 +1,86 @@
+package proxy
+
+import "github.com/modelcontextprotocol/go-sdk/mcp"
+
+// The list handlers rewrite names, titles and URIs, so they work on deep
+// copies of what upstreams list: nothing the hub changes in one listing is
+// shared with another, with the upstream's tool cache, or with a listing
+// for a different profile.
+
+// cloneTool returns a deep copy of tool.
+func cloneTool(tool *mcp.Tool) *mcp.Tool {
+	t := *tool
+	t.Meta = cloneMeta(tool.Meta)
+	if tool.Annotations != nil {
+		a := *tool.Annotations
+		a.DestructiveHint = cloneBool(a.DestructiveHint)
+		a.OpenWorldHint = cloneBool(a.OpenWorldHint)
+		t.Annotations = &a
+	}
+	t.InputSchema = cloneJSON(tool.InputSchema)
+	t.OutputSchema = cloneJSON(tool.OutputSchema)
+	return &t
+}
+
+// clonePrompt returns a deep copy of prompt, including its arguments.
+func clonePrompt(prompt *mcp.Prompt) *mcp.Prompt {
+	p := *prompt
+	p.Meta = cloneMeta(prompt.Meta)
+	if prompt.Arguments != nil {
+		p.Arguments = make([]*mcp.PromptArgument, len(prompt.Arguments))
+		for i, arg := range prompt.Arguments {
+			if arg != nil {
+				a := *arg
+				p.Arguments[i] = &a
+			}
+		}
+	}
+	return &p
+}
+
+// cloneResource returns a deep copy of resource.
+func cloneResource(resource *mcp.Resource) *mcp.Resource {
+	r := *resource
+	r.Meta = cloneMeta(resource.Meta)
+	if resource.Annotations != nil {
+		a := *resource.Annotations
+		a.Audience = append([]mcp.Role(nil), a.Audience...)
+		r.Annotations = &a
+	}
+	return &r
+}
+
+func cloneMeta(meta mcp.Meta) mcp.Meta {
+	if meta == nil {
+		return nil
+	}
+	return mcp.Meta(cloneJSON(map[string]any(meta)).(map[string]any))
+}
+
+func cloneBool(b *bool) *bool {
+	if b == nil {
+		return nil
+	}
+	v := *b
+	return &v
+}
+
+// cloneJSON deep-copies a value decoded from JSON: maps and slices are
+// copied recursively, and anything else is returned as is.
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, elem := range v {
+			m[k] = cloneJSON(elem)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, elem := range v {
+			s[i] = cloneJSON(elem)
+		}
+		return s
+	}
+	return v
+}
@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_PromptArgumentsSurviveAggregation(t *testing.T) {
+	arguments := []*mcp.PromptArgument{
+		{Name: "path", Title: "File path", Description: "The file to review", Required: true},
+		{Name: "style", Description: "Review style"},
+	}
+
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "server1", Version: "1.0.0"}, nil)
+	server.AddPrompt(&mcp.Prompt{Name: "review", Description: "Review a file", Arguments: arguments},
+		func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return &mcp.GetPromptResult{}, nil
+		})
+	upstreamSession := connectTestClient(t, server)
+
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{"server1": {}}},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+	manager := newTestManager(t, upstream.NewUpstream("server1", upstreamSession))
+	session := connectTestClient(t, NewHub(cfg, manager, "test").Server())
+
+	result, err := session.ListPrompts(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListPrompts failed: %v", err)
+	}
+	if len(result.Prompts) != 1 {
+		t.Fatalf("Expected 1 prompt, got %d", len(result.Prompts))
+	}
+	prompt := result.Prompts[0]
+	if prompt.Name != "server1:review" || prompt.Description != "Review a file" {
+		t.Errorf("Prompt = %q (%q), want server1:review (Review a file)", prompt.Name, prompt.Description)
+	}
+	if !reflect.DeepEqual(prompt.Arguments, arguments) {
+		t.Errorf("Prompt arguments = %+v, want %+v", prompt.Arguments, arguments)
+	}
+}
+
+func TestClone_DoesNotShare(t *testing.T) {
+	destructive := true
+	tool := &mcp.Tool{
+		Name:        "write",
+		Meta:        mcp.Meta{"k": "v"},
+		Annotations: &mcp.ToolAnnotations{Title: "Write", DestructiveHint: &destructive},
+		InputSchema: map[string]any{"type": "object", "required": []any{"path"}},
+	}
+	toolCopy := cloneTool(tool)
+	toolCopy.Meta["k"] = "changed"
+	toolCopy.Annotations.Title = "changed"
+	*toolCopy.Annotations.DestructiveHint = false
+	toolCopy.InputSchema.(map[string]any)["required"].([]any)[0] = "changed"
+	if tool.Meta["k"] != "v" || tool.Annotations.Title != "Write" || !*tool.Annotations.DestructiveHint ||
+		tool.InputSchema.(map[string]any)["required"].([]any)[0] != "path" {
+		t.Errorf("Changing a cloned tool changed the original: %+v", tool)
+	}
+
+	prompt := &mcp.Prompt{Name: "review", Arguments: []*mcp.PromptArgument{{Name: "path", Required: true}}}
+	promptCopy := clonePrompt(prompt)
+	promptCopy.Arguments[0].Description = "changed"
+	promptCopy.Arguments = append(promptCopy.Arguments[:0], &mcp.PromptArgument{Name: "other"})
+	if prompt.Arguments[0].Name != "path" || prompt.Arguments[0].Description != "" {
+		t.Errorf("Changing a cloned prompt changed the original's arguments: %+v", prompt.Arguments[0])
+	}
+
+	resource := &mcp.Resource{URI: "file:///a", Annotations: &mcp.Annotations{Audience: []mcp.Role{"user"}}}
+	resourceCopy := cloneResource(resource)
+	resourceCopy.Annotations.Audience[0] = "assistant"
+	if resource.Annotations.Audience[0] != "user" {
+		t.Errorf("Changing a cloned resource changed the original's annotations")
+	}
+}
@@ -0,0 +1,179 @@
+package proxy
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// defaultCompressMinBytes is the smallest response Compress encodes when no
+// minimum is configured.
+const defaultCompressMinBytes = 1024
+
+// Compress wraps next so responses of at least minBytes (default 1024) are
+// gzip- or deflate-encoded for clients whose Accept-Encoding allows it.
+//
+// A response is held back until it reaches minBytes or next flushes it, and
+// the encoding is chosen then. JSON responses are written at once, so the
+// whole response is weighed. Event streams are flushed after every event,
+// so a stream is compressed only if its first event is large enough, such
+// as a list result; the standalone stream, which flushes its headers before
+// any event, is never compressed. Once compressed, every flush from next
+// flushes the encoder too, so events still reach the client one by one.
+func Compress(minBytes int, next http.Handler) http.Handler {
+	if minBytes <= 0 {
+		minBytes = defaultCompressMinBytes
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := acceptedEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		cw := &compressWriter{ResponseWriter: w, encoding: encoding, minBytes: minBytes}
+		defer cw.close()
+		next.ServeHTTP(cw, r)
+	})
+}
+
+// acceptedEncoding returns the encoding to use for a client sending
+// Accept-Encoding header, preferring gzip, or "" if it accepts neither.
+func acceptedEncoding(header string) string {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if v, err := strconv.ParseFloat(q, 64); err == nil && v == 0 {
+				continue
+			}
+		}
+		accepted[coding] = true
+	}
+
+	switch {
+	case accepted["gzip"]:
+		return "gzip"
+	case accepted["deflate"]:
+		return "deflate"
+	}
+	return ""
+}
+
+// flushWriteCloser is an encoder that can emit what it has buffered.
+type flushWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter buffers a response until it can tell whether to compress
+// it, then writes it either through an encoder or as is.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding string
+	minBytes int
+
+	status  int
+	buf     []byte
+	decided bool
+	enc     flushWriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.decided {
+		cw.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if cw.status == 0 {
+		cw.status = status
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < cw.minBytes {
+			return len(p), nil
+		}
+		return len(p), cw.decide()
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.ResponseWriter.Write(p)
+}
+
+// Flush decides on the encoding if it hasn't been, then flushes the
+// encoder and the underlying writer.
+func (cw *compressWriter) Flush() {
+	if err := cw.decide(); err != nil {
+		return
+	}
+	if cw.enc != nil {
+		if err := cw.enc.Flush(); err != nil {
+			return
+		}
+	}
+	if f, ok := cw.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// decide chooses whether to compress from what has been buffered, writes
+// the header, and writes out the buffer.
+func (cw *compressWriter) decide() error {
+	if cw.decided {
+		return nil
+	}
+	cw.decided = true
+
+	h := cw.ResponseWriter.Header()
+	compress := len(cw.buf) >= cw.minBytes && h.Get("Content-Encoding") == "" &&
+		cw.status != http.StatusNoContent && cw.status != http.StatusNotModified
+	if compress {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		if cw.encoding == "gzip" {
+			cw.enc = gzip.NewWriter(cw.ResponseWriter)
+		} else {
+			cw.enc = zlib.NewWriter(cw.ResponseWriter)
+		}
+	}
+	if cw.status != 0 {
+		cw.ResponseWriter.WriteHeader(cw.status)
+	}
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close writes out whatever is still buffered and ends the encoding.
+func (cw *compressWriter) close() {
+	if err := cw.decide(); err != nil {
+		return
+	}
+	if cw.enc != nil {
+		cw.enc.Close()
+	}
+}
@@ -0,0 +1,199 @@
+package proxy
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestAcceptedEncoding(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", ""},
+		{"identity", ""},
+		{"gzip", "gzip"},
+		{"deflate", "deflate"},
+		{"deflate, gzip", "gzip"},
+		{"br, deflate;q=0.5", "deflate"},
+		{"GZIP", "gzip"},
+		{"gzip;q=0, deflate", "deflate"},
+		{"gzip; q=0", ""},
+	}
+	for _, tt := range tests {
+		if got := acceptedEncoding(tt.header); got != tt.want {
+			t.Errorf("acceptedEncoding(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+// catalogUpstreams returns servers with tools shaped like real ones: similar
+// names, descriptions, and input schemas repeated across servers.
+func catalogUpstreams(servers, toolsPerServer int) []mcp2test.Upstream {
+	var upstreams []mcp2test.Upstream
+	for s := range servers {
+		u := mcp2test.Upstream{ID: fmt.Sprintf("server%d", s)}
+		for i := range toolsPerServer {
+			u.Tools = append(u.Tools, &mcp.Tool{
+				Name:        fmt.Sprintf("list_items_%d", i),
+				Description: fmt.Sprintf("Lists the items in collection %d of %s, optionally filtered by a query, newest first. Returns at most limit items per page.", i, u.ID),
+				InputSchema: map[string]any{
+					"type": "object",
+					"properties": map[string]any{
+						"collection": map[string]any{"type": "string", "description": "The collection to list"},
+						"query":      map[string]any{"type": "string", "description": "Only return items matching this query"},
+						"limit":      map[string]any{"type": "integer", "description": "The maximum number of items to return", "minimum": 1, "maximum": 100},
+						"cursor":     map[string]any{"type": "string", "description": "The cursor returned by the previous page"},
+					},
+					"required": []any{"collection"},
+				},
+				Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+			})
+		}
+		upstreams = append(upstreams, u)
+	}
+	return upstreams
+}
+
+// postEncoded posts a JSON-RPC payload like postJSONRPC, asking for
+// encoding and returning the body as sent.
+func postEncoded(t *testing.T, url, sessionID, encoding, payload string) (*http.Response, []byte) {
+	t.Helper()
+
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBufferString(payload))
+	if err != nil {
+		t.Fatalf("Failed to build request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	req.Header.Set("Mcp-Protocol-Version", "2025-03-26")
+	req.Header.Set("Accept-Encoding", encoding)
+	if sessionID != "" {
+		req.Header.Set("Mcp-Session-Id", sessionID)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("Failed to read response: %v", err)
+	}
+	return resp, body
+}
+
+func TestCompress_HubList(t *testing.T) {
+	upstreams := catalogUpstreams(8, 15)
+	servers := map[string]config.ServerProfileConfig{}
+	for _, u := range upstreams {
+		servers[u.ID] = config.ServerProfileConfig{}
+	}
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{"test": {Servers: servers}},
+		Hub:      config.HubConfig{PrefixServerIDs: true},
+	}
+	hub := NewHub(cfg, mcp2test.NewManager(t, upstreams...), "test")
+
+	srv := httptest.NewServer(Compress(0, hub.HTTPHandler(nil)))
+	defer srv.Close()
+
+	// The initialize result is under the threshold
+	resp, _ := postEncoded(t, srv.URL, "", "gzip", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"downstream","version":"1.0.0"}}}`)
+	sessionID := resp.Header.Get("Mcp-Session-Id")
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("Initialize failed: status %d, session %q", resp.StatusCode, sessionID)
+	}
+	if got := resp.Header.Get("Content-Encoding"); got != "" {
+		t.Errorf("initialize response Content-Encoding = %q, want none", got)
+	}
+	postEncoded(t, srv.URL, sessionID, "gzip", `{"jsonrpc":"2.0","method":"notifications/initialized","params":{}}`)
+
+	const list = `{"jsonrpc":"2.0","id":2,"method":"tools/list","params":{}}`
+	_, plain := postEncoded(t, srv.URL, sessionID, "identity", list)
+
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip":    func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"deflate": func(r io.Reader) (io.Reader, error) { return zlib.NewReader(r) },
+	}
+	for encoding, newReader := range decoders {
+		resp, body := postEncoded(t, srv.URL, sessionID, encoding, list)
+		if got := resp.Header.Get("Content-Encoding"); got != encoding {
+			t.Fatalf("tools/list Content-Encoding = %q, want %q", got, encoding)
+		}
+		r, err := newReader(bytes.NewReader(body))
+		if err != nil {
+			t.Fatalf("%s response doesn't decode: %v", encoding, err)
+		}
+		decoded, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%s response doesn't decode: %v", encoding, err)
+		}
+		// Event IDs differ between streams, so compare the data lines
+		if !bytes.Equal(eventData(decoded), eventData(plain)) {
+			t.Errorf("decoded %s event data differs from the uncompressed response", encoding)
+		}
+
+		t.Logf("tools/list of 120 tools on 8 servers: %d bytes, %d with %s (%.0f%%)",
+			len(plain), len(body), encoding, 100*float64(len(body))/float64(len(plain)))
+		if len(body)*5 > len(plain) {
+			t.Errorf("%s response is %d bytes, want under a fifth of %d", encoding, len(body), len(plain))
+		}
+	}
+}
+
+// eventData returns the data lines of an event stream.
+func eventData(stream []byte) []byte {
+	var data []byte
+	for _, line := range bytes.Split(stream, []byte("\n")) {
+		if bytes.HasPrefix(line, []byte("data:")) {
+			data = append(data, line...)
+		}
+	}
+	return data
+}
+
+func TestCompress_Client(t *testing.T) {
+	upstreams := catalogUpstreams(3, 20)
+	servers := map[string]config.ServerProfileConfig{}
+	for _, u := range upstreams {
+		servers[u.ID] = config.ServerProfileConfig{}
+	}
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{"test": {Servers: servers}},
+		Hub:      config.HubConfig{PrefixServerIDs: true},
+	}
+	hub := NewHub(cfg, mcp2test.NewManager(t, upstreams...), "test")
+
+	srv := httptest.NewServer(Compress(0, hub.HTTPHandler(nil)))
+	// Registered first, so it runs after the client session closes
+	t.Cleanup(srv.Close)
+
+	// The client's transport asks for gzip and decodes it
+	session, err := connectHTTPClient(t, srv.URL, "User-Agent", "downstream")
+	if err != nil {
+		t.Fatalf("Connect failed: %v", err)
+	}
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(result.Tools) != 60 {
+		t.Errorf("ListTools returned %d tools, want 60", len(result.Tools))
+	}
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "server0:list_items_0"}); err != nil {
+		t.Errorf("CallTool failed: %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// trackPending records that session has a request in flight to serverID when
+// hub.allowElicitation or hub.allowSampling is set. The returned func removes
+// the record.
+func (h *Hub) trackPending(serverID string, session *mcp.ServerSession) func() {
+	if !(h.config.Hub.AllowElicitation || h.config.Hub.AllowSampling) || session == nil {
+		return func() {}
+	}
+
+	h.pendingMu.Lock()
+	if h.pending[serverID] == nil {
+		h.pending[serverID] = make(map[*mcp.ServerSession]int)
+	}
+	h.pending[serverID][session]++
+	h.pendingMu.Unlock()
+
+	return func() {
+		h.pendingMu.Lock()
+		defer h.pendingMu.Unlock()
+		if h.pending[serverID][session]--; h.pending[serverID][session] == 0 {
+			delete(h.pending[serverID], session)
+		}
+	}
+}
+
+// pendingClient returns the downstream session to forward a feature request
+// (elicitation or sampling) from serverID to: the client whose request the
+// upstream is serving. Upstream sessions are shared, so the request can only
+// be attributed when exactly one downstream session is waiting on serverID;
+// otherwise it is refused rather than risk asking the wrong user. With no
+// session waiting it returns upstream.ErrUnclaimed so another hub on the same
+// manager can answer. supported reports whether the client can handle the
+// request.
+func (h *Hub) pendingClient(serverID, feature string, supported func(*mcp.ClientCapabilities) bool) (*mcp.ServerSession, error) {
+	h.pendingMu.Lock()
+	var sessions []*mcp.ServerSession
+	for session := range h.pending[serverID] {
+		sessions = append(sessions, session)
+	}
+	h.pendingMu.Unlock()
+
+	switch len(sessions) {
+	case 0:
+		h.logger().Warn(feature+" refused: no client request in flight", "server", serverID)
+		return nil, fmt.Errorf("%s from %q refused: %w", feature, serverID, upstream.ErrUnclaimed)
+	case 1:
+	default:
+		h.logger().Warn(feature+" refused: several clients have requests in flight", "server", serverID, "clients", len(sessions))
+		return nil, fmt.Errorf("%s from %q refused: cannot tell which of %d clients it is for", feature, serverID, len(sessions))
+	}
+
+	session := sessions[0]
+	if init := session.InitializeParams(); init == nil || init.Capabilities == nil || !supported(init.Capabilities) {
+		h.logger().Warn(feature+" refused: client does not support "+feature, "server", serverID)
+		return nil, fmt.Errorf("%s from %q refused: client does not support %s", feature, serverID, feature)
+	}
+	return session, nil
+}
+
+// relayElicitation forwards an elicitation/create request from serverID to
+// the downstream client chosen by pendingClient, and returns its answer.
+func (h *Hub) relayElicitation(ctx context.Context, serverID string, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	serverID = h.logicalID(serverID)
+	session, err := h.pendingClient(serverID, "elicitation", func(caps *mcp.ClientCapabilities) bool {
+		return caps.Elicitation != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger().Debug("relaying elicitation", "server", serverID)
+	return session.Elicit(ctx, params)
+}
+
+// relaySampling forwards a sampling/createMessage request from serverID to
+// the downstream client chosen by pendingClient, and returns its answer.
+func (h *Hub) relaySampling(ctx context.Context, serverID string, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+	serverID = h.logicalID(serverID)
+	session, err := h.pendingClient(serverID, "sampling", func(caps *mcp.ClientCapabilities) bool {
+		return caps.Sampling != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger().Debug("relaying sampling request", "server", serverID)
+	return session.CreateMessage(ctx, params)
+}
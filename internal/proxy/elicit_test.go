@@ -0,0 +1,121 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_RelaysElicitation(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, AllowElicitation: true},
+	}
+
+	ctx := context.Background()
+	manager := upstream.NewManager()
+	manager.EnableElicitation()
+	mcp2test.Add(t, manager, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: "confirm"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+				Message: "Delete everything?",
+				RequestedSchema: map[string]any{
+					"type":       "object",
+					"properties": map[string]any{"confirm": map[string]any{"type": "boolean"}},
+				},
+			})
+			if err != nil {
+				return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}, nil, nil
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: result.Action}}}, nil, nil
+		})
+	}})
+	hub := NewHub(cfg, manager, "test")
+
+	var gotMessage string
+	session := mcp2test.ConnectWith(t, hub.Server(), &mcp.ClientOptions{
+		ElicitationHandler: func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			gotMessage = req.Params.Message
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		},
+	})
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1:confirm"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if gotMessage != "Delete everything?" {
+		t.Errorf("Client elicitation message = %q, want the upstream's", gotMessage)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; result.IsError || text != "decline" {
+		t.Errorf("Tool result = %q (isError %v), want the client's decline relayed", text, result.IsError)
+	}
+
+	// A client that cannot answer elicitation gets an error instead of a hang
+	result, err = mcp2test.Connect(t, hub.Server()).CallTool(ctx, &mcp.CallToolParams{Name: "server1:confirm"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("Expected elicitation to fail for a client without the capability, got %+v", result.Content[0])
+	}
+}
+
+func TestHub_RelaysSampling(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, AllowSampling: true},
+	}
+
+	ctx := context.Background()
+	manager := upstream.NewManager()
+	manager.EnableSampling()
+
+	var advertised bool
+	mcp2test.Add(t, manager, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: "summarize"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			advertised = req.Session.InitializeParams().Capabilities.Sampling != nil
+			result, err := req.Session.CreateMessage(ctx, &mcp.CreateMessageParams{
+				Messages:  []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: "Summarize this"}}},
+				MaxTokens: 100,
+			})
+			if err != nil {
+				return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}}}, nil, nil
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{result.Content}}, nil, nil
+		})
+	}})
+	hub := NewHub(cfg, manager, "test")
+
+	session := mcp2test.ConnectWith(t, hub.Server(), &mcp.ClientOptions{
+		CreateMessageHandler: func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			return &mcp.CreateMessageResult{Role: "assistant", Model: "test", Content: &mcp.TextContent{Text: "a summary"}}, nil
+		},
+	})
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1:summarize"})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if !advertised {
+		t.Error("Expected the upstream client to advertise sampling")
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; result.IsError || text != "a summary" {
+		t.Errorf("Tool result = %q (isError %v), want the client's completion relayed", text, result.IsError)
+	}
+}
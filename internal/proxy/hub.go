@@ -3,43 +3,108 @@ package proxy
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/ain3sh/mcp2/internal/config"
 	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/redact"
+	"github.com/ain3sh/mcp2/internal/requestid"
 	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/ain3sh/mcp2/internal/version"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // Hub is the central MCP server that aggregates multiple upstreams.
 type Hub struct {
-	server         *mcp.Server
-	manager        *upstream.Manager
-	config         *config.RootConfig
-	profileEngine  *profile.Engine
-	prefixEnabled  bool
+	server  *mcp.Server
+	manager *upstream.Manager
+	config  *config.RootConfig
+	// profileName is the profile requests are served with unless the hub's
+	// HTTP handler chooses another.
+	profileName string
+	debug       bool
+	log         *slog.Logger
+	audit       *auditSink
+
+	// views holds a view of every configured profile, built up front so
+	// each request can be served with its own; see HTTPHandler.
+	views map[string]*profileView
+
+	// longNames records exposed tool names already logged as over
+	// hub.maxNameLength, so each is logged once.
+	longNames sync.Map
+
+	// progress maps upstream progress tokens to the downstream call they
+	// belong to.
+	progress   map[string]progressTarget
+	progressMu sync.Mutex
+
+	// logLevelsSet records the upstreams asked to send log messages, and
+	// logRequested whether any client has set a logging level.
+	logLevelsSet map[string]bool
+	logRequested bool
+	logLevelsMu  sync.Mutex
+
+	// logQueue holds log messages waiting to be relayed, and logRelaying
+	// whether a goroutine is sending them.
+	logQueue    []*mcp.LoggingMessageParams
+	logRelaying bool
+	logQueueMu  sync.Mutex
+
+	// pending counts, per server ID, the downstream sessions' requests in
+	// flight to that server, to route elicitation requests.
+	pending   map[string]map[*mcp.ServerSession]int
+	pendingMu sync.Mutex
+
+	// replicas holds the hub's replica groups by group ID, and replicaOf
+	// the group each member server belongs to.
+	replicas  map[string]*replicaGroup
+	replicaOf map[string]string
 }
 
 // NewHub creates a new hub server with profile-based filtering.
 func NewHub(cfg *config.RootConfig, manager *upstream.Manager, profileName string) *Hub {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    "mcp2-hub",
-		Version: "0.1.0",
+		Version: version.Version,
 	}, nil)
 
 	hub := &Hub{
-		server:        server,
-		manager:       manager,
-		config:        cfg,
-		profileEngine: profile.NewEngine(cfg, profileName),
-		prefixEnabled: cfg.Hub.PrefixServerIDs,
+		server:       server,
+		manager:      manager,
+		config:       cfg,
+		profileName:  profileName,
+		views:        newProfileViews(cfg, profileName),
+		progress:     make(map[string]progressTarget),
+		logLevelsSet: make(map[string]bool),
+		pending:      make(map[string]map[*mcp.ServerSession]int),
+	}
+	hub.replicas, hub.replicaOf = newReplicaGroups(cfg)
+	manager.OnProgress(hub.relayProgress)
+	manager.OnListChanged(hub.forwardListChanged)
+	if cfg.Hub.AllowElicitation {
+		manager.OnElicit(hub.relayElicitation)
+	}
+	if cfg.Hub.AllowSampling {
+		manager.OnCreateMessage(hub.relaySampling)
 	}
 
 	// Register aggregated tool handler
 	hub.registerToolHandlers()
 	hub.registerResourceHandlers()
 	hub.registerPromptHandlers()
+	hub.registerUnknownMethods()
+	if cfg.Hub.RelayLogs {
+		hub.registerLogRelay()
+	}
+	hub.registerRequestLogging()
 
 	return hub
 }
@@ -49,6 +114,199 @@ func (h *Hub) Server() *mcp.Server {
 	return h.server
 }
 
+// SetDebug enables debug logging for the hub.
+func (h *Hub) SetDebug(enabled bool) {
+	h.debug = enabled
+}
+
+// SetLogger sets the logger used by the hub. By default it logs to
+// slog.Default().
+func (h *Hub) SetLogger(logger *slog.Logger) {
+	h.log = logger
+}
+
+// logger returns the hub's logger, falling back to slog.Default().
+func (h *Hub) logger() *slog.Logger {
+	if h.log != nil {
+		return h.log
+	}
+	return slog.Default()
+}
+
+// requestInfo carries per-request details that handlers fill in for logging.
+type requestInfo struct {
+	serverID string
+	// session is the downstream session the request arrived on.
+	session *mcp.ServerSession
+	// secrets are argument values that must not appear in errors or logs.
+	secrets []string
+}
+
+// inboundRequestID returns the request ID supplied by the client in the
+// X-Request-ID header, or a new one.
+func inboundRequestID(req mcp.Request) string {
+	if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+		if id := extra.Header.Get(requestid.Header); id != "" {
+			return id
+		}
+	}
+	return requestid.New()
+}
+
+// requestMeta returns the _meta sent upstream, carrying the request ID and
+// downstream client identity so upstreams without an HTTP transport can see
+// them too.
+func requestMeta(ctx context.Context) mcp.Meta {
+	var meta mcp.Meta
+	if id := requestid.From(ctx); id != "" {
+		meta = mcp.Meta{requestid.MetaKey: id}
+	}
+	if client := upstream.ClientInfoFrom(ctx); client != nil {
+		if meta == nil {
+			meta = mcp.Meta{}
+		}
+		meta[upstream.ClientInfoMetaKey] = upstream.ClientIdentity(client)
+	}
+	return meta
+}
+
+// downstreamClientInfo returns the implementation info the downstream client
+// sent in its initialize request, if known.
+func downstreamClientInfo(req mcp.Request) *mcp.Implementation {
+	ss, ok := req.GetSession().(*mcp.ServerSession)
+	if !ok || ss == nil {
+		return nil
+	}
+	if params := ss.InitializeParams(); params != nil {
+		return params.ClientInfo
+	}
+	return nil
+}
+
+type requestInfoKey struct{}
+
+// setRequestServer records the upstream server a request was routed to.
+func setRequestServer(ctx context.Context, serverID string) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.serverID = serverID
+	}
+}
+
+// setRequestSecrets records the values of sensitive arguments in args, as
+// judged by redact.IsSensitiveKey, so scrub can mask them.
+func setRequestSecrets(ctx context.Context, args map[string]any) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		info.secrets = redact.Secrets(args)
+	}
+}
+
+// scrub masks the request's sensitive argument values in s, since upstream
+// errors may echo the arguments they were called with.
+func scrub(ctx context.Context, s string) string {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok && len(info.secrets) > 0 {
+		return redact.Scrub(s, info.secrets)
+	}
+	return s
+}
+
+// scrubError returns err with the request's sensitive argument values
+// masked, or err itself if it contains none. A JSON-RPC error, or one
+// wrapping it, stays a JSON-RPC error with the same code and masked message
+// and data.
+func scrubError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	msg := scrub(ctx, err.Error())
+	if msg == err.Error() {
+		return err
+	}
+	if wire, ok := decodeWireError(err); ok {
+		data := json.RawMessage(scrub(ctx, string(wire.Data)))
+		if !json.Valid(data) {
+			data = nil
+		}
+		return newWireError(wire.Code, msg, data)
+	}
+	return errors.New(msg)
+}
+
+// toolArguments decodes a tool call's raw arguments, or returns nil if they
+// are not a JSON object.
+func toolArguments(raw json.RawMessage) map[string]any {
+	var args map[string]any
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &args)
+	}
+	return args
+}
+
+// promptArguments converts a prompt's arguments for setRequestSecrets.
+func promptArguments(args map[string]string) map[string]any {
+	out := make(map[string]any, len(args))
+	for k, v := range args {
+		out[k] = v
+	}
+	return out
+}
+
+// requestSession returns the downstream session the request in ctx arrived
+// on, if known.
+func requestSession(ctx context.Context) *mcp.ServerSession {
+	if info, ok := ctx.Value(requestInfoKey{}).(*requestInfo); ok {
+		return info.session
+	}
+	return nil
+}
+
+// registerRequestLogging logs every request with its method, profile, routed
+// server, and duration. It is registered last so it wraps all other handlers.
+func (h *Hub) registerRequestLogging() {
+	h.server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if strings.HasPrefix(method, "notifications/") {
+				return next(ctx, method, req)
+			}
+
+			info := &requestInfo{}
+			info.session, _ = req.GetSession().(*mcp.ServerSession)
+			ctx = context.WithValue(ctx, requestInfoKey{}, info)
+			view := h.requestView(req)
+			ctx = context.WithValue(ctx, profileViewKey{}, view)
+
+			id := inboundRequestID(req)
+			ctx = requestid.With(ctx, id)
+
+			if h.config.Hub.ForwardClientInfo {
+				if client := downstreamClientInfo(req); client != nil {
+					ctx = upstream.WithClientInfo(ctx, client)
+				}
+			}
+
+			start := time.Now()
+			result, err := next(ctx, method, req)
+			err = scrubError(ctx, err)
+
+			attrs := []any{
+				"requestId", id,
+				"method", method,
+				"profile", view.name,
+				"duration", time.Since(start),
+			}
+			if info.serverID != "" {
+				attrs = append(attrs, "server", info.serverID)
+			}
+			if err != nil {
+				attrs = append(attrs, "error", err)
+				h.logger().Warn("request failed", attrs...)
+			} else {
+				h.logger().Info("request", attrs...)
+			}
+			return result, err
+		}
+	})
+}
+
 // registerToolHandlers sets up tool aggregation and proxying.
 func (h *Hub) registerToolHandlers() {
 	// Override the default tools/list handler to aggregate from all upstreams
@@ -100,32 +358,148 @@ func (h *Hub) registerPromptHandlers() {
 
 // handleToolsList aggregates and filters tools from all upstream servers.
 func (h *Hub) handleToolsList(ctx context.Context) (mcp.Result, error) {
+	v := h.view(ctx)
 	var allTools []*mcp.Tool
+	var owners []string
+	listed := make(map[string]map[string]bool)
 
-	for _, u := range h.manager.List() {
-		result, err := u.Session.ListTools(ctx, nil)
+	for _, u := range h.orderedUpstreams(ctx) {
+		if !u.SupportsTools() {
+			continue
+		}
+		result, err := u.Session().ListTools(ctx, nil)
 		if err != nil {
 			// Log error but continue with other upstreams
 			continue
 		}
 
+		sortTools(u, result.Tools)
+
+		maxTools := 0
+		if u.Config != nil {
+			maxTools = u.Config.MaxTools
+		}
+
+		names := make(map[string]bool)
+		dropped := 0
 		for _, tool := range result.Tools {
 			// Filter based on profile
-			if !h.profileEngine.IsToolAllowed(u.ID, tool.Name) {
+			if !v.engine.ExplainToolCall(u.ID, tool.Name, isReadOnly(tool)).Allowed {
 				continue
 			}
+			if maxTools > 0 && len(names) >= maxTools {
+				dropped++
+				continue
+			}
+			names[tool.Name] = true
+			tool = cloneTool(tool)
+
+			if h.config.Hub.DisplayNameInTitles && u.DisplayName != "" {
+				tool.Title = fmt.Sprintf("%s (%s)", toolTitle(tool), u.DisplayName)
+			}
 
 			// Add server prefix if enabled
-			if h.prefixEnabled {
-				tool.Name = fmt.Sprintf("%s:%s", u.ID, tool.Name)
+			if v.prefix.Tools {
+				tool.Name = v.prefixName(u.ID, tool.Name)
+				h.warnIfNameTooLong(u.ID, tool.Name)
 			}
 			allTools = append(allTools, tool)
+			owners = append(owners, u.ID)
 		}
+		listed[u.ID] = names
+		if dropped > 0 {
+			h.logger().Info("tools over server maxTools not listed", "server", u.ID, "maxTools", maxTools, "dropped", dropped)
+		}
+	}
+
+	if limit := h.config.Hub.MaxTools; limit > 0 && len(allTools) > limit {
+		h.logger().Info("tools over hub maxTools not listed", "maxTools", limit, "dropped", len(allTools)-limit)
+		allTools, owners = allTools[:limit], owners[:limit]
+	}
+	allTools, dropped := fitListBytes(h, "tools", allTools, owners, func(tools []*mcp.Tool) mcp.Result {
+		return &mcp.ListToolsResult{Tools: tools}
+	})
+	for _, serverID := range dropped {
+		delete(listed, serverID)
 	}
 
+	if h.config.Hub.StatusTool && h.allServersDown(v) {
+		allTools = append(allTools, statusTool())
+	}
+	if v.diagnosticsTool {
+		allTools = append(allTools, pingTool())
+	}
+
+	v.listedMu.Lock()
+	v.listedTools = listed
+	v.listedMu.Unlock()
+
 	return &mcp.ListToolsResult{Tools: allTools}, nil
 }
 
+// toolTitle returns the title a client would show for tool: its title, else
+// the title in its annotations, else its name.
+func toolTitle(tool *mcp.Tool) string {
+	if tool.Title != "" {
+		return tool.Title
+	}
+	if tool.Annotations != nil && tool.Annotations.Title != "" {
+		return tool.Annotations.Title
+	}
+	return tool.Name
+}
+
+// warnIfNameTooLong logs, once per name, a prefixed tool name longer than
+// hub.maxNameLength, which clients with a name length limit will reject.
+func (h *Hub) warnIfNameTooLong(serverID, name string) {
+	limit := h.config.Hub.MaxNameLength
+	if limit == 0 || len(name) <= limit {
+		return
+	}
+	if _, logged := h.longNames.LoadOrStore(name, true); logged {
+		return
+	}
+	h.logger().Warn("exposed tool name over maxNameLength; use a shorter server ID",
+		"server", serverID, "tool", name, "length", len(name), "maxNameLength", limit)
+}
+
+// warnIfListed logs a warning when a tool denied at call time was exposed by
+// the most recent tools/list, which indicates a filter bug or a race.
+func (h *Hub) warnIfListed(v *profileView, serverID, toolName string) {
+	if !h.debug {
+		return
+	}
+
+	v.listedMu.RLock()
+	listed := v.listedTools[serverID][toolName]
+	v.listedMu.RUnlock()
+
+	if listed {
+		h.logger().Warn("listed tool denied at call time",
+			"server", serverID, "tool", toolName, "profile", v.name)
+	}
+}
+
+// deniedError is the error returned when the profile denies a request for
+// name, carrying the configured deny message if there is one.
+func deniedError(kind, name string, d profile.Decision) error {
+	if d.Message != "" {
+		return fmt.Errorf("%s %q is not allowed by profile: %s", kind, name, d.Message)
+	}
+	return fmt.Errorf("%s %q is not allowed by profile", kind, name)
+}
+
+// explicitDenial returns d if a deny pattern with a message matched it, else
+// the denial seen so far. Without prefixing a name is tried on every server,
+// so only explicit deny messages and safe mode are surfaced; an allow-list
+// miss more likely means the server doesn't have the name at all.
+func explicitDenial(seen, d profile.Decision) profile.Decision {
+	if seen.Reason == "" && (d.Reason == profile.ReasonDenied || d.Reason == profile.ReasonSafeMode) && d.Message != "" {
+		return d
+	}
+	return seen
+}
+
 // handleToolsCall routes tool calls to the appropriate upstream.
 func (h *Hub) handleToolsCall(ctx context.Context, req mcp.Request) (mcp.Result, error) {
 	callReq, ok := req.(*mcp.CallToolRequest)
@@ -133,112 +507,210 @@ func (h *Hub) handleToolsCall(ctx context.Context, req mcp.Request) (mcp.Result,
 		return nil, fmt.Errorf("invalid request type for tools/call")
 	}
 
+	setRequestSecrets(ctx, toolArguments(callReq.Params.Arguments))
+
+	v := h.view(ctx)
 	toolName := callReq.Params.Name
+	if h.config.Hub.StatusTool && toolName == StatusToolName {
+		return h.handleStatusTool(v), nil
+	}
+	if v.diagnosticsTool && toolName == PingToolName {
+		return h.handlePingTool(v), nil
+	}
+	if mode := broadcastMode(callReq.Params.Meta); mode != "" {
+		return h.broadcastToolCall(ctx, mode, callReq, v.broadcastName(toolName))
+	}
+
 	var serverID string
 	var actualToolName string
 
-	if h.prefixEnabled {
+	if v.prefix.Tools {
 		// Parse server:toolname
-		parts := strings.SplitN(toolName, ":", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("tool name must be in format 'server:toolname' when prefixing is enabled")
+		var ok bool
+		serverID, actualToolName, ok = v.splitName(toolName)
+		if !ok {
+			return nil, fmt.Errorf("tool name must be in format 'server%stoolname' when tool names are prefixed", v.separator)
 		}
-		serverID = parts[0]
-		actualToolName = parts[1]
 	} else {
 		// Without prefixing, try only upstreams where the profile allows this tool
-		var lastErr error
-		for _, u := range h.manager.List() {
-			if !h.profileEngine.IsToolAllowed(u.ID, toolName) {
+		var attempts []CallAttempt
+		var denial profile.Decision
+		for _, u := range h.orderedUpstreams(ctx) {
+			decision := explainToolCall(ctx, v.engine, u, toolName)
+			if !h.auditDecision(ctx, "tool", u.ID, toolName, decision.Allowed) {
+				h.warnIfListed(v, u.ID, toolName)
+				denial = explicitDenial(denial, decision)
 				continue
 			}
-			result, err := u.Session.CallTool(ctx, &mcp.CallToolParams{
-				Name:      toolName,
-				Arguments: callReq.Params.Arguments,
-			})
+			params, done := h.toolCallParams(ctx, callReq, u.ID, toolName)
+			start := time.Now()
+			result, err := u.Session().CallTool(ctx, params)
+			done()
+			h.auditToolCall(ctx, u.ID, toolName, start, result, err)
 			if err == nil {
+				setRequestServer(ctx, u.ID)
 				return result, nil
 			}
-			lastErr = err
+			attempts = append(attempts, CallAttempt{Server: u.ID, Error: scrub(ctx, err.Error())})
 		}
-		if lastErr != nil {
-			return nil, fmt.Errorf("tool %q allowed by profile but call failed: %v", toolName, lastErr)
+		if len(attempts) > 0 {
+			return failedAttemptsResult(toolName, attempts), nil
+		}
+		if denial.Reason != "" {
+			return nil, deniedError("tool", toolName, denial)
 		}
 		return nil, fmt.Errorf("tool %q not found in any upstream or not allowed by profile", toolName)
 	}
 
 	// Get the upstream server
-	u, err := h.manager.Get(serverID)
+	u, err := h.resolveUpstream(ctx, serverID)
 	if err != nil {
 		return nil, fmt.Errorf("upstream server %q not found", serverID)
 	}
-
-	// Check if tool is allowed by profile (call-phase check)
-	if !h.profileEngine.IsToolAllowed(serverID, actualToolName) {
-		return nil, fmt.Errorf("tool %q is not allowed by profile", toolName)
+	setRequestServer(ctx, serverID)
+
+	// Check if tool is allowed by profile (call-phase check). Trusted
+	// profiles skip it unless safe mode is on, but the server must still be
+	// in the profile.
+	if !v.engine.ListEnforcedOnly() || v.engine.SafeMode() {
+		decision := explainToolCall(ctx, v.engine, u, actualToolName)
+		if !h.auditDecision(ctx, "tool", serverID, actualToolName, decision.Allowed) {
+			h.warnIfListed(v, serverID, actualToolName)
+			return nil, deniedError("tool", toolName, decision)
+		}
+	} else if !v.engine.HasServer(serverID) {
+		return nil, deniedError("tool", toolName, profile.Decision{Reason: profile.ReasonServerNotInProfile})
 	}
 
 	// Call the tool on the upstream
-	return u.Session.CallTool(ctx, &mcp.CallToolParams{
-		Name:      actualToolName,
-		Arguments: callReq.Params.Arguments,
-	})
+	params, done := h.toolCallParams(ctx, callReq, serverID, actualToolName)
+	defer done()
+	start := time.Now()
+	result, err := u.Session().CallTool(ctx, params)
+	h.auditToolCall(ctx, serverID, actualToolName, start, result, err)
+	return result, err
 }
 
 // handleResourcesList aggregates and filters resources from all upstream servers.
+// Upstreams are listed in parallel, skipping those that don't advertise
+// resources, and results are merged in serverOrder order with each server's
+// resources sorted by URI, so the list is the same on every call.
 func (h *Hub) handleResourcesList(ctx context.Context) (mcp.Result, error) {
-	var allResources []*mcp.Resource
+	v := h.view(ctx)
+	upstreams := h.orderedUpstreams(ctx)
+	listed := make([][]*mcp.Resource, len(upstreams))
 
-	for _, u := range h.manager.List() {
-		result, err := u.Session.ListResources(ctx, nil)
-		if err != nil {
+	var wg sync.WaitGroup
+	for i, u := range upstreams {
+		if !u.SupportsResources() {
 			continue
 		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := u.Session().ListResources(ctx, nil)
+			if err != nil {
+				h.logger().Debug("failed to list resources", "server", u.ID, "error", err)
+				return
+			}
+			listed[i] = result.Resources
+		}()
+	}
+	wg.Wait()
 
-		for _, resource := range result.Resources {
+	var allResources []*mcp.Resource
+	var owners []string
+	for i, u := range upstreams {
+		resources := listed[i]
+		sort.SliceStable(resources, func(a, b int) bool {
+			return resources[a].URI < resources[b].URI
+		})
+
+		for _, resource := range resources {
 			// Filter based on profile
-			if !h.profileEngine.IsResourceAllowed(u.ID, resource.URI) {
+			if !v.engine.ExplainResourceMetadata(u.ID, resource.URI, resourceMetadata(resource)).Allowed {
 				continue
 			}
 
+			resource = cloneResource(resource)
+
 			// Prefix URI if needed
-			if h.prefixEnabled {
-				resource.URI = fmt.Sprintf("%s:%s", u.ID, resource.URI)
+			if v.prefix.Resources {
+				resource.URI = v.prefixName(u.ID, resource.URI)
 			}
 			allResources = append(allResources, resource)
+			owners = append(owners, u.ID)
 		}
 	}
+	allResources, _ = fitListBytes(h, "resources", allResources, owners, func(resources []*mcp.Resource) mcp.Result {
+		return &mcp.ListResourcesResult{Resources: resources}
+	})
 
 	return &mcp.ListResourcesResult{Resources: allResources}, nil
 }
 
-// handleResourcesRead routes resource reads to the appropriate upstream.
+// handleResourcesRead routes resource reads to the appropriate upstream,
+// then slices the contents to the range asked for under RangeMetaKey and
+// enforces hub.maxResourceBytes.
 func (h *Hub) handleResourcesRead(ctx context.Context, req mcp.Request) (mcp.Result, error) {
 	readReq, ok := req.(*mcp.ReadResourceRequest)
 	if !ok {
 		return nil, fmt.Errorf("invalid request type for resources/read")
 	}
 
+	// Reject a malformed range before reading
+	if _, _, err := requestedRange(readReq.Params.Meta); err != nil {
+		return nil, err
+	}
+	result, err := h.readResource(ctx, readReq)
+	if err != nil {
+		return nil, err
+	}
+	if readResult, ok := result.(*mcp.ReadResourceResult); ok {
+		if err := shapeContents(readReq.Params, readResult, h.config.Hub.MaxResourceBytes); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// readResource reads the resource requested by readReq in full.
+func (h *Hub) readResource(ctx context.Context, readReq *mcp.ReadResourceRequest) (mcp.Result, error) {
+	v := h.view(ctx)
 	uri := readReq.Params.URI
+	if mode := broadcastMode(readReq.Params.Meta); mode != "" {
+		return h.broadcastResourceRead(ctx, mode, readReq, v.broadcastName(uri))
+	}
+
 	var serverID string
 	var actualURI string
 
-	if h.prefixEnabled {
-		parts := strings.SplitN(uri, ":", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("resource URI must be in format 'server:uri' when prefixing is enabled")
+	if v.prefix.Resources {
+		var ok bool
+		serverID, actualURI, ok = v.splitName(uri)
+		if !ok {
+			return nil, fmt.Errorf("resource URI must be in format 'server%suri' when resource URIs are prefixed", v.separator)
 		}
-		serverID = parts[0]
-		actualURI = parts[1]
 	} else {
 		// Try only upstreams where the profile allows this resource
-		var lastErr error
-		for _, u := range h.manager.List() {
-			if !h.profileEngine.IsResourceAllowed(u.ID, uri) {
+		var lastErr, checkErr error
+		var denial profile.Decision
+		for _, u := range h.orderedUpstreams(ctx) {
+			decision, err := explainResourceRead(ctx, v.engine, u, uri)
+			if err != nil {
+				h.auditDecision(ctx, "resource", u.ID, uri, false)
+				checkErr = err
+				continue
+			}
+			if !h.auditDecision(ctx, "resource", u.ID, uri, decision.Allowed) {
+				denial = explicitDenial(denial, decision)
 				continue
 			}
-			result, err := u.Session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+			params, done := h.readResourceParams(ctx, readReq, u.ID, uri)
+			result, err := u.Session().ReadResource(ctx, params)
+			done()
 			if err == nil {
+				setRequestServer(ctx, u.ID)
 				return result, nil
 			}
 			lastErr = err
@@ -246,44 +718,68 @@ func (h *Hub) handleResourcesRead(ctx context.Context, req mcp.Request) (mcp.Res
 		if lastErr != nil {
 			return nil, fmt.Errorf("resource %q allowed by profile but read failed: %v", uri, lastErr)
 		}
+		if checkErr != nil {
+			return nil, checkErr
+		}
+		if denial.Reason != "" {
+			return nil, deniedError("resource", uri, denial)
+		}
 		return nil, fmt.Errorf("resource %q not found in any upstream or not allowed by profile", uri)
 	}
 
-	u, err := h.manager.Get(serverID)
+	u, err := h.resolveUpstream(ctx, serverID)
 	if err != nil {
 		return nil, err
 	}
+	setRequestServer(ctx, serverID)
 
 	// Check if resource is allowed by profile (call-phase check)
-	if !h.profileEngine.IsResourceAllowed(serverID, actualURI) {
-		return nil, fmt.Errorf("resource %q is not allowed by profile", uri)
+	decision, err := explainResourceRead(ctx, v.engine, u, actualURI)
+	if err != nil {
+		h.auditDecision(ctx, "resource", serverID, actualURI, false)
+		return nil, err
+	}
+	if !h.auditDecision(ctx, "resource", serverID, actualURI, decision.Allowed) {
+		return nil, deniedError("resource", uri, decision)
 	}
 
-	return u.Session.ReadResource(ctx, &mcp.ReadResourceParams{URI: actualURI})
+	params, done := h.readResourceParams(ctx, readReq, serverID, actualURI)
+	defer done()
+	return u.Session().ReadResource(ctx, params)
 }
 
 // handlePromptsList aggregates and filters prompts from all upstream servers.
 func (h *Hub) handlePromptsList(ctx context.Context) (mcp.Result, error) {
+	v := h.view(ctx)
 	var allPrompts []*mcp.Prompt
+	var owners []string
 
-	for _, u := range h.manager.List() {
-		result, err := u.Session.ListPrompts(ctx, nil)
+	for _, u := range h.orderedUpstreams(ctx) {
+		if !u.SupportsPrompts() {
+			continue
+		}
+		result, err := u.Session().ListPrompts(ctx, nil)
 		if err != nil {
 			continue
 		}
 
 		for _, prompt := range result.Prompts {
 			// Filter based on profile
-			if !h.profileEngine.IsPromptAllowed(u.ID, prompt.Name) {
+			if !v.engine.IsPromptAllowed(u.ID, prompt.Name) {
 				continue
 			}
+			prompt = clonePrompt(prompt)
 
-			if h.prefixEnabled {
-				prompt.Name = fmt.Sprintf("%s:%s", u.ID, prompt.Name)
+			if v.prefix.Prompts {
+				prompt.Name = v.prefixName(u.ID, prompt.Name)
 			}
 			allPrompts = append(allPrompts, prompt)
+			owners = append(owners, u.ID)
 		}
 	}
+	allPrompts, _ = fitListBytes(h, "prompts", allPrompts, owners, func(prompts []*mcp.Prompt) mcp.Result {
+		return &mcp.ListPromptsResult{Prompts: prompts}
+	})
 
 	return &mcp.ListPromptsResult{Prompts: allPrompts}, nil
 }
@@ -295,29 +791,38 @@ func (h *Hub) handlePromptsGet(ctx context.Context, req mcp.Request) (mcp.Result
 		return nil, fmt.Errorf("invalid request type for prompts/get")
 	}
 
+	setRequestSecrets(ctx, promptArguments(getReq.Params.Arguments))
+
+	v := h.view(ctx)
 	promptName := getReq.Params.Name
 	var serverID string
 	var actualPromptName string
 
-	if h.prefixEnabled {
-		parts := strings.SplitN(promptName, ":", 2)
-		if len(parts) != 2 {
-			return nil, fmt.Errorf("prompt name must be in format 'server:promptname' when prefixing is enabled")
+	if v.prefix.Prompts {
+		var ok bool
+		serverID, actualPromptName, ok = v.splitName(promptName)
+		if !ok {
+			return nil, fmt.Errorf("prompt name must be in format 'server%spromptname' when prompt names are prefixed", v.separator)
 		}
-		serverID = parts[0]
-		actualPromptName = parts[1]
 	} else {
 		// Try only upstreams where the profile allows this prompt
-		var lastErr error
-		for _, u := range h.manager.List() {
-			if !h.profileEngine.IsPromptAllowed(u.ID, promptName) {
+		var lastErr, argErr error
+		var denial profile.Decision
+		for _, u := range h.orderedUpstreams(ctx) {
+			decision := v.engine.ExplainPrompt(u.ID, promptName)
+			if !h.auditDecision(ctx, "prompt", u.ID, promptName, decision.Allowed) {
+				denial = explicitDenial(denial, decision)
 				continue
 			}
-			result, err := u.Session.GetPrompt(ctx, &mcp.GetPromptParams{
-				Name:      promptName,
-				Arguments: getReq.Params.Arguments,
-			})
+			if err := v.engine.CheckPromptArguments(u.ID, promptName, getReq.Params.Arguments); err != nil {
+				argErr = err
+				continue
+			}
+			params, done := h.getPromptParams(ctx, getReq, u.ID, promptName)
+			result, err := u.Session().GetPrompt(ctx, params)
+			done()
 			if err == nil {
+				setRequestServer(ctx, u.ID)
 				return result, nil
 			}
 			lastErr = err
@@ -325,21 +830,31 @@ func (h *Hub) handlePromptsGet(ctx context.Context, req mcp.Request) (mcp.Result
 		if lastErr != nil {
 			return nil, fmt.Errorf("prompt %q allowed by profile but get failed: %v", promptName, lastErr)
 		}
+		if argErr != nil {
+			return nil, argErr
+		}
+		if denial.Reason != "" {
+			return nil, deniedError("prompt", promptName, denial)
+		}
 		return nil, fmt.Errorf("prompt %q not found in any upstream or not allowed by profile", promptName)
 	}
 
-	u, err := h.manager.Get(serverID)
+	u, err := h.resolveUpstream(ctx, serverID)
 	if err != nil {
 		return nil, err
 	}
+	setRequestServer(ctx, serverID)
 
 	// Check if prompt is allowed by profile (call-phase check)
-	if !h.profileEngine.IsPromptAllowed(serverID, actualPromptName) {
-		return nil, fmt.Errorf("prompt %q is not allowed by profile", promptName)
+	decision := v.engine.ExplainPrompt(serverID, actualPromptName)
+	if !h.auditDecision(ctx, "prompt", serverID, actualPromptName, decision.Allowed) {
+		return nil, deniedError("prompt", promptName, decision)
+	}
+	if err := v.engine.CheckPromptArguments(serverID, actualPromptName, getReq.Params.Arguments); err != nil {
+		return nil, err
 	}
 
-	return u.Session.GetPrompt(ctx, &mcp.GetPromptParams{
-		Name:      actualPromptName,
-		Arguments: getReq.Params.Arguments,
-	})
+	params, done := h.getPromptParams(ctx, getReq, serverID, actualPromptName)
+	defer done()
+	return u.Session().GetPrompt(ctx, params)
 }
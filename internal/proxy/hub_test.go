@@ -0,0 +1,638 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/ain3sh/mcp2/internal/requestid"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newTestUpstream starts an in-memory MCP server exposing the given tools
+// and returns an upstream connected to it.
+func newTestUpstream(t *testing.T, id string, tools ...string) *upstream.Upstream {
+	t.Helper()
+	return mcp2test.NewUpstream(t, mcp2test.Upstream{ID: id, DisplayName: id, Tools: mcp2test.Tools(tools...)})
+}
+
+// connectTestClient connects an in-memory client to the given server.
+func connectTestClient(t *testing.T, server *mcp.Server) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "downstream", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+
+	return session
+}
+
+func newTestManager(t *testing.T, upstreams ...*upstream.Upstream) *upstream.Manager {
+	t.Helper()
+
+	manager := upstream.NewManager()
+	for _, u := range upstreams {
+		if err := manager.Add(u); err != nil {
+			t.Fatalf("Failed to add upstream: %v", err)
+		}
+	}
+	return manager
+}
+
+func TestHub_WarnsWhenListedToolDeniedAtCallTime(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file"))
+	hub := NewHub(cfg, manager, "test")
+	hub.SetDebug(true)
+
+	var buf bytes.Buffer
+	origOutput := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(origOutput)
+
+	ctx := context.Background()
+	if _, err := hub.handleToolsList(ctx); err != nil {
+		t.Fatalf("handleToolsList failed: %v", err)
+	}
+
+	// Simulate the profile changing between list and call.
+	cfg.Profiles["test"].Servers["server1"] = config.ServerProfileConfig{
+		Tools: config.ComponentFilter{Deny: []string{"read_file"}},
+	}
+
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "read_file"}}
+	if _, err := hub.handleToolsCall(ctx, req); err == nil {
+		t.Fatal("Expected call to be denied")
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "tool=read_file") || !strings.Contains(out, "server=server1") || !strings.Contains(out, "profile=test") {
+		t.Errorf("Expected warning with server/tool/profile, got %q", out)
+	}
+}
+
+func TestHub_DeniedErrorIncludesReason(t *testing.T) {
+	const reason = "Deletion tools are disabled in the safe profile; switch profiles to enable."
+	filter := config.ComponentFilter{
+		Deny:        []string{"delete_*"},
+		DenyReasons: map[string]string{"delete_*": reason},
+	}
+
+	for _, prefix := range []bool{true, false} {
+		cfg := &config.RootConfig{
+			Profiles: map[string]config.ProfileConfig{
+				"safe": {
+					Servers: map[string]config.ServerProfileConfig{
+						"server1": {Tools: filter},
+					},
+				},
+			},
+			Hub: config.HubConfig{PrefixServerIDs: prefix},
+		}
+		manager := newTestManager(t, newTestUpstream(t, "server1", "delete_file"))
+		hub := NewHub(cfg, manager, "safe")
+
+		name := "delete_file"
+		if prefix {
+			name = "server1:delete_file"
+		}
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name}}
+		_, err := hub.handleToolsCall(context.Background(), req)
+		if err == nil || !strings.Contains(err.Error(), reason) {
+			t.Errorf("prefix=%v: handleToolsCall error = %v, want it to include the deny reason", prefix, err)
+		}
+	}
+}
+
+func TestHub_ListEnforcedOnlySkipsCallCheck(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"trusted": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {Tools: config.ComponentFilter{Deny: []string{"delete_*"}}},
+				},
+				ListEnforcedOnly: true,
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+	manager := newTestManager(t,
+		newTestUpstream(t, "server1", "read_file", "delete_file"),
+		newTestUpstream(t, "server2", "other"))
+	hub := NewHub(cfg, manager, "trusted")
+	ctx := context.Background()
+
+	result, err := hub.handleToolsList(ctx)
+	if err != nil {
+		t.Fatalf("handleToolsList failed: %v", err)
+	}
+	if tools := result.(*mcp.ListToolsResult).Tools; len(tools) != 1 || tools[0].Name != "server1:read_file" {
+		t.Errorf("listed tools = %v, want only server1:read_file", tools)
+	}
+
+	// The unlisted tool can be called since the call-time check is skipped
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "server1:delete_file"}}
+	if _, err := hub.handleToolsCall(ctx, req); err != nil {
+		t.Errorf("handleToolsCall(server1:delete_file) failed: %v", err)
+	}
+
+	// Servers outside the profile are still refused
+	req = &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "server2:other"}}
+	if _, err := hub.handleToolsCall(ctx, req); err == nil {
+		t.Error("handleToolsCall(server2:other) succeeded, want server not in profile")
+	}
+}
+
+func TestHub_NoWarningForUnlistedTool(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {
+						Tools: config.ComponentFilter{Deny: []string{"write_file"}},
+					},
+				},
+			},
+		},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file", "write_file"))
+	hub := NewHub(cfg, manager, "test")
+	hub.SetDebug(true)
+
+	var buf bytes.Buffer
+	origOutput := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(origOutput)
+
+	ctx := context.Background()
+	if _, err := hub.handleToolsList(ctx); err != nil {
+		t.Fatalf("handleToolsList failed: %v", err)
+	}
+
+	req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: "write_file"}}
+	if _, err := hub.handleToolsCall(ctx, req); err == nil {
+		t.Fatal("Expected call to be denied")
+	}
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no warning for unlisted tool, got %q", buf.String())
+	}
+}
+
+func TestHub_LogsRequests(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file"))
+	hub := NewHub(cfg, manager, "test")
+
+	var buf bytes.Buffer
+	origOutput := log.Writer()
+	log.SetOutput(&buf)
+	defer log.SetOutput(origOutput)
+
+	session := connectTestClient(t, hub.Server())
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "server1:read_file"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "method=tools/call") || !strings.Contains(out, "server=server1") || !strings.Contains(out, "profile=test") {
+		t.Errorf("Expected request log with method/server/profile, got %q", out)
+	}
+	if !strings.Contains(out, "requestId=") {
+		t.Errorf("Expected request log with requestId, got %q", out)
+	}
+}
+
+func TestHub_PropagatesRequestIDInMeta(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	var gotID any
+	server := mcp.NewServer(&mcp.Implementation{Name: "server1", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "read_file"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		gotID = req.Params.GetMeta()[requestid.MetaKey]
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	upstreamSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { upstreamSession.Close() })
+
+	manager := newTestManager(t, upstream.NewUpstream("server1", upstreamSession))
+	hub := NewHub(cfg, manager, "test")
+
+	session := connectTestClient(t, hub.Server())
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1:read_file"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	if id, ok := gotID.(string); !ok || id == "" {
+		t.Errorf("Expected upstream to receive a request ID in _meta, got %v", gotID)
+	}
+}
+
+func TestHub_ForwardsClientInfo(t *testing.T) {
+	for _, forward := range []bool{false, true} {
+		cfg := &config.RootConfig{
+			Profiles: map[string]config.ProfileConfig{
+				"test": {
+					Servers: map[string]config.ServerProfileConfig{
+						"server1": {},
+					},
+				},
+			},
+			Hub: config.HubConfig{PrefixServerIDs: true, ForwardClientInfo: forward},
+		}
+
+		ctx := context.Background()
+		clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+		var gotClient any
+		server := mcp.NewServer(&mcp.Implementation{Name: "server1", Version: "1.0.0"}, nil)
+		mcp.AddTool(server, &mcp.Tool{Name: "read_file"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			gotClient = req.Params.GetMeta()[upstream.ClientInfoMetaKey]
+			return &mcp.CallToolResult{}, nil, nil
+		})
+		serverSession, err := server.Connect(ctx, serverTransport, nil)
+		if err != nil {
+			t.Fatalf("Failed to start test server: %v", err)
+		}
+		t.Cleanup(func() { serverSession.Close() })
+
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+		upstreamSession, err := client.Connect(ctx, clientTransport, nil)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		t.Cleanup(func() { upstreamSession.Close() })
+
+		manager := newTestManager(t, upstream.NewUpstream("server1", upstreamSession))
+		hub := NewHub(cfg, manager, "test")
+
+		session := connectTestClient(t, hub.Server())
+		if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1:read_file"}); err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+
+		if !forward {
+			if gotClient != nil {
+				t.Errorf("Expected no client info with forwardClientInfo off, got %v", gotClient)
+			}
+			continue
+		}
+		want := "mcp2-proxy (on behalf of downstream/1.0.0)"
+		if gotClient != want {
+			t.Errorf("client info = %v, want %q", gotClient, want)
+		}
+	}
+}
+
+func TestHub_SetLogger(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file"))
+	hub := NewHub(cfg, manager, "test")
+
+	var buf bytes.Buffer
+	hub.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	var global bytes.Buffer
+	origOutput := log.Writer()
+	log.SetOutput(&global)
+	defer log.SetOutput(origOutput)
+
+	session := connectTestClient(t, hub.Server())
+	if _, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "server1:read_file"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "method=tools/call") {
+		t.Errorf("Expected request log on injected logger, got %q", buf.String())
+	}
+	if global.Len() != 0 {
+		t.Errorf("Expected nothing on the global logger, got %q", global.String())
+	}
+}
+
+func TestHub_ProfilePrefixSettings(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"dev": {
+				Servers:         map[string]config.ServerProfileConfig{"server1": {}},
+				PrefixSeparator: "__",
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read_file"))
+	session := connectTestClient(t, NewHub(cfg, manager, "dev").Server())
+
+	ctx := context.Background()
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "server1__read_file" {
+		t.Fatalf("Expected server1__read_file, got %v", tools.Tools)
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1__read_file"}); err != nil {
+		t.Errorf("CallTool failed: %v", err)
+	}
+
+	bare := false
+	profile := cfg.Profiles["dev"]
+	profile.PrefixServerIDs = &bare
+	cfg.Profiles["dev"] = profile
+
+	session = connectTestClient(t, NewHub(cfg, manager, "dev").Server())
+	tools, err = session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "read_file" {
+		t.Errorf("Expected unprefixed read_file, got %v", tools.Tools)
+	}
+}
+
+func TestHub_ComponentPrefixes(t *testing.T) {
+	no := false
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{"docs": {}, "files": {}}},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, Prefix: config.PrefixComponentsConfig{Resources: &no}},
+	}
+	manager := mcp2test.NewManager(t,
+		mcp2test.Upstream{ID: "docs", Tools: mcp2test.Tools("search"), Resources: mcp2test.Resources("docs://index")},
+		mcp2test.Upstream{ID: "files", Prompts: mcp2test.Prompts("summarize"), Resources: mcp2test.Resources("file:///readme")},
+	)
+	hub := NewHub(cfg, manager, "test")
+
+	mcp2test.AssertSurface(t, hub.Server(), mcp2test.Surface{
+		Tools:     []string{"docs:search"},
+		Resources: []string{"docs://index", "file:///readme"},
+		Prompts:   []string{"files:summarize"},
+	})
+
+	ctx := context.Background()
+	session := connectTestClient(t, hub.Server())
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "file:///readme"})
+	if err != nil {
+		t.Fatalf("ReadResource failed: %v", err)
+	}
+	if len(result.Contents) != 1 || result.Contents[0].Text != "files" {
+		t.Errorf("ReadResource read %v, want the files server's resource", result.Contents)
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "docs:search"}); err != nil {
+		t.Errorf("CallTool(docs:search) failed: %v", err)
+	}
+	if _, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: "files:summarize"}); err != nil {
+		t.Errorf("GetPrompt(files:summarize) failed: %v", err)
+	}
+}
+
+func TestHub_ToolNamesContainingSeparator(t *testing.T) {
+	tests := []struct {
+		name      string
+		separator string
+		prefix    bool
+		tool      string
+		exposed   string
+	}{
+		{"colon in tool name", "", true, "a:b", "server1:a:b"},
+		{"several colons", "", true, "git:hub:create", "server1:git:hub:create"},
+		{"alternate separator in tool name", "__", true, "x__y", "server1__x__y"},
+		{"colon with alternate separator", "__", true, "a:b", "server1__a:b"},
+		{"colon without prefixing", "", false, "github:create", "github:create"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.RootConfig{
+				Profiles: map[string]config.ProfileConfig{
+					"test": {
+						Servers: map[string]config.ServerProfileConfig{
+							"server1": {},
+						},
+					},
+				},
+				Hub: config.HubConfig{PrefixServerIDs: tt.prefix, PrefixSeparator: tt.separator},
+			}
+
+			manager := newTestManager(t, newTestUpstream(t, "server1", tt.tool))
+			session := connectTestClient(t, NewHub(cfg, manager, "test").Server())
+
+			ctx := context.Background()
+			tools, err := session.ListTools(ctx, nil)
+			if err != nil {
+				t.Fatalf("ListTools failed: %v", err)
+			}
+			if len(tools.Tools) != 1 || tools.Tools[0].Name != tt.exposed {
+				t.Fatalf("Expected %s, got %v", tt.exposed, tools.Tools)
+			}
+
+			result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: tt.exposed})
+			if err != nil {
+				t.Fatalf("CallTool failed: %v", err)
+			}
+			want := "server1:" + tt.tool
+			if text := result.Content[0].(*mcp.TextContent).Text; text != want {
+				t.Errorf("Called %q, want %q", text, want)
+			}
+		})
+	}
+}
+
+func TestHub_SkipsListsForUnadvertisedCapabilities(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+	}
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	var methods []string
+	server := mcp.NewServer(&mcp.Implementation{Name: "server1", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "read_file"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			methods = append(methods, method)
+			return next(ctx, method, req)
+		}
+	})
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start test server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	upstreamSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { upstreamSession.Close() })
+
+	manager := newTestManager(t, upstream.NewUpstream("server1", upstreamSession))
+	hub := NewHub(cfg, manager, "test")
+
+	if _, err := hub.handleToolsList(ctx); err != nil {
+		t.Fatalf("handleToolsList failed: %v", err)
+	}
+	if _, err := hub.handleResourcesList(ctx); err != nil {
+		t.Fatalf("handleResourcesList failed: %v", err)
+	}
+	if _, err := hub.handlePromptsList(ctx); err != nil {
+		t.Fatalf("handlePromptsList failed: %v", err)
+	}
+
+	got := strings.Join(methods, ",")
+	if strings.Contains(got, "resources/list") || strings.Contains(got, "prompts/list") {
+		t.Errorf("Expected unadvertised lists to be skipped, upstream received %s", got)
+	}
+	if !strings.Contains(got, "tools/list") {
+		t.Errorf("Expected tools/list to reach the upstream, got %s", got)
+	}
+}
+
+func TestHub_WarnsOnceForLongToolNames(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, MaxNameLength: 16},
+	}
+
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read", "read_long_file"))
+	hub := NewHub(cfg, manager, "test")
+
+	var buf bytes.Buffer
+	hub.SetLogger(slog.New(slog.NewTextHandler(&buf, nil)))
+
+	for i := 0; i < 2; i++ {
+		if _, err := hub.handleToolsList(context.Background()); err != nil {
+			t.Fatalf("handleToolsList failed: %v", err)
+		}
+	}
+
+	if got := strings.Count(buf.String(), "over maxNameLength"); got != 1 {
+		t.Errorf("logged %d warnings, want 1: %q", got, buf.String())
+	}
+	if !strings.Contains(buf.String(), "tool=server1:read_long_file") {
+		t.Errorf("Expected warning for server1:read_long_file, got %q", buf.String())
+	}
+}
+
+func TestHub_DisplayNameInTitles(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"srv1": {},
+					"srv2": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, DisplayNameInTitles: true},
+	}
+
+	fs := newTestUpstream(t, "srv1", "search")
+	fs.DisplayName = "Filesystem"
+	bare := newTestUpstream(t, "srv2", "fetch")
+	bare.DisplayName = ""
+	hub := NewHub(cfg, newTestManager(t, fs, bare), "test")
+
+	tools, err := connectTestClient(t, hub.Server()).ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	titles := make(map[string]string)
+	for _, tool := range tools.Tools {
+		titles[tool.Name] = tool.Title
+	}
+	if got := titles["srv1:search"]; got != "search (Filesystem)" {
+		t.Errorf("srv1:search title = %q, want %q", got, "search (Filesystem)")
+	}
+	if got, ok := titles["srv2:fetch"]; !ok || got != "" {
+		t.Errorf("srv2:fetch title = %q (listed %v), want it listed without a title", got, ok)
+	}
+}
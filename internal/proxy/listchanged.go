@@ -0,0 +1,56 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// The SDK only sends list_changed notifications when features are added to
+// or removed from a server, so the hub re-registers a placeholder tool,
+// resource, or prompt to notify its clients. The hub's own list and call
+// handlers intercept those methods, so placeholders are never exposed.
+const (
+	placeholderName = "mcp2-list-changed"
+	placeholderURI  = "mcp2://list-changed"
+)
+
+var errPlaceholder = errors.New("not an upstream feature")
+
+// forwardListChanged tells clients that an upstream in any of the hub's
+// profiles changed its tools, resources, or prompts, so they list them
+// again. Notifications go to every session, so a client may re-list
+// needlessly, but never misses a change.
+func (h *Hub) forwardListChanged(serverID string, kind upstream.ListKind) {
+	serverID = h.logicalID(serverID)
+	if !h.anyViewHasServer(serverID) {
+		return
+	}
+
+	switch kind {
+	case upstream.ListTools:
+		h.server.AddTool(&mcp.Tool{
+			Name:        placeholderName,
+			InputSchema: &jsonschema.Schema{Type: "object"},
+		}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return nil, errPlaceholder
+		})
+	case upstream.ListResources:
+		h.server.AddResource(&mcp.Resource{
+			Name: placeholderName,
+			URI:  placeholderURI,
+		}, func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+			return nil, errPlaceholder
+		})
+	case upstream.ListPrompts:
+		h.server.AddPrompt(&mcp.Prompt{
+			Name: placeholderName,
+		}, func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			return nil, errPlaceholder
+		})
+	}
+	h.logger().Debug("forwarded list change", "server", serverID, "list", kind)
+}
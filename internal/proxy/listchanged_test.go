@@ -0,0 +1,66 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_ForwardsToolListChanged(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	ctx := context.Background()
+
+	var upstreamServer *mcp.Server
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{
+		ID:    "server1",
+		Tools: mcp2test.Tools("read_file"),
+		Setup: func(server *mcp.Server) { upstreamServer = server },
+	})
+	hub := NewHub(cfg, manager, "test")
+
+	changed := make(chan struct{}, 1)
+	session := mcp2test.ConnectWith(t, hub.Server(), &mcp.ClientOptions{
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		},
+	})
+
+	upstreamServer.AddTool(mcp2test.Tools("write_file")[0], func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+
+	select {
+	case <-changed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected tools/list_changed to be forwarded")
+	}
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+	if len(names) != 2 || names[0] != "server1:read_file" || names[1] != "server1:write_file" {
+		t.Errorf("Expected both upstream tools and no placeholder, got %v", names)
+	}
+}
@@ -0,0 +1,49 @@
+package proxy
+
+import (
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// fitListBytes enforces hub.maxListBytes on an aggregated list. If result,
+// built from items, marshals to more bytes than the limit, the entries of
+// the lowest-priority server (the last in serverOrder order) are dropped,
+// then the next, until it fits. owners[i] is the server items[i] came from,
+// and each server's entries are contiguous. It returns the entries kept and
+// the IDs of the servers dropped.
+func fitListBytes[T any](h *Hub, kind string, items []*T, owners []string, result func([]*T) mcp.Result) ([]*T, []string) {
+	limit := h.config.Hub.MaxListBytes
+	if limit <= 0 || len(items) == 0 {
+		return items, nil
+	}
+
+	size, err := marshalledSize(result(items))
+	if err != nil || size <= limit {
+		return items, nil
+	}
+	listedSize := size
+
+	var dropped []string
+	for size > limit && len(items) > 0 {
+		last := owners[len(items)-1]
+		i := len(items) - 1
+		for i > 0 && owners[i-1] == last {
+			i--
+		}
+		items = items[:i]
+		dropped = append(dropped, last)
+		if size, err = marshalledSize(result(items)); err != nil {
+			break
+		}
+	}
+
+	h.logger().Warn(kind+" list over hub maxListBytes; servers' entries not listed",
+		"maxListBytes", limit, "bytes", listedSize, "servers", dropped)
+	return items, dropped
+}
+
+func marshalledSize(v any) (int, error) {
+	data, err := json.Marshal(v)
+	return len(data), err
+}
@@ -0,0 +1,78 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_MaxListBytes(t *testing.T) {
+	big := mcp2test.Upstream{
+		ID:      "big",
+		Tools:   []*mcp.Tool{{Name: "report", Description: strings.Repeat("x", 4000)}},
+		Prompts: []*mcp.Prompt{{Name: "summary", Description: strings.Repeat("x", 4000)}},
+	}
+	small := mcp2test.Upstream{
+		ID:      "small",
+		Tools:   mcp2test.Tools("ping"),
+		Prompts: mcp2test.Prompts("hello"),
+	}
+
+	tests := []struct {
+		name        string
+		serverOrder []string
+		limit       int
+		want        mcp2test.Surface
+	}{
+		{
+			name:  "no limit",
+			limit: 0,
+			want: mcp2test.Surface{
+				Tools:   []string{"big:report", "small:ping"},
+				Prompts: []string{"big:summary", "small:hello"},
+			},
+		},
+		{
+			name:        "under limit",
+			serverOrder: []string{"small", "big"},
+			limit:       1 << 20,
+			want: mcp2test.Surface{
+				Tools:   []string{"big:report", "small:ping"},
+				Prompts: []string{"big:summary", "small:hello"},
+			},
+		},
+		{
+			name:        "drops lowest priority server",
+			serverOrder: []string{"small", "big"},
+			limit:       1000,
+			want: mcp2test.Surface{
+				Tools:   []string{"small:ping"},
+				Prompts: []string{"small:hello"},
+			},
+		},
+		{
+			name:        "drops until it fits",
+			serverOrder: []string{"big", "small"},
+			limit:       1000,
+			want:        mcp2test.Surface{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.RootConfig{
+				Profiles: map[string]config.ProfileConfig{
+					"test": {
+						Servers: map[string]config.ServerProfileConfig{"big": {}, "small": {}},
+					},
+				},
+				Hub: config.HubConfig{PrefixServerIDs: true, ServerOrder: tt.serverOrder, MaxListBytes: tt.limit},
+			}
+			hub := NewHub(cfg, mcp2test.NewManager(t, big, small), "test")
+			mcp2test.AssertSurface(t, hub.Server(), tt.want)
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package proxy
+
+import (
+	"context"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ServerMetaKey is the _meta key naming the upstream server a relayed log
+// message came from.
+const ServerMetaKey = "mcp2/server"
+
+// logRelayTimeout bounds how long relaying a log message waits on one
+// client, and how long an upstream gets to accept logging/setLevel.
+const logRelayTimeout = 5 * time.Second
+
+// maxQueuedLogs bounds the log messages waiting to be relayed. Messages
+// arriving while the queue is full are dropped.
+const maxQueuedLogs = 256
+
+// registerLogRelay forwards logging/setLevel to upstreams so they start
+// emitting log messages, which relayLog then passes on to clients.
+func (h *Hub) registerLogRelay() {
+	h.manager.OnLog(h.relayLog)
+	h.manager.OnConnect(h.resetUpstreamLogging)
+	h.server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			result, err := next(ctx, method, req)
+			if method == "logging/setLevel" && err == nil {
+				h.enableUpstreamLogging(ctx)
+			}
+			return result, err
+		}
+	})
+}
+
+// enableUpstreamLogging asks every upstream that supports logging to send
+// all messages. Each client's own level is applied when relaying, so
+// upstreams are set to debug once rather than to the last client's level.
+func (h *Hub) enableUpstreamLogging(ctx context.Context) {
+	h.logLevelsMu.Lock()
+	h.logRequested = true
+	h.logLevelsMu.Unlock()
+
+	for _, u := range h.manager.List() {
+		h.enableLogging(ctx, u)
+	}
+}
+
+// resetUpstreamLogging runs when an upstream connects or reconnects. The
+// new session starts at the server's own level, so serverID is asked again
+// to send all messages if a client has already set a level.
+func (h *Hub) resetUpstreamLogging(serverID string) {
+	h.logLevelsMu.Lock()
+	delete(h.logLevelsSet, serverID)
+	requested := h.logRequested
+	h.logLevelsMu.Unlock()
+	if !requested {
+		return
+	}
+
+	u, err := h.manager.Get(serverID)
+	if err != nil {
+		return
+	}
+	h.enableLogging(context.Background(), u)
+}
+
+// enableLogging sets u's logging level to debug unless it has been already
+// or doesn't support logging.
+func (h *Hub) enableLogging(ctx context.Context, u *upstream.Upstream) {
+	if caps := u.Capabilities(); caps != nil && caps.Logging == nil {
+		return
+	}
+
+	h.logLevelsMu.Lock()
+	done := h.logLevelsSet[u.ID]
+	h.logLevelsSet[u.ID] = true
+	h.logLevelsMu.Unlock()
+	if done {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, logRelayTimeout)
+	defer cancel()
+	if err := u.Session().SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "debug"}); err != nil {
+		h.logger().Debug("failed to enable upstream logging", "server", u.ID, "error", err)
+		h.logLevelsMu.Lock()
+		delete(h.logLevelsSet, u.ID)
+		h.logLevelsMu.Unlock()
+	}
+}
+
+// relayLog queues an upstream log message for every connected client,
+// tagged with the server it came from. It runs on the upstream's
+// notification path, so the messages are sent by sendLogs rather than
+// here. Sessions aren't tied to a profile, so only servers in the hub's
+// default profile are relayed.
+func (h *Hub) relayLog(serverID string, params *mcp.LoggingMessageParams) {
+	serverID = h.logicalID(serverID)
+	if !h.views[h.profileName].engine.HasServer(serverID) {
+		return
+	}
+
+	logger := serverID
+	if params.Logger != "" {
+		logger = serverID + "/" + params.Logger
+	}
+	relayed := &mcp.LoggingMessageParams{
+		Meta:   mcp.Meta{ServerMetaKey: serverID},
+		Data:   params.Data,
+		Level:  params.Level,
+		Logger: logger,
+	}
+
+	h.logQueueMu.Lock()
+	defer h.logQueueMu.Unlock()
+	if len(h.logQueue) >= maxQueuedLogs {
+		h.logger().Debug("dropped log message, relay queue is full", "server", serverID)
+		return
+	}
+	h.logQueue = append(h.logQueue, relayed)
+	if !h.logRelaying {
+		h.logRelaying = true
+		go h.sendLogs()
+	}
+}
+
+// sendLogs sends queued log messages in order until the queue is empty,
+// giving each client up to logRelayTimeout per message. ServerSession.Log
+// drops messages below the level each client asked for.
+func (h *Hub) sendLogs() {
+	for {
+		h.logQueueMu.Lock()
+		if len(h.logQueue) == 0 {
+			h.logRelaying = false
+			h.logQueueMu.Unlock()
+			return
+		}
+		params := h.logQueue[0]
+		h.logQueue = h.logQueue[1:]
+		h.logQueueMu.Unlock()
+
+		for ss := range h.server.Sessions() {
+			ctx, cancel := context.WithTimeout(context.Background(), logRelayTimeout)
+			err := ss.Log(ctx, params)
+			cancel()
+			if err != nil {
+				h.logger().Debug("failed to relay log message", "server", params.Meta[ServerMetaKey], "error", err)
+			}
+		}
+	}
+}
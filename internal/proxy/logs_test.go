@@ -0,0 +1,122 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_RelaysLogMessages(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, RelayLogs: true},
+	}
+
+	ctx := context.Background()
+
+	// The tool waits for its log message to reach the downstream client so
+	// the message is not racing the result.
+	logs := make(chan *mcp.LoggingMessageParams, 2)
+	logSeen := make(chan struct{})
+
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: "noisy"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "info", Data: "chatter"})
+			req.Session.Log(ctx, &mcp.LoggingMessageParams{Level: "error", Logger: "db", Data: "connection lost"})
+			select {
+			case <-logSeen:
+			case <-time.After(time.Second):
+			}
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	}})
+	hub := NewHub(cfg, manager, "test")
+
+	session := mcp2test.ConnectWith(t, hub.Server(), &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			logs <- req.Params
+			close(logSeen)
+		},
+	})
+
+	if err := session.SetLoggingLevel(ctx, &mcp.SetLoggingLevelParams{Level: "warning"}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "server1:noisy"}); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	select {
+	case l := <-logs:
+		if l.Level != "error" || l.Logger != "server1/db" || l.Data != "connection lost" {
+			t.Errorf("Unexpected log message: %+v", l)
+		}
+		if l.Meta[ServerMetaKey] != "server1" {
+			t.Errorf("Expected log tagged with server1, got meta %v", l.Meta)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected log message to be relayed")
+	}
+}
+
+func TestHub_ReenablesUpstreamLoggingOnReconnect(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{"server1": {}}},
+		},
+		Hub: config.HubConfig{RelayLogs: true},
+	}
+
+	setLevels := make(chan mcp.LoggingLevel, 4)
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+		server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+			return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+				if method == "logging/setLevel" {
+					setLevels <- req.GetParams().(*mcp.SetLoggingLevelParams).Level
+				}
+				return next(ctx, method, req)
+			}
+		})
+	}})
+	hub := NewHub(cfg, manager, "test")
+
+	expectSetLevel := func(when string) {
+		t.Helper()
+		select {
+		case level := <-setLevels:
+			if level != "debug" {
+				t.Errorf("Upstream level set to %q %s, want debug", level, when)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the upstream's level to be set %s", when)
+		}
+	}
+
+	// Before any client sets a level, a reconnect leaves logging alone
+	hub.resetUpstreamLogging("server1")
+	session := mcp2test.Connect(t, hub.Server())
+	if err := session.SetLoggingLevel(context.Background(), &mcp.SetLoggingLevelParams{Level: "info"}); err != nil {
+		t.Fatalf("SetLoggingLevel failed: %v", err)
+	}
+	expectSetLevel("after the client set a level")
+
+	// A new session starts at the server's own level
+	hub.resetUpstreamLogging("server1")
+	expectSetLevel("after a reconnect")
+
+	select {
+	case level := <-setLevels:
+		t.Errorf("Unexpected extra setLevel %q", level)
+	default:
+	}
+}
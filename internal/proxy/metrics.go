@@ -0,0 +1,108 @@
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ain3sh/mcp2/internal/upstream"
+)
+
+// MetricsHandler serves the counters of StatusHandler in the Prometheus
+// text exposition format, for scrapers that can't read /statusz: whether
+// every upstream is connected, its requests in flight, and its restarts,
+// labeled by server; the hits, misses, evictions, and size of its
+// definition caches, labeled by server and cache; and the decision counts
+// of endpoints, labeled by endpoint, profile, server, component, and
+// decision. As with StatusHandler, endpoints must not change once the
+// handler serves.
+func MetricsHandler(manager *upstream.Manager, endpoints map[string]DecisionCounter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		statuses := manager.Statuses()
+
+		var m metricsWriter
+		for _, metric := range []struct {
+			name, kind, help string
+			value            func(upstream.Status) uint64
+		}{
+			{"mcp2_upstream_connected", "gauge", "Whether the server's session is connected (1) or not (0).", func(s upstream.Status) uint64 { return boolValue(s.Connected) }},
+			{"mcp2_upstream_in_flight_requests", "gauge", "Requests awaiting the server's response, including queued ones.", func(s upstream.Status) uint64 { return uint64(s.InFlight) }},
+			{"mcp2_upstream_restarts_total", "counter", "Times the server was reconnected.", func(s upstream.Status) uint64 { return uint64(s.Restarts) }},
+		} {
+			m.header(metric.name, metric.kind, metric.help)
+			for _, s := range statuses {
+				m.sample(metric.name, metric.value(s), "server", s.ID)
+			}
+		}
+		for _, metric := range []struct {
+			name, kind, help string
+			value            func(upstream.CacheStats) uint64
+		}{
+			{"mcp2_cache_hits_total", "counter", "Definition lookups answered from the cache.", func(c upstream.CacheStats) uint64 { return c.Hits }},
+			{"mcp2_cache_misses_total", "counter", "Definition lookups that had to list the server.", func(c upstream.CacheStats) uint64 { return c.Misses }},
+			{"mcp2_cache_evictions_total", "counter", "Cached definitions dropped because the server's list changed or it reconnected.", func(c upstream.CacheStats) uint64 { return c.Evictions }},
+			{"mcp2_cache_entries", "gauge", "Definitions cached now.", func(c upstream.CacheStats) uint64 { return uint64(c.Size) }},
+		} {
+			m.header(metric.name, metric.kind, metric.help)
+			for _, s := range statuses {
+				m.sample(metric.name, metric.value(s.Caches.Tools), "server", s.ID, "cache", "tools")
+				m.sample(metric.name, metric.value(s.Caches.Resources), "server", s.ID, "cache", "resources")
+			}
+		}
+
+		names := make([]string, 0, len(endpoints))
+		for endpoint := range endpoints {
+			names = append(names, endpoint)
+		}
+		sort.Strings(names)
+		m.header("mcp2_profile_decisions_total", "counter", "Profile decisions on a server's tools, resources, and prompts.")
+		for _, endpoint := range names {
+			for _, c := range endpoints[endpoint].DecisionCounts() {
+				labels := []string{"endpoint", endpoint, "profile", c.Profile, "server", c.Server, "component", string(c.Component)}
+				m.sample("mcp2_profile_decisions_total", c.Allowed, append(labels, "decision", "allowed")...)
+				m.sample("mcp2_profile_decisions_total", c.Denied, append(labels, "decision", "denied")...)
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		io.WriteString(w, m.String())
+	})
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// labelValueEscaper escapes a label value as the text format requires.
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// metricsWriter builds a Prometheus text exposition.
+type metricsWriter struct {
+	strings.Builder
+}
+
+func (m *metricsWriter) header(name, kind, help string) {
+	fmt.Fprintf(m, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// sample writes one sample of name with the given label name and value
+// pairs.
+func (m *metricsWriter) sample(name string, value uint64, labels ...string) {
+	m.WriteString(name)
+	for i := 0; i+1 < len(labels); i += 2 {
+		sep := ","
+		if i == 0 {
+			sep = "{"
+		}
+		fmt.Fprintf(m, "%s%s=\"%s\"", sep, labels[i], labelValueEscaper.Replace(labels[i+1]))
+	}
+	if len(labels) > 0 {
+		m.WriteString("}")
+	}
+	fmt.Fprintf(m, " %d\n", value)
+}
@@ -0,0 +1,55 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestMetricsHandler_ExposesCounters(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{"server1": {}}},
+		},
+		Hub: config.HubConfig{SafeMode: true},
+	}
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read"))
+	hub := NewHub(cfg, manager, "test")
+	session := connectTestClient(t, hub.Server())
+
+	// Safe mode looks up each called tool's annotations in the cache
+	for range 2 {
+		session.CallTool(context.Background(), &mcp.CallToolParams{Name: "read"})
+	}
+
+	rec := httptest.NewRecorder()
+	MetricsHandler(manager, map[string]DecisionCounter{"/mcp": hub}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q, want the Prometheus text format", ct)
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE mcp2_upstream_connected gauge\n",
+		`mcp2_upstream_connected{server="server1"} 1` + "\n",
+		`mcp2_upstream_in_flight_requests{server="server1"} 0` + "\n",
+		"# TYPE mcp2_upstream_restarts_total counter\n",
+		`mcp2_upstream_restarts_total{server="server1"} 0` + "\n",
+		"# TYPE mcp2_cache_hits_total counter\n",
+		`mcp2_cache_hits_total{server="server1",cache="tools"} 1` + "\n",
+		`mcp2_cache_misses_total{server="server1",cache="tools"} 1` + "\n",
+		`mcp2_cache_entries{server="server1",cache="tools"} 1` + "\n",
+		`mcp2_cache_hits_total{server="server1",cache="resources"} 0` + "\n",
+		// Safe mode denied both calls, since read isn't annotated read-only
+		`mcp2_profile_decisions_total{endpoint="/mcp",profile="test",server="server1",component="tool",decision="denied"} 2` + "\n",
+		`mcp2_profile_decisions_total{endpoint="/mcp",profile="test",server="server1",component="tool",decision="allowed"} 0` + "\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Metrics missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package proxy
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// orderedUpstreams returns the upstreams, with each replica group in place
+// of its members, in hub.serverOrder order, followed by unlisted servers
+// alphabetically.
+func (h *Hub) orderedUpstreams(ctx context.Context) []*upstream.Upstream {
+	rank := make(map[string]int, len(h.config.Hub.ServerOrder))
+	for i, serverID := range h.config.Hub.ServerOrder {
+		if _, seen := rank[serverID]; !seen {
+			rank[serverID] = i
+		}
+	}
+
+	upstreams := h.logicalUpstreams(ctx)
+	sort.SliceStable(upstreams, func(i, j int) bool {
+		ri, iok := rank[upstreams[i].ID]
+		rj, jok := rank[upstreams[j].ID]
+		if iok != jok {
+			return iok
+		}
+		if iok && ri != rj {
+			return ri < rj
+		}
+		return upstreams[i].ID < upstreams[j].ID
+	})
+	return upstreams
+}
+
+// sortTools orders a server's tools by the first toolPriority pattern they
+// match, then alphabetically.
+func sortTools(u *upstream.Upstream, tools []*mcp.Tool) {
+	var priority []string
+	if u.Config != nil {
+		priority = u.Config.ToolPriority
+	}
+
+	rank := func(name string) int {
+		for i, pattern := range priority {
+			if profile.Match(name, pattern) {
+				return i
+			}
+		}
+		return len(priority)
+	}
+	sort.SliceStable(tools, func(i, j int) bool {
+		ri, rj := rank(tools[i].Name), rank(tools[j].Name)
+		if ri != rj {
+			return ri < rj
+		}
+		return tools[i].Name < tools[j].Name
+	})
+}
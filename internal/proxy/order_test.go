@@ -0,0 +1,84 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+func TestHub_ToolOrdering(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"alpha": {}, "beta": {}, "gamma": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, ServerOrder: []string{"gamma"}},
+	}
+
+	beta := newTestUpstream(t, "beta", "delete_file", "read_file", "write_file", "write_dir")
+	beta.Config = &config.ServerConfig{ToolPriority: []string{"read_file", "write_*"}}
+	manager := newTestManager(t,
+		newTestUpstream(t, "alpha", "search"),
+		beta,
+		newTestUpstream(t, "gamma", "zeta", "eta"),
+	)
+	hub := NewHub(cfg, manager, "test")
+
+	tools, err := connectTestClient(t, hub.Server()).ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+
+	want := []string{
+		"gamma:eta", "gamma:zeta",
+		"alpha:search",
+		"beta:read_file", "beta:write_dir", "beta:write_file", "beta:delete_file",
+	}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Tools = %v, want %v", names, want)
+	}
+}
+
+func TestHub_MaxTools(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"alpha": {Tools: config.ComponentFilter{Deny: []string{"a1"}}},
+					"beta":  {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, MaxTools: 3},
+	}
+
+	alpha := newTestUpstream(t, "alpha", "a1", "a2", "a3", "a4")
+	alpha.Config = &config.ServerConfig{MaxTools: 2}
+	manager := newTestManager(t, alpha, newTestUpstream(t, "beta", "b1", "b2"))
+	hub := NewHub(cfg, manager, "test")
+
+	tools, err := connectTestClient(t, hub.Server()).ListTools(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	var names []string
+	for _, tool := range tools.Tools {
+		names = append(names, tool.Name)
+	}
+
+	// a1 is denied before the per-server cap applies, then the hub cap
+	// keeps the first three overall
+	want := []string{"alpha:a2", "alpha:a3", "beta:b1"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("Tools = %v, want %v", names, want)
+	}
+}
@@ -2,11 +2,15 @@ package proxy
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 
 	"github.com/ain3sh/mcp2/internal/config"
 	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/redact"
 	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/ain3sh/mcp2/internal/version"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -17,13 +21,17 @@ type PerServerProxy struct {
 	upstream      *upstream.Upstream
 	profileEngine *profile.Engine
 	serverID      string
+	log           *slog.Logger
+
+	// maxResourceBytes is hub.maxResourceBytes.
+	maxResourceBytes int
 }
 
 // NewPerServerProxy creates a proxy for a single upstream server.
 func NewPerServerProxy(cfg *config.RootConfig, upstream *upstream.Upstream, profileName string) *PerServerProxy {
 	server := mcp.NewServer(&mcp.Implementation{
 		Name:    fmt.Sprintf("mcp2-proxy-%s", upstream.ID),
-		Version: "0.1.0",
+		Version: version.Version,
 	}, nil)
 
 	proxy := &PerServerProxy{
@@ -31,6 +39,8 @@ func NewPerServerProxy(cfg *config.RootConfig, upstream *upstream.Upstream, prof
 		upstream:      upstream,
 		profileEngine: profile.NewEngine(cfg, profileName),
 		serverID:      upstream.ID,
+
+		maxResourceBytes: cfg.Hub.MaxResourceBytes,
 	}
 
 	// Register handlers for this specific upstream
@@ -44,6 +54,26 @@ func (p *PerServerProxy) Server() *mcp.Server {
 	return p.server
 }
 
+// DecisionCounts returns how many times the proxy's profile allowed and
+// denied the server's tools, resources, and prompts.
+func (p *PerServerProxy) DecisionCounts() []profile.DecisionCount {
+	return p.profileEngine.Counts()
+}
+
+// SetLogger sets the logger used by the proxy. By default it logs to
+// slog.Default().
+func (p *PerServerProxy) SetLogger(logger *slog.Logger) {
+	p.log = logger
+}
+
+// logger returns the proxy's logger, falling back to slog.Default().
+func (p *PerServerProxy) logger() *slog.Logger {
+	if p.log != nil {
+		return p.log
+	}
+	return slog.Default()
+}
+
 // registerHandlers sets up filtering middleware for a single upstream.
 func (p *PerServerProxy) registerHandlers() {
 	p.server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
@@ -70,7 +100,7 @@ func (p *PerServerProxy) registerHandlers() {
 
 // handleToolsList returns filtered tools from the upstream.
 func (p *PerServerProxy) handleToolsList(ctx context.Context) (mcp.Result, error) {
-	result, err := p.upstream.Session.ListTools(ctx, nil)
+	result, err := p.upstream.Session().ListTools(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -78,8 +108,8 @@ func (p *PerServerProxy) handleToolsList(ctx context.Context) (mcp.Result, error
 	// Filter tools based on profile
 	var filteredTools []*mcp.Tool
 	for _, tool := range result.Tools {
-		if p.profileEngine.IsToolAllowed(p.serverID, tool.Name) {
-			filteredTools = append(filteredTools, tool)
+		if p.profileEngine.ExplainToolCall(p.serverID, tool.Name, isReadOnly(tool)).Allowed {
+			filteredTools = append(filteredTools, cloneTool(tool))
 		}
 	}
 
@@ -93,21 +123,27 @@ func (p *PerServerProxy) handleToolsCall(ctx context.Context, req mcp.Request) (
 		return nil, fmt.Errorf("invalid request type for tools/call")
 	}
 
-	// Check if tool is allowed by profile
-	if !p.profileEngine.IsToolAllowed(p.serverID, callReq.Params.Name) {
-		return nil, fmt.Errorf("tool %q is not allowed by profile", callReq.Params.Name)
+	// Check if tool is allowed by profile, unless the profile trusts
+	// list-time filtering and safe mode is off
+	if !p.profileEngine.ListEnforcedOnly() || p.profileEngine.SafeMode() {
+		if decision := explainToolCall(ctx, p.profileEngine, p.upstream, callReq.Params.Name); !decision.Allowed {
+			p.logger().Debug("tool denied by profile", "server", p.serverID, "tool", callReq.Params.Name)
+			return nil, deniedError("tool", callReq.Params.Name, decision)
+		}
 	}
 
 	// Forward to upstream
-	return p.upstream.Session.CallTool(ctx, &mcp.CallToolParams{
+	result, err := p.upstream.Session().CallTool(ctx, &mcp.CallToolParams{
+		Meta:      clientMeta(callReq.Params.Meta),
 		Name:      callReq.Params.Name,
 		Arguments: callReq.Params.Arguments,
 	})
+	return result, scrubArguments(err, toolArguments(callReq.Params.Arguments))
 }
 
 // handleResourcesList returns filtered resources from the upstream.
 func (p *PerServerProxy) handleResourcesList(ctx context.Context) (mcp.Result, error) {
-	result, err := p.upstream.Session.ListResources(ctx, nil)
+	result, err := p.upstream.Session().ListResources(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -115,8 +151,8 @@ func (p *PerServerProxy) handleResourcesList(ctx context.Context) (mcp.Result, e
 	// Filter resources based on profile
 	var filteredResources []*mcp.Resource
 	for _, resource := range result.Resources {
-		if p.profileEngine.IsResourceAllowed(p.serverID, resource.URI) {
-			filteredResources = append(filteredResources, resource)
+		if p.profileEngine.ExplainResourceMetadata(p.serverID, resource.URI, resourceMetadata(resource)).Allowed {
+			filteredResources = append(filteredResources, cloneResource(resource))
 		}
 	}
 
@@ -131,19 +167,35 @@ func (p *PerServerProxy) handleResourcesRead(ctx context.Context, req mcp.Reques
 	}
 
 	// Check if resource is allowed by profile
-	if !p.profileEngine.IsResourceAllowed(p.serverID, readReq.Params.URI) {
-		return nil, fmt.Errorf("resource %q is not allowed by profile", readReq.Params.URI)
+	decision, err := explainResourceRead(ctx, p.profileEngine, p.upstream, readReq.Params.URI)
+	if err != nil {
+		return nil, err
+	}
+	if !decision.Allowed {
+		return nil, deniedError("resource", readReq.Params.URI, decision)
+	}
+
+	if _, _, err := requestedRange(readReq.Params.Meta); err != nil {
+		return nil, err
 	}
 
 	// Forward to upstream
-	return p.upstream.Session.ReadResource(ctx, &mcp.ReadResourceParams{
-		URI: readReq.Params.URI,
+	result, err := p.upstream.Session().ReadResource(ctx, &mcp.ReadResourceParams{
+		Meta: clientMeta(readReq.Params.Meta),
+		URI:  readReq.Params.URI,
 	})
+	if err != nil {
+		return nil, err
+	}
+	if err := shapeContents(readReq.Params, result, p.maxResourceBytes); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 // handlePromptsList returns filtered prompts from the upstream.
 func (p *PerServerProxy) handlePromptsList(ctx context.Context) (mcp.Result, error) {
-	result, err := p.upstream.Session.ListPrompts(ctx, nil)
+	result, err := p.upstream.Session().ListPrompts(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -152,7 +204,7 @@ func (p *PerServerProxy) handlePromptsList(ctx context.Context) (mcp.Result, err
 	var filteredPrompts []*mcp.Prompt
 	for _, prompt := range result.Prompts {
 		if p.profileEngine.IsPromptAllowed(p.serverID, prompt.Name) {
-			filteredPrompts = append(filteredPrompts, prompt)
+			filteredPrompts = append(filteredPrompts, clonePrompt(prompt))
 		}
 	}
 
@@ -167,13 +219,30 @@ func (p *PerServerProxy) handlePromptsGet(ctx context.Context, req mcp.Request)
 	}
 
 	// Check if prompt is allowed by profile
-	if !p.profileEngine.IsPromptAllowed(p.serverID, getReq.Params.Name) {
-		return nil, fmt.Errorf("prompt %q is not allowed by profile", getReq.Params.Name)
+	if decision := p.profileEngine.ExplainPrompt(p.serverID, getReq.Params.Name); !decision.Allowed {
+		return nil, deniedError("prompt", getReq.Params.Name, decision)
+	}
+	if err := p.profileEngine.CheckPromptArguments(p.serverID, getReq.Params.Name, getReq.Params.Arguments); err != nil {
+		return nil, err
 	}
 
 	// Forward to upstream
-	return p.upstream.Session.GetPrompt(ctx, &mcp.GetPromptParams{
+	result, err := p.upstream.Session().GetPrompt(ctx, &mcp.GetPromptParams{
+		Meta:      clientMeta(getReq.Params.Meta),
 		Name:      getReq.Params.Name,
 		Arguments: getReq.Params.Arguments,
 	})
+	return result, scrubArguments(err, promptArguments(getReq.Params.Arguments))
+}
+
+// scrubArguments returns err with the values of sensitive arguments in args
+// masked, or err itself if it contains none.
+func scrubArguments(err error, args map[string]any) error {
+	if err == nil {
+		return nil
+	}
+	if msg := redact.Scrub(err.Error(), redact.Secrets(args)); msg != err.Error() {
+		return errors.New(msg)
+	}
+	return err
 }
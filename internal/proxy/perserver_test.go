@@ -1,12 +1,11 @@
 package proxy
 
 import (
-	"context"
 	"testing"
 
 	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
 	"github.com/ain3sh/mcp2/internal/upstream"
-	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 func TestPerServerProxy_Creation(t *testing.T) {
@@ -173,55 +172,17 @@ func TestPerServerProxy_NoServerPrefixing(t *testing.T) {
 		},
 	}
 
-	upstream := &upstream.Upstream{
-		ID: "server1",
-	}
-
-	// Create in-memory transports for testing
-	clientTransport, serverTransport := mcp.NewInMemoryTransports()
-
-	// Create a simple test server that returns a tool
-	testServer := mcp.NewServer(&mcp.Implementation{
-		Name:    "test-upstream",
-		Version: "1.0.0",
-	}, nil)
-
-	mcp.AddTool(testServer, &mcp.Tool{
-		Name:        "test_tool",
-		Description: "A test tool",
-	}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
-		return &mcp.CallToolResult{
-			Content: []mcp.Content{
-				&mcp.TextContent{Text: "test response"},
-			},
-		}, nil, nil
+	u := mcp2test.NewUpstream(t, mcp2test.Upstream{
+		ID:      "server1",
+		Tools:   mcp2test.Tools("test_tool"),
+		Prompts: mcp2test.Prompts("test_prompt"),
 	})
+	proxy := NewPerServerProxy(cfg, u, "test")
 
-	// Run the test server
-	ctx := context.Background()
-	go testServer.Run(ctx, serverTransport)
-
-	// Connect client to test server
-	client := mcp.NewClient(&mcp.Implementation{
-		Name:    "test-client",
-		Version: "1.0.0",
-	}, nil)
-
-	session, err := client.Connect(ctx, clientTransport, nil)
-	if err != nil {
-		t.Fatalf("Failed to connect: %v", err)
-	}
-	defer session.Close()
-
-	// Set the session in our mock upstream
-	upstream.Session = session
-
-	// Create per-server proxy
-	_ = NewPerServerProxy(cfg, upstream, "test")
-
-	// The proxy should not prefix tool names even though hub.prefixServerIDs is true
-	// (In a real test we'd verify this by calling listTools on the proxy,
-	// but that requires more complex setup. The architecture ensures no prefixing.)
-
-	t.Log("Per-server proxy created successfully - architecture ensures no prefixing")
+	// Names are exposed as the upstream lists them even though
+	// hub.prefixServerIDs is true
+	mcp2test.AssertSurface(t, proxy.Server(), mcp2test.Surface{
+		Tools:   []string{"test_tool"},
+		Prompts: []string{"test_prompt"},
+	})
 }
@@ -0,0 +1,56 @@
+package proxy
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/version"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// PingToolName is the hub-native diagnostics tool listed when
+// hub.diagnosticsTool (or the profile's override) is set.
+const PingToolName = "mcp2_ping"
+
+// pingTool describes the hub-native diagnostics tool.
+func pingTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        PingToolName,
+		Title:       "mcp2 ping",
+		Description: "Checks that the mcp2 proxy is alive. Reports its version, active profile, how many of the profile's servers are connected, and the current time.",
+		InputSchema: map[string]any{"type": "object"},
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}
+}
+
+// pingResult is what the diagnostics tool reports.
+type pingResult struct {
+	Version          string    `json:"version"`
+	Profile          string    `json:"profile"`
+	ConnectedServers int       `json:"connectedServers"`
+	Servers          int       `json:"servers"`
+	Time             time.Time `json:"time"`
+}
+
+// handlePingTool answers a call to the diagnostics tool. It is answered by
+// the hub and never proxied or checked against the profile's filters.
+func (h *Hub) handlePingTool(v *profileView) *mcp.CallToolResult {
+	servers := h.profileServers(v)
+	result := pingResult{
+		Version: version.Version,
+		Profile: v.name,
+		Servers: len(servers),
+		Time:    time.Now().UTC(),
+	}
+	for _, serverID := range servers {
+		if u, err := h.manager.Get(serverID); err == nil && u.Connected() {
+			result.ConnectedServers++
+		}
+	}
+
+	data, _ := json.Marshal(result)
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(data)}},
+		StructuredContent: result,
+	}
+}
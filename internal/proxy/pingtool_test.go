@@ -0,0 +1,72 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/ain3sh/mcp2/internal/version"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_PingTool(t *testing.T) {
+	cfg := &config.RootConfig{
+		Hub: config.HubConfig{DiagnosticsTool: true, PrefixServerIDs: true},
+		Servers: map[string]config.ServerConfig{
+			"files":  {},
+			"search": {},
+		},
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"files":  {Tools: config.ComponentFilter{Allow: []string{"read_*"}}},
+					"search": {},
+				},
+			},
+		},
+	}
+	// search failed to connect
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "files", Tools: mcp2test.Tools("read_file")})
+	hub := NewHub(cfg, manager, "test")
+
+	// Listed unprefixed, and not subject to the profile's tool filters
+	mcp2test.AssertSurface(t, hub.Server(), mcp2test.Surface{Tools: []string{"files:read_file", PingToolName}})
+
+	result, err := connectTestClient(t, hub.Server()).CallTool(context.Background(), &mcp.CallToolParams{Name: PingToolName})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	if result.IsError {
+		t.Fatalf("CallTool returned an error result: %v", result.Content)
+	}
+	var got pingResult
+	if err := json.Unmarshal([]byte(result.Content[0].(*mcp.TextContent).Text), &got); err != nil {
+		t.Fatalf("ping result is not JSON: %v", err)
+	}
+	if got.Version != version.Version || got.Profile != "test" || got.ConnectedServers != 1 || got.Servers != 2 || got.Time.IsZero() {
+		t.Errorf("ping result = %+v, want version %s, profile test, 1 of 2 servers connected, and a time", got, version.Version)
+	}
+}
+
+func TestHub_PingToolProfileOverride(t *testing.T) {
+	off := false
+	cfg := &config.RootConfig{
+		Hub: config.HubConfig{DiagnosticsTool: true},
+		Profiles: map[string]config.ProfileConfig{
+			"quiet": {
+				Servers:         map[string]config.ServerProfileConfig{"files": {}},
+				DiagnosticsTool: &off,
+			},
+		},
+	}
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "files", Tools: mcp2test.Tools("read_file")})
+	hub := NewHub(cfg, manager, "quiet")
+
+	mcp2test.AssertSurface(t, hub.Server(), mcp2test.Surface{Tools: []string{"read_file"}})
+	result, err := connectTestClient(t, hub.Server()).CallTool(context.Background(), &mcp.CallToolParams{Name: PingToolName})
+	if err == nil && !result.IsError {
+		t.Errorf("CallTool(%s) succeeded with the diagnostics tool turned off for the profile", PingToolName)
+	}
+}
@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// profileHeader carries the profile chosen for a request from the hub's
+// HTTP handler to its MCP handlers. The SDK passes the HTTP request's
+// headers, but not its context, through to them. The handler always
+// overwrites it, so clients can't choose a profile by sending it.
+const profileHeader = "X-Mcp2-Profile"
+
+// profileView is what the hub needs to serve requests for one profile: its
+// engine, naming settings, and what it last listed.
+type profileView struct {
+	name            string
+	engine          *profile.Engine
+	prefix          config.PrefixedComponents
+	separator       string
+	diagnosticsTool bool
+
+	// listedTools records, per server ID, the tool names exposed by the
+	// most recent tools/list. It is used to detect filtering inconsistencies.
+	listedTools map[string]map[string]bool
+	listedMu    sync.RWMutex
+}
+
+func newProfileView(cfg *config.RootConfig, profileName string) *profileView {
+	v := &profileView{
+		name:            profileName,
+		engine:          profile.NewEngine(cfg, profileName),
+		prefix:          cfg.ComponentPrefixes(profileName),
+		diagnosticsTool: cfg.DiagnosticsToolFor(profileName),
+		listedTools:     make(map[string]map[string]bool),
+	}
+	_, v.separator = cfg.PrefixSettings(profileName)
+	return v
+}
+
+// prefixName joins a server ID and a name with the profile's separator.
+func (v *profileView) prefixName(serverID, name string) string {
+	return serverID + v.separator + name
+}
+
+// splitName splits a prefixed name into its server ID and name at the first
+// separator. Server IDs cannot contain the separator, so names that do
+// round-trip unchanged.
+func (v *profileView) splitName(prefixed string) (serverID, name string, ok bool) {
+	return strings.Cut(prefixed, v.separator)
+}
+
+// newProfileViews builds a view for every configured profile and for
+// defaultProfile, which need not be configured.
+func newProfileViews(cfg *config.RootConfig, defaultProfile string) map[string]*profileView {
+	views := make(map[string]*profileView, len(cfg.Profiles)+1)
+	for name := range cfg.Profiles {
+		views[name] = newProfileView(cfg, name)
+	}
+	if _, ok := views[defaultProfile]; !ok {
+		views[defaultProfile] = newProfileView(cfg, defaultProfile)
+	}
+	return views
+}
+
+// anyViewHasServer reports whether any of the hub's profiles includes
+// serverID.
+func (h *Hub) anyViewHasServer(serverID string) bool {
+	for _, v := range h.views {
+		if v.engine.HasServer(serverID) {
+			return true
+		}
+	}
+	return false
+}
+
+type profileViewKey struct{}
+
+// view returns the profile view for the request in ctx: the one chosen by
+// the hub's HTTP handler, else the hub's default.
+func (h *Hub) view(ctx context.Context) *profileView {
+	if v, ok := ctx.Value(profileViewKey{}).(*profileView); ok {
+		return v
+	}
+	return h.views[h.profileName]
+}
+
+// requestView returns the view for req's profile, as chosen by the hub's
+// HTTP handler, or the default view for requests that didn't come through
+// it.
+func (h *Hub) requestView(req mcp.Request) *profileView {
+	if extra := req.GetExtra(); extra != nil && extra.Header != nil {
+		if v, ok := h.views[extra.Header.Get(profileHeader)]; ok {
+			return v
+		}
+	}
+	return h.views[h.profileName]
+}
+
+// HTTPHandler returns a streamable HTTP handler for the hub that serves
+// each request with the profile choose returns for it, which must be
+// configured. A nil choose, or an empty result, selects the hub's default
+// profile.
+func (h *Hub) HTTPHandler(choose func(*http.Request) string) http.Handler {
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server {
+		return h.server
+	}, nil)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := h.profileName
+		if choose != nil {
+			if chosen := choose(r); chosen != "" {
+				name = chosen
+			}
+		}
+		if _, ok := h.views[name]; !ok {
+			http.Error(w, "unknown profile", http.StatusNotFound)
+			return
+		}
+		r.Header.Set(profileHeader, name)
+		mcpHandler.ServeHTTP(w, r)
+	})
+}
+
+// DecisionCounts returns how many times each of the hub's profiles allowed
+// and denied each server's tools, resources, and prompts, across list and
+// call phases, sorted by profile.
+func (h *Hub) DecisionCounts() []profile.DecisionCount {
+	names := make([]string, 0, len(h.views))
+	for name := range h.views {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var counts []profile.DecisionCount
+	for _, name := range names {
+		counts = append(counts, h.views[name].engine.Counts()...)
+	}
+	return counts
+}
@@ -0,0 +1,134 @@
+package proxy
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// headerTransport sets a header on every request it sends.
+type headerTransport struct {
+	key, value string
+}
+
+func (t headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(t.key, t.value)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// connectHTTPClient connects a client to url, sending header key: value
+// with every request.
+func connectHTTPClient(t *testing.T, url, key, value string) (*mcp.ClientSession, error) {
+	t.Helper()
+
+	transport := &mcp.StreamableClientTransport{
+		Endpoint:   url,
+		HTTPClient: &http.Client{Transport: headerTransport{key: key, value: value}},
+	}
+	client := mcp.NewClient(&mcp.Implementation{Name: "downstream", Version: "1.0.0"}, nil)
+	session, err := client.Connect(context.Background(), transport, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.Cleanup(func() { session.Close() })
+	return session, nil
+}
+
+func toolNames(t *testing.T, session *mcp.ClientSession) []string {
+	t.Helper()
+
+	result, err := session.ListTools(context.Background(), nil)
+	if err != nil {
+		t.Errorf("ListTools failed: %v", err)
+		return nil
+	}
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func TestHub_ProfilePerRequest(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"reader": {
+				Servers: map[string]config.ServerProfileConfig{
+					"files": {Tools: config.ComponentFilter{Allow: []string{"read_*"}}},
+				},
+			},
+			"admin": {
+				Servers: map[string]config.ServerProfileConfig{"files": {}, "search": {}},
+			},
+		},
+	}
+	manager := mcp2test.NewManager(t,
+		mcp2test.Upstream{ID: "files", Tools: mcp2test.Tools("read_file", "write_file")},
+		mcp2test.Upstream{ID: "search", Tools: mcp2test.Tools("query")},
+	)
+	hub := NewHub(cfg, manager, "reader")
+
+	srv := httptest.NewServer(hub.HTTPHandler(func(r *http.Request) string {
+		return r.Header.Get("X-Tenant")
+	}))
+	// Registered first, so it runs after the client sessions close
+	t.Cleanup(srv.Close)
+
+	reader, err := connectHTTPClient(t, srv.URL, "X-Tenant", "reader")
+	if err != nil {
+		t.Fatalf("Connect as reader failed: %v", err)
+	}
+	admin, err := connectHTTPClient(t, srv.URL, "X-Tenant", "admin")
+	if err != nil {
+		t.Fatalf("Connect as admin failed: %v", err)
+	}
+
+	want := map[*mcp.ClientSession][]string{
+		reader: {"read_file"},
+		admin:  {"query", "read_file", "write_file"},
+	}
+	var wg sync.WaitGroup
+	for range 10 {
+		for session, tools := range want {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if got := toolNames(t, session); !slices.Equal(got, tools) {
+					t.Errorf("ListTools = %v, want %v", got, tools)
+				}
+			}()
+		}
+	}
+	wg.Wait()
+
+	// A call is checked against the caller's profile, not the hub's default
+	result, err := admin.CallTool(context.Background(), &mcp.CallToolParams{Name: "write_file"})
+	if err != nil || result.IsError {
+		t.Errorf("CallTool(write_file) as admin = %v, %v; want success", result, err)
+	}
+	if _, err := reader.CallTool(context.Background(), &mcp.CallToolParams{Name: "write_file"}); err == nil {
+		t.Error("CallTool(write_file) as reader succeeded, want it denied")
+	}
+
+	// Clients can't pick a profile by sending the internal header
+	spoofed, err := connectHTTPClient(t, srv.URL, profileHeader, "admin")
+	if err != nil {
+		t.Fatalf("Connect with %s failed: %v", profileHeader, err)
+	}
+	if got := toolNames(t, spoofed); !slices.Equal(got, []string{"read_file"}) {
+		t.Errorf("ListTools with %s: admin = %v, want the default profile's [read_file]", profileHeader, got)
+	}
+
+	if _, err := connectHTTPClient(t, srv.URL, "X-Tenant", "nobody"); err == nil {
+		t.Error("Connect with an unknown profile succeeded")
+	}
+}
@@ -0,0 +1,130 @@
+package proxy
+
+import (
+	"context"
+
+	"github.com/ain3sh/mcp2/internal/requestid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// progressTarget is the downstream session and token that progress for an
+// upstream call is relayed to.
+type progressTarget struct {
+	session *mcp.ServerSession
+	token   any
+}
+
+// progressTokenKey is the _meta key a client sets to ask for progress.
+const progressTokenKey = "progressToken"
+
+// clientMeta copies the _meta a client sent, minus the keys the proxy
+// interprets itself, so out-of-band data like trace context reaches the
+// upstream unchanged.
+func clientMeta(meta mcp.Meta) mcp.Meta {
+	var out mcp.Meta
+	for k, v := range meta {
+		if k == progressTokenKey || k == BroadcastMetaKey || k == RangeMetaKey {
+			continue
+		}
+		if out == nil {
+			out = mcp.Meta{}
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// forwardMeta builds the _meta sent to serverID for a request that arrived
+// from session with meta: the client's entries plus the request ID and client
+// identity. If the client asked for progress, a unique upstream progress
+// token is registered so notifications can be relayed back, and with
+// hub.allowElicitation the session is tracked as waiting on serverID; the
+// returned func undoes both and must be called when the request completes.
+func (h *Hub) forwardMeta(ctx context.Context, serverID string, session *mcp.ServerSession, meta mcp.Meta) (mcp.Meta, func()) {
+	untrack := h.trackPending(serverID, session)
+
+	forwarded := clientMeta(meta)
+	for k, v := range requestMeta(ctx) {
+		if forwarded == nil {
+			forwarded = mcp.Meta{}
+		}
+		forwarded[k] = v
+	}
+
+	token := meta[progressTokenKey]
+	if token == nil || session == nil {
+		return forwarded, untrack
+	}
+
+	if forwarded == nil {
+		forwarded = mcp.Meta{}
+	}
+	upstreamToken := requestid.New()
+	forwarded[progressTokenKey] = upstreamToken
+
+	h.progressMu.Lock()
+	h.progress[upstreamToken] = progressTarget{session: session, token: token}
+	h.progressMu.Unlock()
+
+	return forwarded, func() {
+		h.progressMu.Lock()
+		delete(h.progress, upstreamToken)
+		h.progressMu.Unlock()
+		untrack()
+	}
+}
+
+// toolCallParams builds the params for forwarding a tool call to serverID as
+// name, carrying the client's _meta as described by forwardMeta.
+func (h *Hub) toolCallParams(ctx context.Context, callReq *mcp.CallToolRequest, serverID, name string) (*mcp.CallToolParams, func()) {
+	meta, done := h.forwardMeta(ctx, serverID, callReq.Session, callReq.Params.Meta)
+	return &mcp.CallToolParams{
+		Meta:      meta,
+		Name:      name,
+		Arguments: callReq.Params.Arguments,
+	}, done
+}
+
+// readResourceParams builds the params for forwarding a resource read to
+// serverID as uri, carrying the client's _meta as described by forwardMeta.
+func (h *Hub) readResourceParams(ctx context.Context, readReq *mcp.ReadResourceRequest, serverID, uri string) (*mcp.ReadResourceParams, func()) {
+	meta, done := h.forwardMeta(ctx, serverID, readReq.Session, readReq.Params.Meta)
+	return &mcp.ReadResourceParams{Meta: meta, URI: uri}, done
+}
+
+// getPromptParams builds the params for forwarding a prompt get to serverID
+// as name, carrying the client's _meta as described by forwardMeta.
+func (h *Hub) getPromptParams(ctx context.Context, getReq *mcp.GetPromptRequest, serverID, name string) (*mcp.GetPromptParams, func()) {
+	meta, done := h.forwardMeta(ctx, serverID, getReq.Session, getReq.Params.Meta)
+	return &mcp.GetPromptParams{
+		Meta:      meta,
+		Name:      name,
+		Arguments: getReq.Params.Arguments,
+	}, done
+}
+
+// relayProgress forwards an upstream progress notification to the downstream
+// client whose call it belongs to.
+func (h *Hub) relayProgress(serverID string, params *mcp.ProgressNotificationParams) {
+	upstreamToken, ok := params.ProgressToken.(string)
+	if !ok {
+		return
+	}
+
+	h.progressMu.Lock()
+	target, ok := h.progress[upstreamToken]
+	h.progressMu.Unlock()
+	if !ok {
+		return
+	}
+
+	err := target.session.NotifyProgress(context.Background(), &mcp.ProgressNotificationParams{
+		ProgressToken: target.token,
+		Progress:      params.Progress,
+		Total:         params.Total,
+		Message:       params.Message,
+	})
+	if err != nil {
+		h.logger().Debug("failed to relay progress", "server", serverID, "error", err)
+	}
+}
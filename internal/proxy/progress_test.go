@@ -0,0 +1,158 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_RelaysProgress(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	ctx := context.Background()
+
+	// The tool waits for its progress to reach the downstream client, since
+	// progress arriving after the result is not relayed.
+	progress := make(chan *mcp.ProgressNotificationParams, 1)
+	progressSeen := make(chan struct{})
+
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: "slow"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			if token := req.Params.GetProgressToken(); token != nil {
+				req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+					ProgressToken: token,
+					Progress:      1,
+					Total:         2,
+					Message:       "halfway",
+				})
+				select {
+				case <-progressSeen:
+				case <-time.After(time.Second):
+				}
+			}
+			return &mcp.CallToolResult{}, nil, nil
+		})
+	}})
+	hub := NewHub(cfg, manager, "test")
+
+	session := mcp2test.ConnectWith(t, hub.Server(), &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress <- req.Params
+			close(progressSeen)
+		},
+	})
+
+	params := &mcp.CallToolParams{Meta: mcp.Meta{}, Name: "server1:slow"}
+	params.SetProgressToken("client-token")
+	if _, err := session.CallTool(ctx, params); err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	select {
+	case p := <-progress:
+		if p.ProgressToken != "client-token" || p.Progress != 1 || p.Total != 2 || p.Message != "halfway" {
+			t.Errorf("Unexpected progress notification: %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected progress notification to be relayed")
+	}
+}
+
+func TestHub_ForwardsMeta(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+
+	ctx := context.Background()
+	progressSeen := make(chan struct{})
+
+	var toolMeta, promptMeta mcp.Meta
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+		mcp.AddTool(server, &mcp.Tool{Name: "traced"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			toolMeta = req.Params.Meta
+			req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: req.Params.GetProgressToken(),
+				Progress:      1,
+			})
+			select {
+			case <-progressSeen:
+			case <-time.After(time.Second):
+			}
+			return &mcp.CallToolResult{Meta: mcp.Meta{"upstream/cost": 3.0}}, nil, nil
+		})
+		server.AddPrompt(&mcp.Prompt{Name: "traced"}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			promptMeta = req.Params.Meta
+			return &mcp.GetPromptResult{Meta: mcp.Meta{"upstream/cost": 1.0}}, nil
+		})
+	}})
+	hub := NewHub(cfg, manager, "test")
+
+	progress := make(chan any, 1)
+	session := mcp2test.ConnectWith(t, hub.Server(), &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			progress <- req.Params.ProgressToken
+			close(progressSeen)
+		},
+	})
+
+	const traceparent = "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
+		Meta: mcp.Meta{"traceparent": traceparent, "progressToken": "client-token"},
+		Name: "server1:traced",
+	})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+
+	if toolMeta["traceparent"] != traceparent {
+		t.Errorf("Upstream tool _meta = %v, want traceparent carried through", toolMeta)
+	}
+	if token := toolMeta["progressToken"]; token == nil || token == "client-token" {
+		t.Errorf("Upstream progress token = %v, want a proxy-issued token", token)
+	}
+	select {
+	case token := <-progress:
+		if token != "client-token" {
+			t.Errorf("Relayed progress token = %v, want client-token", token)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected progress notification to be relayed")
+	}
+	if result.Meta["upstream/cost"] != 3.0 {
+		t.Errorf("CallTool result _meta = %v, want upstream's _meta returned", result.Meta)
+	}
+
+	prompt, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Meta: mcp.Meta{"traceparent": traceparent},
+		Name: "server1:traced",
+	})
+	if err != nil {
+		t.Fatalf("GetPrompt failed: %v", err)
+	}
+	if promptMeta["traceparent"] != traceparent {
+		t.Errorf("Upstream prompt _meta = %v, want traceparent carried through", promptMeta)
+	}
+	if prompt.Meta["upstream/cost"] != 1.0 {
+		t.Errorf("GetPrompt result _meta = %v, want upstream's _meta returned", prompt.Meta)
+	}
+}
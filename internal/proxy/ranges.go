@@ -0,0 +1,134 @@
+package proxy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// RangeMetaKey is the _meta key that asks the hub for a byte range of each
+// content of a resources/read, as "start-end" (inclusive, as in an HTTP
+// Range header) or "start-" for the rest. MCP has no ranged reads, so the
+// hub reads the whole resource and slices it. Each sliced content carries
+// the range served under the same key in its _meta.
+const RangeMetaKey = "mcp2/range"
+
+// ByteRange is a range of bytes in a resource's content.
+type ByteRange struct {
+	Start int64
+	// End is the last byte included, or -1 for the end of the content.
+	End int64
+}
+
+// ParseRange parses a range in the form "start-end" or "start-".
+func ParseRange(s string) (ByteRange, error) {
+	startStr, endStr, ok := strings.Cut(s, "-")
+	if !ok {
+		return ByteRange{}, fmt.Errorf("invalid range %q: want start-end or start-", s)
+	}
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil || start < 0 {
+		return ByteRange{}, fmt.Errorf("invalid range %q: start must be a non-negative integer", s)
+	}
+	r := ByteRange{Start: start, End: -1}
+	if endStr != "" {
+		if r.End, err = strconv.ParseInt(endStr, 10, 64); err != nil || r.End < start {
+			return ByteRange{}, fmt.Errorf("invalid range %q: end must be an integer no less than start", s)
+		}
+	}
+	return r, nil
+}
+
+func (r ByteRange) String() string {
+	if r.End < 0 {
+		return fmt.Sprintf("%d-", r.Start)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// bounds returns the half-open byte offsets of r within content of size
+// bytes, clamped to it.
+func (r ByteRange) bounds(size int) (start, end int) {
+	start, end = size, size
+	if r.Start < int64(size) {
+		start = int(r.Start)
+	}
+	if r.End >= 0 && r.End+1 < int64(size) {
+		end = max(int(r.End)+1, start)
+	}
+	return start, end
+}
+
+// requestedRange returns the range asked for in meta, if any.
+func requestedRange(meta mcp.Meta) (r ByteRange, ok bool, err error) {
+	v, present := meta[RangeMetaKey]
+	if !present {
+		return ByteRange{}, false, nil
+	}
+	s, isString := v.(string)
+	if !isString {
+		return ByteRange{}, false, fmt.Errorf("_meta %q must be a string like \"0-1023\"", RangeMetaKey)
+	}
+	r, err = ParseRange(s)
+	return r, err == nil, err
+}
+
+// sliceContents cuts each of result's contents to r. Text is cut at
+// character boundaries so it stays valid UTF-8, dropping a character split
+// by either end.
+func sliceContents(result *mcp.ReadResourceResult, r ByteRange) {
+	for _, c := range result.Contents {
+		var start, end, size int
+		if c.Blob != nil {
+			size = len(c.Blob)
+			start, end = r.bounds(size)
+			c.Blob = c.Blob[start:end]
+		} else {
+			size = len(c.Text)
+			start, end = r.bounds(size)
+			for start < end && !utf8.RuneStart(c.Text[start]) {
+				start++
+			}
+			for end < size && end > start && !utf8.RuneStart(c.Text[end]) {
+				end--
+			}
+			c.Text = c.Text[start:end]
+		}
+
+		if c.Meta == nil {
+			c.Meta = mcp.Meta{}
+		}
+		c.Meta[RangeMetaKey] = map[string]any{"start": start, "end": end - 1, "size": size}
+	}
+}
+
+// shapeContents slices result to the range asked for in params, if any,
+// and returns an error if a content is then over limit bytes.
+func shapeContents(params *mcp.ReadResourceParams, result *mcp.ReadResourceResult, limit int) error {
+	r, ranged, err := requestedRange(params.Meta)
+	if err != nil {
+		return err
+	}
+	if ranged {
+		sliceContents(result, r)
+	}
+	return checkResourceSize(params.URI, result, limit)
+}
+
+// checkResourceSize returns an error if any of result's contents is larger
+// than limit bytes (0 = no limit).
+func checkResourceSize(uri string, result *mcp.ReadResourceResult, limit int) error {
+	if limit <= 0 {
+		return nil
+	}
+	for _, c := range result.Contents {
+		if size := len(c.Text) + len(c.Blob); size > limit {
+			return fmt.Errorf("resource %q content is %d bytes, over hub.maxResourceBytes (%d); read it in ranges with _meta[%q]",
+				uri, size, limit, RangeMetaKey)
+		}
+	}
+	return nil
+}
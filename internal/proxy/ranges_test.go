@@ -0,0 +1,103 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ByteRange
+		wantErr bool
+	}{
+		{"0-99", ByteRange{0, 99}, false},
+		{"100-", ByteRange{100, -1}, false},
+		{"5-5", ByteRange{5, 5}, false},
+		{"9-5", ByteRange{}, true},
+		{"-5", ByteRange{}, true},
+		{"5", ByteRange{}, true},
+		{"a-b", ByteRange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRange(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRange(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseRange(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSliceContents(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  mcp.ResourceContents
+		r        ByteRange
+		wantText string
+		wantBlob string
+	}{
+		{"text", mcp.ResourceContents{Text: "hello world"}, ByteRange{6, 10}, "world", ""},
+		{"open end", mcp.ResourceContents{Text: "hello world"}, ByteRange{6, -1}, "world", ""},
+		{"past end", mcp.ResourceContents{Text: "hello"}, ByteRange{10, 20}, "", ""},
+		// "é" is two bytes; ranges splitting it drop it
+		{"utf-8 start", mcp.ResourceContents{Text: "héllo"}, ByteRange{2, -1}, "llo", ""},
+		{"utf-8 end", mcp.ResourceContents{Text: "héllo"}, ByteRange{0, 1}, "h", ""},
+		{"blob", mcp.ResourceContents{Blob: []byte{0, 1, 2, 3, 4}}, ByteRange{1, 2}, "", "\x01\x02"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := tt.content
+			sliceContents(&mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{&c}}, tt.r)
+			if c.Text != tt.wantText || string(c.Blob) != tt.wantBlob {
+				t.Errorf("sliced to text %q blob %q, want text %q blob %q", c.Text, c.Blob, tt.wantText, tt.wantBlob)
+			}
+			if _, ok := c.Meta[RangeMetaKey]; !ok {
+				t.Errorf("sliced content has no %s _meta", RangeMetaKey)
+			}
+		})
+	}
+}
+
+func TestHub_ResourceReadRangeAndSizeLimit(t *testing.T) {
+	cfg := &config.RootConfig{
+		Hub: config.HubConfig{MaxResourceBytes: 5},
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{"access-log": {}}},
+		},
+	}
+	// The resource's text is the server ID, 10 bytes
+	manager := newTestManager(t, newResourceUpstream(t, "access-log", "file:///log"))
+	session := connectTestClient(t, NewHub(cfg, manager, "test").Server())
+	ctx := context.Background()
+
+	_, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "file:///log"})
+	if err == nil || !strings.Contains(err.Error(), "maxResourceBytes") {
+		t.Fatalf("full read error = %v, want maxResourceBytes error", err)
+	}
+
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{
+		URI:  "file:///log",
+		Meta: mcp.Meta{RangeMetaKey: "7-"},
+	})
+	if err != nil {
+		t.Fatalf("ranged read failed: %v", err)
+	}
+	if got := result.Contents[0].Text; got != "log" {
+		t.Errorf("ranged read = %q, want %q", got, "log")
+	}
+
+	_, err = session.ReadResource(ctx, &mcp.ReadResourceParams{
+		URI:  "file:///log",
+		Meta: mcp.Meta{RangeMetaKey: "0-"},
+	})
+	if err == nil {
+		t.Error("ranged read over maxResourceBytes succeeded, want error")
+	}
+}
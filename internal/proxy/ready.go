@@ -0,0 +1,36 @@
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/upstream"
+)
+
+// ReadyHandler reports whether every required server in cfg is connected:
+// 200 if so, else 503 listing those that aren't. Optional servers don't
+// affect readiness, so a load balancer keeps routing to a hub that is only
+// missing those.
+func ReadyHandler(cfg *config.RootConfig, manager *upstream.Manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var down []string
+		for serverID, serverCfg := range cfg.Servers {
+			if !serverCfg.IsRequired() {
+				continue
+			}
+			if u, err := manager.Get(serverID); err != nil || !u.Connected() {
+				down = append(down, serverID)
+			}
+		}
+
+		if len(down) > 0 {
+			sort.Strings(down)
+			http.Error(w, fmt.Sprintf("required servers not connected: %s", strings.Join(down, ", ")), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+}
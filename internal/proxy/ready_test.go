@@ -0,0 +1,36 @@
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+func TestReadyHandler_GatesOnRequiredServers(t *testing.T) {
+	optional := false
+	cfg := &config.RootConfig{
+		Servers: map[string]config.ServerConfig{
+			"server1": {},
+			"flaky":   {Required: &optional},
+		},
+	}
+	manager := newTestManager(t, newTestUpstream(t, "server1", "tool"))
+	handler := ReadyHandler(cfg, manager)
+
+	// The optional server never connected
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200 with only an optional server missing", rec.Code)
+	}
+
+	cfg.Servers["server2"] = config.ServerConfig{}
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "server2") {
+		t.Errorf("status = %d, body %q; want 503 naming server2", rec.Code, rec.Body.String())
+	}
+}
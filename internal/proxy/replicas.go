@@ -0,0 +1,177 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sync"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// replicaGroup balances calls across the members of a hub.replicaGroups
+// entry. By default each downstream session sticks to one member, chosen by
+// weighted rendezvous hashing so that only the sessions of a member that
+// goes away move elsewhere. Stateless groups use smooth weighted
+// round-robin, so a member with weight 2 gets every other call of three
+// rather than two in a row.
+type replicaGroup struct {
+	stateless bool
+
+	mu      sync.Mutex
+	members []*replica
+}
+
+type replica struct {
+	server  string
+	weight  int
+	current int
+}
+
+// newReplicaGroups builds the hub's replica groups from config, along with
+// the group each member server belongs to.
+func newReplicaGroups(cfg *config.RootConfig) (groups map[string]*replicaGroup, groupOf map[string]string) {
+	groups = make(map[string]*replicaGroup, len(cfg.Hub.ReplicaGroups))
+	groupOf = make(map[string]string)
+	for groupID, groupCfg := range cfg.Hub.ReplicaGroups {
+		group := &replicaGroup{stateless: groupCfg.Stateless}
+		for _, member := range groupCfg.Servers {
+			weight := member.Weight
+			if weight == 0 {
+				weight = 1
+			}
+			group.members = append(group.members, &replica{server: member.Server, weight: weight})
+			groupOf[member.Server] = groupID
+		}
+		groups[groupID] = group
+	}
+	return groups, groupOf
+}
+
+// pickFor returns the member to use for the downstream session identified
+// by key among those for which available is true, or false if none is.
+// Stateless groups, and requests without a session, use pick.
+func (g *replicaGroup) pickFor(key string, available func(serverID string) bool) (string, bool) {
+	if g.stateless || key == "" {
+		return g.pick(available)
+	}
+
+	var best string
+	bestScore := math.Inf(-1)
+	for _, r := range g.members {
+		if !available(r.server) {
+			continue
+		}
+		if score := rendezvousScore(key, r); score > bestScore {
+			best, bestScore = r.server, score
+		}
+	}
+	return best, best != ""
+}
+
+// rendezvousScore is key's weighted rendezvous hashing score for r: the
+// member with the highest score wins, and each member wins a share of keys
+// proportional to its weight.
+func rendezvousScore(key string, r *replica) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	h.Write([]byte{0})
+	h.Write([]byte(r.server))
+	// Map the hash to (0, 1)
+	u := (float64(h.Sum64()>>11) + 0.5) / (1 << 53)
+	return -float64(r.weight) / math.Log(u)
+}
+
+// pick returns the next member in round-robin order for which available is
+// true, or false if none is.
+func (g *replicaGroup) pick(available func(serverID string) bool) (string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	var best *replica
+	total := 0
+	for _, r := range g.members {
+		if !available(r.server) {
+			continue
+		}
+		r.current += r.weight
+		total += r.weight
+		if best == nil || r.current > best.current {
+			best = r
+		}
+	}
+	if best == nil {
+		return "", false
+	}
+	best.current -= total
+	return best.server, true
+}
+
+// resolveUpstream returns the upstream serving serverID for the request in
+// ctx. For a replica group that is the connected member picked for the
+// request's session, presented under the group ID so profile checks,
+// prefixes, and logs use the ID clients see.
+func (h *Hub) resolveUpstream(ctx context.Context, serverID string) (*upstream.Upstream, error) {
+	group, ok := h.replicas[serverID]
+	if !ok {
+		return h.manager.Get(serverID)
+	}
+
+	memberID, ok := group.pickFor(affinityKey(requestSession(ctx)), func(id string) bool {
+		u, err := h.manager.Get(id)
+		return err == nil && u.Connected()
+	})
+	if !ok {
+		return nil, fmt.Errorf("replica group %q has no connected servers", serverID)
+	}
+	u, err := h.manager.Get(memberID)
+	if err != nil {
+		return nil, err
+	}
+
+	h.logger().Debug("picked replica", "group", serverID, "server", memberID)
+	return u.Alias(serverID), nil
+}
+
+// logicalUpstreams returns the manager's upstreams with the members of each
+// replica group replaced by one upstream for the group, backed by the member
+// picked for the request in ctx.
+func (h *Hub) logicalUpstreams(ctx context.Context) []*upstream.Upstream {
+	var upstreams []*upstream.Upstream
+	for _, u := range h.manager.List() {
+		if _, ok := h.replicaOf[u.ID]; !ok {
+			upstreams = append(upstreams, u)
+		}
+	}
+	for groupID := range h.replicas {
+		if u, err := h.resolveUpstream(ctx, groupID); err == nil {
+			upstreams = append(upstreams, u)
+		}
+	}
+	return upstreams
+}
+
+// logicalID returns the ID clients know serverID by: its replica group's ID
+// if it is a member of one, else serverID itself.
+func (h *Hub) logicalID(serverID string) string {
+	if groupID, ok := h.replicaOf[serverID]; ok {
+		return groupID
+	}
+	return serverID
+}
+
+// affinityKey identifies a downstream session for sticky routing: its
+// session ID, or for transports without one, such as stdio, the session
+// itself.
+func affinityKey(ss *mcp.ServerSession) string {
+	if ss == nil {
+		return ""
+	}
+	if id := ss.ID(); id != "" {
+		return id
+	}
+	return fmt.Sprintf("%p", ss)
+}
@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReplicaGroup_WeightedRoundRobin(t *testing.T) {
+	group := &replicaGroup{members: []*replica{
+		{server: "a", weight: 2},
+		{server: "b", weight: 1},
+	}}
+	all := func(string) bool { return true }
+
+	var picks []string
+	for i := 0; i < 6; i++ {
+		server, ok := group.pick(all)
+		if !ok {
+			t.Fatal("pick found no member")
+		}
+		picks = append(picks, server)
+	}
+	if got, want := strings.Join(picks, ","), "a,b,a,a,b,a"; got != want {
+		t.Errorf("picks = %s, want %s", got, want)
+	}
+
+	// Unavailable members are skipped
+	server, _ := group.pick(func(id string) bool { return id == "b" })
+	if server != "b" {
+		t.Errorf("pick with only b available = %q, want b", server)
+	}
+	if _, ok := group.pick(func(string) bool { return false }); ok {
+		t.Error("pick with no member available succeeded")
+	}
+}
+
+func TestHub_StatelessReplicaGroup(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"search": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{
+			PrefixServerIDs: true,
+			ReplicaGroups: map[string]config.ReplicaGroupConfig{
+				"search": {
+					Servers: []config.ReplicaConfig{
+						{Server: "search-1", Weight: 2},
+						{Server: "search-2"},
+					},
+					Stateless: true,
+				},
+			},
+		},
+	}
+
+	manager := newTestManager(t,
+		newTestUpstream(t, "search-1", "query"),
+		newTestUpstream(t, "search-2", "query"))
+	hub := NewHub(cfg, manager, "test")
+	session := connectTestClient(t, hub.Server())
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "search:query" {
+		t.Fatalf("Tools = %v, want only search:query", tools.Tools)
+	}
+
+	counts := make(map[string]int)
+	for i := 0; i < 6; i++ {
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "search:query"})
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		counts[result.Content[0].(*mcp.TextContent).Text]++
+	}
+	if counts["search-1:query"] != 4 || counts["search-2:query"] != 2 {
+		t.Errorf("calls per replica = %v, want 4 on search-1 and 2 on search-2", counts)
+	}
+
+	// Members are not reachable under their own IDs
+	if _, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "search-1:query"}); err == nil {
+		t.Error("CallTool(search-1:query) succeeded, want it denied")
+	}
+}
+
+func TestHub_StickyReplicaGroup(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"search": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{
+			PrefixServerIDs: true,
+			ReplicaGroups: map[string]config.ReplicaGroupConfig{
+				"search": {Servers: []config.ReplicaConfig{
+					{Server: "search-1"},
+					{Server: "search-2"},
+				}},
+			},
+		},
+	}
+
+	replicas := map[string]*upstream.Upstream{
+		"search-1": newTestUpstream(t, "search-1", "query"),
+		"search-2": newTestUpstream(t, "search-2", "query"),
+	}
+	manager := newTestManager(t, replicas["search-1"], replicas["search-2"])
+	hub := NewHub(cfg, manager, "test")
+	ctx := context.Background()
+
+	// backend returns the replica that served a call from session
+	backend := func(session *mcp.ClientSession) string {
+		t.Helper()
+		result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "search:query"})
+		if err != nil {
+			t.Fatalf("CallTool failed: %v", err)
+		}
+		return strings.TrimSuffix(result.Content[0].(*mcp.TextContent).Text, ":query")
+	}
+
+	// Each client's calls all reach the same replica
+	var sessions []*mcp.ClientSession
+	used := make(map[string]bool)
+	for i := 0; i < 8; i++ {
+		session := connectTestClient(t, hub.Server())
+		first := backend(session)
+		for j := 0; j < 3; j++ {
+			if got := backend(session); got != first {
+				t.Fatalf("client %d: call went to %s, want %s", i, got, first)
+			}
+		}
+		sessions = append(sessions, session)
+		used[first] = true
+	}
+	if len(used) != 2 {
+		t.Errorf("8 clients used replicas %v, want both", used)
+	}
+
+	// Clients fail over when their replica disconnects
+	session := sessions[0]
+	dead := backend(session)
+	replicas[dead].Session().Close()
+	deadline := time.Now().Add(time.Second)
+	for replicas[dead].Connected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := backend(session); got == dead {
+		t.Errorf("call after %s disconnected still went to it", dead)
+	}
+}
@@ -0,0 +1,44 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// resourceMetadata returns what resource declares about its size and
+// audience for profile resourceMetadata filters.
+func resourceMetadata(resource *mcp.Resource) *profile.ResourceMetadata {
+	md := &profile.ResourceMetadata{Size: resource.Size}
+	if a := resource.Annotations; a != nil {
+		for _, role := range a.Audience {
+			md.Audience = append(md.Audience, string(role))
+		}
+		md.Priority = a.Priority
+	}
+	return md
+}
+
+// explainResourceRead decides a read of uri on u. Reads carry no metadata,
+// so when the profile filters u's resources by it, the resource's listed
+// definition is looked up in u's resource cache; a resource u doesn't list,
+// such as one from a template, is decided by URI alone. If the lookup fails
+// for any other reason the read is denied and the error returned, since the
+// metadata rules can't be checked.
+func explainResourceRead(ctx context.Context, engine *profile.Engine, u *upstream.Upstream, uri string) (profile.Decision, error) {
+	var md *profile.ResourceMetadata
+	if engine.HasResourceMetadataRules(u.ID) {
+		resource, err := u.Resource(ctx, uri)
+		switch {
+		case err == nil:
+			md = resourceMetadata(resource)
+		case !errors.Is(err, upstream.ErrResourceNotFound):
+			return profile.Decision{}, fmt.Errorf("checking resource metadata for %q: %w", uri, err)
+		}
+	}
+	return engine.ExplainResourceMetadata(u.ID, uri, md), nil
+}
@@ -0,0 +1,130 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newResourceUpstream returns an upstream serving the given resource URIs.
+func newResourceUpstream(t *testing.T, id string, uris ...string) *upstream.Upstream {
+	t.Helper()
+	return mcp2test.NewUpstream(t, mcp2test.Upstream{ID: id, Resources: mcp2test.Resources(uris...)})
+}
+
+func TestHub_ResourcesListOrderAndPrefix(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"docs":  {Resources: config.ComponentFilter{Deny: []string{"file:///secret*"}}},
+					"notes": {},
+					"tools": {},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true, ServerOrder: []string{"notes"}},
+	}
+
+	manager := newTestManager(t,
+		newResourceUpstream(t, "docs", "file:///b.md", "file:///secret.md", "file:///a.md"),
+		newResourceUpstream(t, "notes", "note://2", "note://1"),
+		// Advertises no resources capability, so it is skipped
+		newTestUpstream(t, "tools", "read_file"))
+	hub := NewHub(cfg, manager, "test")
+	session := connectTestClient(t, hub.Server())
+
+	for i := 0; i < 3; i++ {
+		result, err := session.ListResources(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("ListResources failed: %v", err)
+		}
+		var uris []string
+		for _, r := range result.Resources {
+			uris = append(uris, r.URI)
+		}
+		want := []string{"notes:note://1", "notes:note://2", "docs:file:///a.md", "docs:file:///b.md"}
+		if strings.Join(uris, " ") != strings.Join(want, " ") {
+			t.Fatalf("Resources = %v, want %v", uris, want)
+		}
+	}
+}
+
+func TestHub_ResourceMetadataFilter(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"docs": {ResourceMetadata: config.ResourceMetadataFilter{MaxSize: 1024, Audience: []string{"assistant"}}},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+	u := mcp2test.NewUpstream(t, mcp2test.Upstream{
+		ID: "docs",
+		Resources: []*mcp.Resource{
+			{Name: "small", URI: "file:///small", Size: 100},
+			{Name: "huge", URI: "file:///huge", Size: 1 << 30},
+			{Name: "unsized", URI: "file:///unsized"},
+			{Name: "private", URI: "file:///private", Annotations: &mcp.Annotations{Audience: []mcp.Role{"user"}}},
+		},
+	})
+	hub := NewHub(cfg, newTestManager(t, u), "test")
+
+	mcp2test.AssertSurface(t, hub.Server(), mcp2test.Surface{
+		Resources: []string{"docs:file:///small", "docs:file:///unsized"},
+	})
+
+	ctx := context.Background()
+	session := connectTestClient(t, hub.Server())
+	if _, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: "docs:file:///small"}); err != nil {
+		t.Errorf("ReadResource(small) failed: %v", err)
+	}
+	for _, uri := range []string{"docs:file:///huge", "docs:file:///private"} {
+		_, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+		if err == nil || !strings.Contains(err.Error(), "not allowed") {
+			t.Errorf("ReadResource(%s) error = %v, want denied by profile", uri, err)
+		}
+	}
+}
+
+func TestHub_ResourceMetadataFilterFailsClosed(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"docs": {ResourceMetadata: config.ResourceMetadataFilter{MaxSize: 1024}},
+				},
+			},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+	u := mcp2test.NewUpstream(t, mcp2test.Upstream{
+		ID:        "docs",
+		Resources: []*mcp.Resource{{Name: "huge", URI: "file:///huge", Size: 1 << 30}},
+		Setup: func(server *mcp.Server) {
+			server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+				return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+					if method == "resources/list" {
+						return nil, errors.New("listing unavailable")
+					}
+					return next(ctx, method, req)
+				}
+			})
+		},
+	})
+	hub := NewHub(cfg, newTestManager(t, u), "test")
+
+	session := connectTestClient(t, hub.Server())
+	_, err := session.ReadResource(context.Background(), &mcp.ReadResourceParams{URI: "docs:file:///huge"})
+	if err == nil || !strings.Contains(err.Error(), "listing unavailable") {
+		t.Errorf("ReadResource(huge) error = %v, want the failed metadata lookup", err)
+	}
+}
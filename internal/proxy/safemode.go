@@ -0,0 +1,35 @@
+package proxy
+
+import (
+	"context"
+
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// isReadOnly reports whether tool is annotated read-only.
+func isReadOnly(tool *mcp.Tool) bool {
+	return tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+}
+
+// toolReadOnly reports whether u's tool named name is annotated read-only,
+// going by its cached definition. A tool that can't be found is not.
+func toolReadOnly(ctx context.Context, u *upstream.Upstream, name string) bool {
+	tool, err := u.Tool(ctx, name)
+	if err != nil {
+		return false
+	}
+	return isReadOnly(tool)
+}
+
+// explainToolCall decides a call to name on u. Calls carry no annotations,
+// so in safe mode the tool's are looked up in u's tool cache; otherwise
+// this is ExplainTool.
+func explainToolCall(ctx context.Context, engine *profile.Engine, u *upstream.Upstream, name string) profile.Decision {
+	readOnly := false
+	if engine.SafeMode() {
+		readOnly = toolReadOnly(ctx, u, name)
+	}
+	return engine.ExplainToolCall(u.ID, name, readOnly)
+}
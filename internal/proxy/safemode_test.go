@@ -0,0 +1,60 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_SafeModeAllowsOnlyReadOnlyTools(t *testing.T) {
+	for _, prefix := range []bool{true, false} {
+		cfg := &config.RootConfig{
+			Profiles: map[string]config.ProfileConfig{
+				"test": {
+					Servers: map[string]config.ServerProfileConfig{
+						"server1": {},
+					},
+					// Safe mode overrides trusted profiles too
+					ListEnforcedOnly: true,
+				},
+			},
+			Hub: config.HubConfig{PrefixServerIDs: prefix, SafeMode: true},
+		}
+		manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Tools: []*mcp.Tool{
+			{Name: "read_file", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}},
+			{Name: "write_file"},
+		}})
+		hub := NewHub(cfg, manager, "test")
+		ctx := context.Background()
+
+		name := func(tool string) string {
+			if prefix {
+				return "server1:" + tool
+			}
+			return tool
+		}
+
+		result, err := hub.handleToolsList(ctx)
+		if err != nil {
+			t.Fatalf("prefix=%v: handleToolsList failed: %v", prefix, err)
+		}
+		if tools := result.(*mcp.ListToolsResult).Tools; len(tools) != 1 || tools[0].Name != name("read_file") {
+			t.Errorf("prefix=%v: listed tools = %v, want only %s", prefix, tools, name("read_file"))
+		}
+
+		req := &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name("read_file")}}
+		if _, err := hub.handleToolsCall(ctx, req); err != nil {
+			t.Errorf("prefix=%v: handleToolsCall(read_file) failed: %v", prefix, err)
+		}
+
+		req = &mcp.CallToolRequest{Params: &mcp.CallToolParamsRaw{Name: name("write_file")}}
+		_, err = hub.handleToolsCall(ctx, req)
+		if err == nil || !strings.Contains(err.Error(), "safe mode") {
+			t.Errorf("prefix=%v: handleToolsCall(write_file) error = %v, want a safe mode denial", prefix, err)
+		}
+	}
+}
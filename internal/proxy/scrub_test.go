@@ -0,0 +1,109 @@
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_ScrubsSecretArgumentsFromErrors(t *testing.T) {
+	const password = "hunter2-correct-horse"
+	args, _ := json.Marshal(map[string]any{"user": "alice", "password": password})
+
+	for _, prefix := range []bool{true, false} {
+		cfg := &config.RootConfig{
+			Profiles: map[string]config.ProfileConfig{
+				"test": {
+					Servers: map[string]config.ServerProfileConfig{
+						"server1": {},
+					},
+				},
+			},
+			Hub: config.HubConfig{PrefixServerIDs: prefix},
+		}
+		// The login tool fails with an error that echoes its arguments
+		manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+			server.AddTool(&mcp.Tool{Name: "login", InputSchema: &jsonschema.Schema{Type: "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return nil, fmt.Errorf("login failed for arguments %s", req.Params.Arguments)
+			})
+		}})
+		hub := NewHub(cfg, manager, "test")
+
+		var logs bytes.Buffer
+		hub.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+		name := "login"
+		if prefix {
+			name = "server1:login"
+		}
+		session := connectTestClient(t, hub.Server())
+		result, err := session.CallTool(context.Background(), &mcp.CallToolParams{
+			Name:      name,
+			Arguments: json.RawMessage(args),
+		})
+
+		var surfaced string
+		if err != nil {
+			surfaced = err.Error()
+		} else {
+			data, _ := json.Marshal(result)
+			surfaced = string(data)
+		}
+		if !strings.Contains(surfaced, "alice") {
+			t.Errorf("prefix=%v: expected the upstream error to reach the client, got %q", prefix, surfaced)
+		}
+		if strings.Contains(surfaced, password) {
+			t.Errorf("prefix=%v: password surfaced to the client: %q", prefix, surfaced)
+		}
+		if strings.Contains(logs.String(), password) {
+			t.Errorf("prefix=%v: password logged: %q", prefix, logs.String())
+		}
+	}
+}
+
+func TestHub_ScrubbedErrorsKeepJSONRPCCode(t *testing.T) {
+	const password = "hunter2-correct-horse"
+
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{"server1": {}}},
+		},
+		Hub: config.HubConfig{PrefixServerIDs: true},
+	}
+	// The login prompt rejects its arguments with a JSON-RPC error echoing
+	// them
+	manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+		server.AddPrompt(&mcp.Prompt{Name: "login", Arguments: []*mcp.PromptArgument{{Name: "password"}}}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+			data, _ := json.Marshal(req.Params.Arguments)
+			return nil, newWireError(-32602, fmt.Sprintf("invalid arguments %v", req.Params.Arguments), data)
+		})
+	}})
+	hub := NewHub(cfg, manager, "test")
+	hub.SetLogger(slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	session := connectTestClient(t, hub.Server())
+	_, err := session.GetPrompt(context.Background(), &mcp.GetPromptParams{Name: "server1:login", Arguments: map[string]string{"password": password}})
+	if err == nil {
+		t.Fatal("Expected the upstream error")
+	}
+	wire, ok := decodeWireError(err)
+	if !ok || wire.Code != -32602 {
+		t.Fatalf("Error = %v (wire %+v), want code -32602", err, wire)
+	}
+	if strings.Contains(wire.Message, password) || strings.Contains(string(wire.Data), password) {
+		t.Errorf("Password surfaced to the client: %+v", wire)
+	}
+	if !strings.Contains(wire.Message, "invalid arguments") {
+		t.Errorf("Message = %q, want the upstream's message", wire.Message)
+	}
+}
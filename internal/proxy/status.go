@@ -0,0 +1,51 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/ain3sh/mcp2/internal/upstream"
+)
+
+// StatusReport is a running proxy's view of its upstreams, as served by
+// StatusHandler.
+type StatusReport struct {
+	Upstreams []upstream.Status `json:"upstreams"`
+	// Decisions holds, per MCP endpoint, how often its profile allowed and
+	// denied each server's tools, resources, and prompts.
+	Decisions map[string][]profile.DecisionCount `json:"decisions,omitempty"`
+	Time      time.Time                          `json:"time"`
+}
+
+// DecisionCounter is an endpoint that counts its profile's decisions, such
+// as a Hub or PerServerProxy.
+type DecisionCounter interface {
+	DecisionCounts() []profile.DecisionCount
+}
+
+// StatusHandler serves a StatusReport as JSON: the connection state,
+// restarts, in-flight requests, and cache counters of every upstream
+// manager holds, and the decision counts of endpoints, keyed by endpoint.
+// Unlike probing the servers anew, these are the sessions actually serving
+// clients. endpoints is read on every request, so it must not change once
+// the handler serves.
+func StatusHandler(manager *upstream.Manager, endpoints map[string]DecisionCounter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := StatusReport{
+			Upstreams: manager.Statuses(),
+			Time:      time.Now().UTC(),
+		}
+		for endpoint, counter := range endpoints {
+			if counts := counter.DecisionCounts(); len(counts) > 0 {
+				if report.Decisions == nil {
+					report.Decisions = make(map[string][]profile.DecisionCount)
+				}
+				report.Decisions[endpoint] = counts
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,54 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/profile"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestStatusHandler_ReportsCountersFromServing(t *testing.T) {
+	cfg := &config.RootConfig{
+		Profiles: map[string]config.ProfileConfig{
+			"test": {Servers: map[string]config.ServerProfileConfig{"server1": {}}},
+		},
+		Hub: config.HubConfig{SafeMode: true},
+	}
+	manager := newTestManager(t, newTestUpstream(t, "server1", "read"))
+	hub := NewHub(cfg, manager, "test")
+	session := connectTestClient(t, hub.Server())
+
+	// Safe mode looks up each called tool's annotations in the cache
+	for range 2 {
+		session.CallTool(context.Background(), &mcp.CallToolParams{Name: "read"})
+	}
+
+	rec := httptest.NewRecorder()
+	StatusHandler(manager, map[string]DecisionCounter{"/mcp": hub}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/statusz", nil))
+	var report StatusReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if len(report.Upstreams) != 1 || report.Upstreams[0].ID != "server1" {
+		t.Fatalf("Upstreams = %+v, want server1", report.Upstreams)
+	}
+	if !report.Upstreams[0].Connected {
+		t.Errorf("server1 reported disconnected")
+	}
+	tools := report.Upstreams[0].Caches.Tools
+	if tools.Misses != 1 || tools.Hits != 1 || tools.Size != 1 {
+		t.Errorf("Tool cache = %+v, want 1 miss, 1 hit, 1 cached", tools)
+	}
+
+	// Safe mode denied both calls, since read isn't annotated read-only
+	want := []profile.DecisionCount{{Profile: "test", Server: "server1", Component: profile.ComponentTool, Denied: 2}}
+	if got := report.Decisions["/mcp"]; !slices.Equal(got, want) {
+		t.Errorf("Decisions[/mcp] = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// StatusToolName is the hub-native tool listed when hub.statusTool is set
+// and none of the profile's servers are connected.
+const StatusToolName = "mcp2_status"
+
+// statusTool describes the hub-native status tool.
+func statusTool() *mcp.Tool {
+	return &mcp.Tool{
+		Name:        StatusToolName,
+		Title:       "MCP server status",
+		Description: "Reports which backend MCP servers are unavailable and why. Listed because none are currently connected; tell the user the backend tools are unavailable.",
+		InputSchema: map[string]any{"type": "object"},
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true},
+	}
+}
+
+// profileServers returns the IDs of the configured servers v's profile
+// exposes, directly or through a replica group, sorted.
+func (h *Hub) profileServers(v *profileView) []string {
+	return h.config.ProfileServerIDs(v.name)
+}
+
+// allServersDown reports whether none of the profile's servers are
+// connected, counting servers that failed to connect at startup.
+func (h *Hub) allServersDown(v *profileView) bool {
+	for _, serverID := range h.profileServers(v) {
+		if u, err := h.manager.Get(serverID); err == nil && u.Connected() {
+			return false
+		}
+	}
+	return true
+}
+
+// handleStatusTool answers a call to the status tool with each of the
+// profile's servers and, for those that are down, their last error. It is
+// answered by the hub and never proxied.
+func (h *Hub) handleStatusTool(v *profileView) *mcp.CallToolResult {
+	var b strings.Builder
+	if h.allServersDown(v) {
+		b.WriteString("The backend MCP servers are currently unavailable.\n")
+	}
+	for _, serverID := range h.profileServers(v) {
+		u, err := h.manager.Get(serverID)
+		switch {
+		case err != nil:
+			fmt.Fprintf(&b, "- %s: not connected (failed to start)\n", serverID)
+		case u.Connected():
+			fmt.Fprintf(&b, "- %s: connected\n", serverID)
+		default:
+			status := u.Status()
+			if status.LastError != "" {
+				fmt.Fprintf(&b, "- %s: disconnected: %s\n", serverID, status.LastError)
+			} else {
+				fmt.Fprintf(&b, "- %s: disconnected\n", serverID)
+			}
+		}
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{&mcp.TextContent{Text: b.String()}},
+	}
+}
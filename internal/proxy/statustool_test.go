@@ -0,0 +1,73 @@
+package proxy
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/upstream"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_StatusToolListedOnlyWhenAllServersDown(t *testing.T) {
+	cfg := &config.RootConfig{
+		Hub: config.HubConfig{StatusTool: true},
+		Servers: map[string]config.ServerConfig{
+			"server1": {},
+			"server2": {},
+		},
+		Profiles: map[string]config.ProfileConfig{
+			"test": {
+				Servers: map[string]config.ServerProfileConfig{
+					"server1": {},
+					"server2": {},
+				},
+			},
+		},
+	}
+	ctx := context.Background()
+
+	toolNames := func(session *mcp.ClientSession) []string {
+		t.Helper()
+		result, err := session.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	// Neither server connected, as when both failed at startup
+	down := connectTestClient(t, NewHub(cfg, newTestManager(t), "test").Server())
+	if names := toolNames(down); !slices.Equal(names, []string{StatusToolName}) {
+		t.Fatalf("tools with all servers down = %v, want [%s]", names, StatusToolName)
+	}
+	result, err := down.CallTool(ctx, &mcp.CallToolParams{Name: StatusToolName})
+	if err != nil {
+		t.Fatalf("CallTool failed: %v", err)
+	}
+	text := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"currently unavailable", "server1: not connected", "server2: not connected"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("status text %q missing %q", text, want)
+		}
+	}
+
+	// One server up hides the status tool
+	up := connectTestClient(t, NewHub(cfg, newTestManager(t, newTestUpstream(t, "server1", "tool1")), "test").Server())
+	if names := toolNames(up); slices.Contains(names, StatusToolName) {
+		t.Errorf("tools with a server up = %v, want no %s", names, StatusToolName)
+	}
+
+	// Without hub.statusTool nothing is added
+	cfg.Hub.StatusTool = false
+	off := connectTestClient(t, NewHub(cfg, upstream.NewManager(), "test").Server())
+	if names := toolNames(off); len(names) != 0 {
+		t.Errorf("tools without statusTool = %v, want none", names)
+	}
+}
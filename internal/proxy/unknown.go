@@ -0,0 +1,69 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// hubMethods are the methods the hub answers itself: the ones it proxies
+// with filtering, and the session-level ones the MCP server handles.
+var hubMethods = map[string]bool{
+	"initialize":       true,
+	"ping":             true,
+	"logging/setLevel": true,
+	"tools/list":       true,
+	"tools/call":       true,
+	"resources/list":   true,
+	"resources/read":   true,
+	"prompts/list":     true,
+	"prompts/get":      true,
+}
+
+// errMethodNotFound is the JSON-RPC "method not found" error. Errors
+// wrapping it keep its code.
+var errMethodNotFound = newWireError(-32601, "method not found", nil)
+
+// registerUnknownMethods applies hub.unknownMethod to requests for methods
+// the hub doesn't proxy. Methods the MCP library doesn't know at all are
+// rejected before they get here.
+func (h *Hub) registerUnknownMethods() {
+	mode, serverID := h.config.Hub.UnknownMethodMode()
+	h.server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if hubMethods[method] || strings.HasPrefix(method, "notifications/") {
+				return next(ctx, method, req)
+			}
+			switch mode {
+			case config.UnknownMethodReject:
+				return nil, fmt.Errorf("%w: %q is not proxied by mcp2", errMethodNotFound, method)
+			case config.UnknownMethodForward:
+				return h.forwardMethod(ctx, serverID, method, req)
+			default:
+				h.logger().Info("method not proxied, answering locally", "method", method)
+				return next(ctx, method, req)
+			}
+		}
+	})
+}
+
+// forwardMethod sends a request the hub doesn't proxy to serverID,
+// unfiltered. Only methods with a result the hub can relay are forwarded.
+func (h *Hub) forwardMethod(ctx context.Context, serverID, method string, req mcp.Request) (mcp.Result, error) {
+	u, err := h.resolveUpstream(ctx, serverID)
+	if err != nil {
+		return nil, err
+	}
+	setRequestServer(ctx, serverID)
+
+	switch params := req.GetParams().(type) {
+	case *mcp.CompleteParams:
+		return u.Session().Complete(ctx, params)
+	case *mcp.ListResourceTemplatesParams:
+		return u.Session().ListResourceTemplates(ctx, params)
+	}
+	return nil, fmt.Errorf("%w: %q cannot be forwarded by mcp2", errMethodNotFound, method)
+}
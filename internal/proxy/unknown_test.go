@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/mcp2test"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHub_UnknownMethod(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		setting       string
+		wantTemplates int
+		wantErr       string
+	}{
+		// The hub's own server has no templates
+		{"", 0, ""},
+		{"passthrough", 0, ""},
+		{"reject", 0, "not proxied"},
+		{"forward:server1", 1, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.setting, func(t *testing.T) {
+			cfg := &config.RootConfig{
+				Hub: config.HubConfig{UnknownMethod: tt.setting},
+				Profiles: map[string]config.ProfileConfig{
+					"test": {Servers: map[string]config.ServerProfileConfig{"server1": {}}},
+				},
+			}
+			manager := mcp2test.NewManager(t, mcp2test.Upstream{ID: "server1", Setup: func(server *mcp.Server) {
+				server.AddResourceTemplate(&mcp.ResourceTemplate{Name: "file", URITemplate: "file:///{path}"},
+					func(context.Context, *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+						return &mcp.ReadResourceResult{}, nil
+					})
+			}})
+			session := connectTestClient(t, NewHub(cfg, manager, "test").Server())
+
+			result, err := session.ListResourceTemplates(ctx, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ListResourceTemplates error = %v, want %q", err, tt.wantErr)
+				}
+				if !errors.Is(err, errMethodNotFound) {
+					t.Errorf("ListResourceTemplates error = %v, want method not found", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ListResourceTemplates failed: %v", err)
+			}
+			if len(result.ResourceTemplates) != tt.wantTemplates {
+				t.Errorf("got %d templates, want %d", len(result.ResourceTemplates), tt.wantTemplates)
+			}
+		})
+	}
+}
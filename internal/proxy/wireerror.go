@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"encoding/json"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// wireError is the wire form of a JSON-RPC error.
+type wireError struct {
+	Code    int64           `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// newWireError returns a JSON-RPC error. The SDK doesn't export its error
+// type, so it is decoded from its wire form.
+func newWireError(code int64, message string, data json.RawMessage) error {
+	resp, err := json.Marshal(map[string]any{
+		"jsonrpc": "2.0",
+		"id":      0,
+		"error":   wireError{Code: code, Message: message, Data: data},
+	})
+	if err != nil {
+		panic(err)
+	}
+	msg, err := jsonrpc.DecodeMessage(resp)
+	if err != nil {
+		panic(err)
+	}
+	return msg.(*jsonrpc.Response).Error
+}
+
+// decodeWireError returns the wire form of err if it is, or wraps, a
+// JSON-RPC error, which the SDK only exposes by encoding it.
+func decodeWireError(err error) (wireError, bool) {
+	data, encodeErr := jsonrpc.EncodeMessage(&jsonrpc.Response{Error: err})
+	var resp struct {
+		Error wireError `json:"error"`
+	}
+	if encodeErr != nil || json.Unmarshal(data, &resp) != nil || resp.Error.Code == 0 {
+		return wireError{}, false
+	}
+	return resp.Error, true
+}
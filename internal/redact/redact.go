@@ -0,0 +1,161 @@
+// Package redact masks secret values before they are shown to users or logged.
+package redact
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+// Mask replaces redacted values.
+const Mask = "****"
+
+// sensitiveKeys are exact (case-insensitive) key names that hold secrets.
+var sensitiveKeys = map[string]bool{
+	"authorization":       true,
+	"proxy-authorization": true,
+	"cookie":              true,
+	"set-cookie":          true,
+}
+
+// sensitiveSubstrings mark a key as secret if it contains any of them.
+var sensitiveSubstrings = []string{"TOKEN", "SECRET", "KEY", "PASSWORD", "PASSWD", "CREDENTIAL"}
+
+// IsSensitiveKey reports whether values stored under key should be masked.
+func IsSensitiveKey(key string) bool {
+	if sensitiveKeys[strings.ToLower(key)] {
+		return true
+	}
+	upper := strings.ToUpper(key)
+	for _, s := range sensitiveSubstrings {
+		if strings.Contains(upper, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns value, masked if key is sensitive.
+func Value(key, value string) string {
+	if value == "" || !IsSensitiveKey(key) {
+		return value
+	}
+	return Mask
+}
+
+// Map returns a copy of m with sensitive values masked.
+func Map(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = Value(k, v)
+	}
+	return out
+}
+
+// Secrets returns the values stored under sensitive keys in args, such as
+// tool call arguments, searching nested objects and arrays. Strings and
+// numbers are returned as they would appear in text.
+func Secrets(args map[string]any) []string {
+	var secrets []string
+	var walk func(key string, v any, sensitive bool)
+	walk = func(key string, v any, sensitive bool) {
+		sensitive = sensitive || IsSensitiveKey(key)
+		switch v := v.(type) {
+		case map[string]any:
+			for k, child := range v {
+				walk(k, child, sensitive)
+			}
+		case []any:
+			for _, child := range v {
+				walk(key, child, sensitive)
+			}
+		case string:
+			if sensitive && v != "" {
+				secrets = append(secrets, v)
+			}
+		case float64, int, int64, json.Number:
+			if sensitive {
+				secrets = append(secrets, fmt.Sprint(v))
+			}
+		}
+	}
+	for k, v := range args {
+		walk(k, v, false)
+	}
+	return secrets
+}
+
+// Scrub returns s with every occurrence of the given secrets masked.
+// Longer secrets are masked first so one containing another is fully hidden.
+func Scrub(s string, secrets []string) string {
+	sorted := append([]string(nil), secrets...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+	for _, secret := range sorted {
+		if secret != "" {
+			s = strings.ReplaceAll(s, secret, Mask)
+		}
+	}
+	return s
+}
+
+// URL masks the password in a URL's userinfo and any sensitive query
+// parameters. Unparseable URLs are returned unchanged.
+func URL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || (u.User == nil && u.RawQuery == "") {
+		return raw
+	}
+
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), Mask)
+		}
+	}
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for k, values := range query {
+			for i := range values {
+				values[i] = Value(k, values[i])
+			}
+			query[k] = values
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	// Keep the mask readable rather than percent-encoded.
+	return strings.ReplaceAll(u.String(), url.QueryEscape(Mask), Mask)
+}
+
+// Config returns a copy of cfg with secrets in server transports masked.
+// The original config is not modified.
+func Config(cfg *config.RootConfig) *config.RootConfig {
+	out := *cfg
+	out.Servers = make(map[string]config.ServerConfig, len(cfg.Servers))
+	for id, server := range cfg.Servers {
+		server.Transport.Env = Map(server.Transport.Env)
+		server.Transport.Headers = Map(server.Transport.Headers)
+		server.Transport.URL = URL(server.Transport.URL)
+		if oauth := server.Transport.OAuth; oauth != nil {
+			masked := *oauth
+			masked.ClientSecret = Value("clientSecret", oauth.ClientSecret)
+			server.Transport.OAuth = &masked
+		}
+		out.Servers[id] = server
+	}
+	if cfg.Auth.Tokens != nil {
+		out.Auth.Tokens = make([]config.AuthToken, len(cfg.Auth.Tokens))
+	}
+	for i, token := range cfg.Auth.Tokens {
+		token.Token = Value("token", token.Token)
+		out.Auth.Tokens[i] = token
+	}
+	return &out
+}
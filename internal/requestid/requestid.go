@@ -0,0 +1,35 @@
+// Package requestid generates and propagates request IDs used to correlate
+// logs across mcp2 and upstream servers.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header is the HTTP header carrying the request ID.
+const Header = "X-Request-ID"
+
+// MetaKey is the _meta key carrying the request ID in MCP requests.
+const MetaKey = "mcp2/requestId"
+
+type contextKey struct{}
+
+// New returns a random request ID.
+func New() string {
+	var b [8]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// With returns a context carrying the request ID.
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// From returns the request ID carried by ctx, or "" if there is none.
+func From(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
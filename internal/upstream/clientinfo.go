@@ -0,0 +1,35 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ClientInfoHeader is the HTTP header identifying the downstream client a
+// request to an HTTP upstream is made on behalf of.
+const ClientInfoHeader = "X-MCP2-Client"
+
+// ClientInfoMetaKey is the _meta key identifying the downstream client.
+const ClientInfoMetaKey = "mcp2/client"
+
+type clientInfoKey struct{}
+
+// WithClientInfo returns a context carrying the downstream client's
+// implementation info, which is forwarded to HTTP upstreams.
+func WithClientInfo(ctx context.Context, info *mcp.Implementation) context.Context {
+	return context.WithValue(ctx, clientInfoKey{}, info)
+}
+
+// ClientInfoFrom returns the downstream client info carried by ctx, if any.
+func ClientInfoFrom(ctx context.Context) *mcp.Implementation {
+	info, _ := ctx.Value(clientInfoKey{}).(*mcp.Implementation)
+	return info
+}
+
+// ClientIdentity describes the proxy acting on behalf of a downstream client,
+// e.g. "mcp2-proxy (on behalf of claude-code/1.0.0)".
+func ClientIdentity(info *mcp.Implementation) string {
+	return fmt.Sprintf("mcp2-proxy (on behalf of %s/%s)", info.Name, info.Version)
+}
@@ -0,0 +1,96 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+// ErrDependency is wrapped by ConnectAll's error for a server that wasn't
+// connected because a server in its dependsOn failed.
+var ErrDependency = errors.New("dependency failed to connect")
+
+// ConnectAll connects to servers in parallel, starting each only once the
+// servers in its dependsOn have connected, and at most concurrency at a
+// time (0 = no limit). A dependency outside servers counts as connected if
+// the manager already has it. onDone, if set, is called as each server
+// finishes with how long its connect took, not counting the wait for its
+// dependencies. It returns the error for each server that failed.
+// Dependencies are expected to be acyclic, as Validate ensures.
+func (m *Manager) ConnectAll(ctx context.Context, servers map[string]config.ServerConfig, concurrency int,
+	onDone func(serverID string, elapsed time.Duration, err error)) map[string]error {
+	var sem chan struct{}
+	if concurrency > 0 {
+		sem = make(chan struct{}, concurrency)
+	}
+
+	// done[id] is closed once id has connected or failed, after errs[id]
+	// is set
+	done := make(map[string]chan struct{}, len(servers))
+	for serverID := range servers {
+		done[serverID] = make(chan struct{})
+	}
+	var (
+		mu   sync.Mutex
+		errs = make(map[string]error)
+		wg   sync.WaitGroup
+	)
+
+	connect := func(serverID string, serverCfg config.ServerConfig, start *time.Time) error {
+		for _, dep := range serverCfg.DependsOn {
+			depDone, ok := done[dep]
+			if !ok {
+				if _, err := m.Get(dep); err != nil {
+					return fmt.Errorf("server %q: %w: %q is not connected", serverID, ErrDependency, dep)
+				}
+				continue
+			}
+			select {
+			case <-depDone:
+			case <-ctx.Done():
+				return fmt.Errorf("failed to connect to server %q: %w", serverID, ctx.Err())
+			}
+			mu.Lock()
+			depErr := errs[dep]
+			mu.Unlock()
+			if depErr != nil {
+				return fmt.Errorf("server %q: %w: %q", serverID, ErrDependency, dep)
+			}
+		}
+
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return fmt.Errorf("failed to connect to server %q: %w", serverID, ctx.Err())
+			}
+		}
+		*start = time.Now()
+		return m.Connect(ctx, serverID, &serverCfg)
+	}
+
+	for serverID := range servers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := connect(serverID, servers[serverID], &start)
+			if err != nil {
+				mu.Lock()
+				errs[serverID] = err
+				mu.Unlock()
+			}
+			close(done[serverID])
+			if onDone != nil {
+				onDone(serverID, time.Since(start), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errs
+}
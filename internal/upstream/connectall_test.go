@@ -0,0 +1,149 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// initRecorder records the order in which test servers are initialized and
+// how many initialize at once.
+type initRecorder struct {
+	mu       sync.Mutex
+	order    []string
+	inFlight int
+	peak     int
+}
+
+// newServer starts an MCP server named id whose initialize takes delay.
+func (r *initRecorder) newServer(t *testing.T, id string, delay time.Duration) string {
+	server := mcp.NewServer(&mcp.Implementation{Name: id, Version: "1.0.0"}, nil)
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "initialize" {
+				return next(ctx, method, req)
+			}
+			r.mu.Lock()
+			r.inFlight++
+			r.peak = max(r.peak, r.inFlight)
+			r.mu.Unlock()
+
+			time.Sleep(delay)
+
+			r.mu.Lock()
+			r.inFlight--
+			r.order = append(r.order, id)
+			r.mu.Unlock()
+			return next(ctx, method, req)
+		}
+	})
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func httpServerConfig(url string, dependsOn ...string) config.ServerConfig {
+	return config.ServerConfig{
+		Transport: config.ServerTransportConfig{Kind: "http", URL: url},
+		DependsOn: dependsOn,
+	}
+}
+
+func TestManager_ConnectAllDependencies(t *testing.T) {
+	rec := &initRecorder{}
+	servers := map[string]config.ServerConfig{
+		// base is slow, so app would finish first if it didn't wait
+		"base":  httpServerConfig(rec.newServer(t, "base", 100*time.Millisecond)),
+		"app":   httpServerConfig(rec.newServer(t, "app", 0), "base"),
+		"other": httpServerConfig(rec.newServer(t, "other", 0)),
+	}
+
+	m := NewManager()
+	defer m.Close()
+	errs := m.ConnectAll(context.Background(), servers, 0, nil)
+	if len(errs) != 0 {
+		t.Fatalf("ConnectAll errors = %v", errs)
+	}
+
+	pos := make(map[string]int)
+	for i, id := range rec.order {
+		pos[id] = i
+	}
+	if pos["base"] > pos["app"] {
+		t.Errorf("initialize order = %v, want base before app", rec.order)
+	}
+	if pos["other"] > pos["base"] {
+		t.Errorf("initialize order = %v, want other to connect alongside base", rec.order)
+	}
+	for id := range servers {
+		if _, err := m.Get(id); err != nil {
+			t.Errorf("server %q not connected: %v", id, err)
+		}
+	}
+}
+
+func TestManager_ConnectAllConcurrency(t *testing.T) {
+	rec := &initRecorder{}
+	servers := map[string]config.ServerConfig{
+		"a": httpServerConfig(rec.newServer(t, "a", 30*time.Millisecond)),
+		"b": httpServerConfig(rec.newServer(t, "b", 30*time.Millisecond)),
+		"c": httpServerConfig(rec.newServer(t, "c", 30*time.Millisecond)),
+	}
+
+	m := NewManager()
+	defer m.Close()
+	if errs := m.ConnectAll(context.Background(), servers, 1, nil); len(errs) != 0 {
+		t.Fatalf("ConnectAll errors = %v", errs)
+	}
+	if rec.peak != 1 {
+		t.Errorf("peak concurrent connects = %d, want 1", rec.peak)
+	}
+}
+
+func TestManager_ConnectAllFailedDependency(t *testing.T) {
+	// A listener that is closed again leaves a port nothing answers on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := "http://" + l.Addr().String() + "/mcp"
+	l.Close()
+
+	rec := &initRecorder{}
+	servers := map[string]config.ServerConfig{
+		"base": httpServerConfig(unreachable),
+		"app":  httpServerConfig(rec.newServer(t, "app", 0), "base"),
+	}
+
+	m := NewManager()
+	defer m.Close()
+	var mu sync.Mutex
+	reported := make(map[string]error)
+	errs := m.ConnectAll(context.Background(), servers, 0, func(serverID string, _ time.Duration, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		reported[serverID] = err
+	})
+
+	if !errors.Is(errs["base"], ErrDial) {
+		t.Errorf("base error = %v, want %v", errs["base"], ErrDial)
+	}
+	if !errors.Is(errs["app"], ErrDependency) {
+		t.Errorf("app error = %v, want %v", errs["app"], ErrDependency)
+	}
+	if len(rec.order) != 0 {
+		t.Errorf("app was initialized despite its dependency failing")
+	}
+	if len(reported) != 2 || reported["app"] == nil {
+		t.Errorf("onDone reports = %v, want both servers with errors", reported)
+	}
+}
@@ -0,0 +1,92 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Connect and Reconnect errors wrap one of these, telling a server that
+// could not be reached (its command didn't start, or the network failed)
+// apart from one that was reached but failed the MCP initialize handshake
+// (protocol version mismatch, rejected credentials, not an MCP server).
+var (
+	ErrDial       = errors.New("cannot reach server")
+	ErrInitialize = errors.New("MCP initialization failed")
+)
+
+// dialRecorder notes whether connecting failed before the server answered.
+// The SDK flattens transport errors into the initialize error, so this is
+// recorded on the way through instead of recovered from the error.
+type dialRecorder struct {
+	mu  sync.Mutex
+	err error
+}
+
+// wrap returns transport with its connection and, for HTTP, its requests
+// observed by r.
+func (r *dialRecorder) wrap(transport mcp.Transport) mcp.Transport {
+	if t, ok := transport.(*mcp.StreamableClientTransport); ok && t.HTTPClient != nil {
+		client := *t.HTTPClient
+		base := client.Transport
+		if base == nil {
+			base = http.DefaultTransport
+		}
+		client.Transport = &dialRecordingRoundTripper{base: base, rec: r}
+		t.HTTPClient = &client
+	}
+	return &dialRecordingTransport{Transport: transport, rec: r}
+}
+
+func (r *dialRecorder) fail(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// classify wraps err, from connecting a client over a wrapped transport,
+// in ErrDial or ErrInitialize.
+func (r *dialRecorder) classify(err error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return fmt.Errorf("%w: %w", ErrDial, err)
+	}
+	return fmt.Errorf("%w: %w", ErrInitialize, err)
+}
+
+type dialRecordingTransport struct {
+	mcp.Transport
+	rec *dialRecorder
+}
+
+func (t *dialRecordingTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		t.rec.fail(err)
+	}
+	return conn, err
+}
+
+// dialRecordingRoundTripper records network errors. HTTP error responses
+// mean the server was reached, so they are left to initialize.
+type dialRecordingRoundTripper struct {
+	base http.RoundTripper
+	rec  *dialRecorder
+}
+
+func (rt *dialRecordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := rt.base.RoundTrip(req)
+	var netErr net.Error
+	if err != nil && errors.As(err, &netErr) {
+		rt.rec.fail(err)
+	}
+	return resp, err
+}
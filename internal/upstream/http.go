@@ -0,0 +1,201 @@
+package upstream
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/requestid"
+)
+
+// headerTransport adds static headers, and the request ID and downstream
+// client info from the request's context, to every request before delegating
+// to the wrapped RoundTripper.
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := requestid.From(req.Context())
+	client := ClientInfoFrom(req.Context())
+	if len(t.headers) == 0 && id == "" && client == nil {
+		return t.base.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the caller's request
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	if id != "" {
+		req.Header.Set(requestid.Header, id)
+	}
+	if client != nil {
+		req.Header.Set(ClientInfoHeader, ClientIdentity(client))
+	}
+	return t.base.RoundTrip(req)
+}
+
+// defaultMaxRetryWait caps Retry-After delays when MaxRetryWait is unset.
+const defaultMaxRetryWait = 30 * time.Second
+
+// retryTransport retries requests that were rejected with 429 Too Many
+// Requests or 503 Service Unavailable and a Retry-After header, waiting the
+// indicated delay. A 429 means the request was not processed, so any request
+// with a replayable body is retried; 503 is only retried for idempotent methods.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	maxWait    time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err != nil || attempt >= t.maxRetries || !t.shouldRetry(req, resp) {
+			return resp, err
+		}
+
+		delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		if !ok || delay > t.maxWait {
+			return resp, nil
+		}
+
+		next, err := rewindRequest(req)
+		if err != nil {
+			return resp, nil
+		}
+
+		// Release the connection before waiting
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		req = next
+	}
+}
+
+func (t *retryTransport) shouldRetry(req *http.Request, resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return isIdempotent(req.Method)
+	default:
+		return false
+	}
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodDelete, http.MethodPut:
+		return true
+	default:
+		return false
+	}
+}
+
+// rewindRequest returns a copy of req with a fresh body for resending.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	next := req.Clone(req.Context())
+	if req.Body == nil || req.Body == http.NoBody {
+		return next, nil
+	}
+	if req.GetBody == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	next.Body = body
+	return next, nil
+}
+
+// parseRetryAfter parses a Retry-After header given as delay-seconds or an
+// HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if when, err := http.ParseTime(value); err == nil {
+		delay := when.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// newUpstreamRoundTripper layers retries on top of a pooled transport
+// according to the given settings.
+func newUpstreamRoundTripper(base http.RoundTripper, hc config.HTTPClientConfig) http.RoundTripper {
+	if hc.MaxRetries <= 0 {
+		return base
+	}
+	maxWait := defaultMaxRetryWait
+	if hc.MaxRetryWait > 0 {
+		maxWait = time.Duration(hc.MaxRetryWait) * time.Second
+	}
+	return &retryTransport{base: base, maxRetries: hc.MaxRetries, maxWait: maxWait}
+}
+
+// newPooledTransport builds an http.Transport with the given pool settings
+// applied on top of Go's defaults.
+func newPooledTransport(hc config.HTTPClientConfig) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if hc.MaxIdleConns > 0 {
+		t.MaxIdleConns = hc.MaxIdleConns
+	}
+	if hc.MaxIdleConnsPerHost > 0 {
+		t.MaxIdleConnsPerHost = hc.MaxIdleConnsPerHost
+	}
+	if hc.MaxConnsPerHost > 0 {
+		t.MaxConnsPerHost = hc.MaxConnsPerHost
+	}
+	if hc.IdleConnTimeout > 0 {
+		t.IdleConnTimeout = time.Duration(hc.IdleConnTimeout) * time.Second
+	}
+	return t
+}
+
+// mergeHTTPClientConfig returns base with any non-zero fields of override applied.
+func mergeHTTPClientConfig(base, override config.HTTPClientConfig) config.HTTPClientConfig {
+	if override.MaxIdleConns > 0 {
+		base.MaxIdleConns = override.MaxIdleConns
+	}
+	if override.MaxIdleConnsPerHost > 0 {
+		base.MaxIdleConnsPerHost = override.MaxIdleConnsPerHost
+	}
+	if override.MaxConnsPerHost > 0 {
+		base.MaxConnsPerHost = override.MaxConnsPerHost
+	}
+	if override.IdleConnTimeout > 0 {
+		base.IdleConnTimeout = override.IdleConnTimeout
+	}
+	if override.MaxRetries > 0 {
+		base.MaxRetries = override.MaxRetries
+	}
+	if override.MaxRetryWait > 0 {
+		base.MaxRetryWait = override.MaxRetryWait
+	}
+	return base
+}
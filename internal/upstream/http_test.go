@@ -0,0 +1,228 @@
+package upstream
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/requestid"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestHeaderTransport_AddsHeaders(t *testing.T) {
+	var gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &headerTransport{
+		base:    http.DefaultTransport,
+		headers: map[string]string{"Authorization": "Bearer abc"},
+	}}
+
+	req, _ := http.NewRequest("GET", srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotAuth != "Bearer abc" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer abc")
+	}
+	if req.Header.Get("Authorization") != "" {
+		t.Error("Original request should not be modified")
+	}
+}
+
+func TestHeaderTransport_AddsRequestID(t *testing.T) {
+	var gotID string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = r.Header.Get(requestid.Header)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &headerTransport{base: http.DefaultTransport}}
+
+	ctx := requestid.With(context.Background(), "abc123")
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotID != "abc123" {
+		t.Errorf("%s = %q, want %q", requestid.Header, gotID, "abc123")
+	}
+}
+
+func TestCreateHTTPTransport_SharesPool(t *testing.T) {
+	m := NewManager()
+	m.SetHTTPClientConfig(config.HTTPClientConfig{MaxIdleConnsPerHost: 7, IdleConnTimeout: 30})
+
+	baseOf := func(serverCfg *config.ServerConfig) *http.Transport {
+		t.Helper()
+		transport, err := m.createHTTPTransport(serverCfg)
+		if err != nil {
+			t.Fatalf("createHTTPTransport failed: %v", err)
+		}
+		ht := transport.(*mcp.StreamableClientTransport).HTTPClient.Transport.(*headerTransport)
+		return ht.base.(*http.Transport)
+	}
+
+	a := baseOf(&config.ServerConfig{Transport: config.ServerTransportConfig{Kind: "http", URL: "http://a/mcp"}})
+	b := baseOf(&config.ServerConfig{Transport: config.ServerTransportConfig{Kind: "http", URL: "http://b/mcp"}})
+	if a != b {
+		t.Error("Expected HTTP upstreams to share a transport")
+	}
+	if a.MaxIdleConnsPerHost != 7 {
+		t.Errorf("MaxIdleConnsPerHost = %d, want 7", a.MaxIdleConnsPerHost)
+	}
+
+	c := baseOf(&config.ServerConfig{Transport: config.ServerTransportConfig{
+		Kind:       "http",
+		URL:        "http://c/mcp",
+		HTTPClient: config.HTTPClientConfig{MaxConnsPerHost: 2},
+	}})
+	if c == a {
+		t.Error("Expected per-server override to get a dedicated transport")
+	}
+	if c.MaxConnsPerHost != 2 || c.MaxIdleConnsPerHost != 7 {
+		t.Errorf("Override not merged with hub settings: MaxConnsPerHost=%d MaxIdleConnsPerHost=%d",
+			c.MaxConnsPerHost, c.MaxIdleConnsPerHost)
+	}
+}
+
+func TestRetryTransport_HonorsRetryAfter(t *testing.T) {
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("Attempt %d body = %q, want %q", attempts, body, "payload")
+		}
+		if attempts < 3 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: newUpstreamRoundTripper(http.DefaultTransport, config.HTTPClientConfig{MaxRetries: 3})}
+	resp, err := client.Post(srv.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || attempts != 3 {
+		t.Errorf("Status = %d after %d attempts, want 200 after 3", resp.StatusCode, attempts)
+	}
+}
+
+func TestRetryTransport_DoesNotRetry(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		status     int
+		retryAfter string
+	}{
+		{"no Retry-After", http.MethodPost, http.StatusTooManyRequests, ""},
+		{"wait exceeds cap", http.MethodPost, http.StatusTooManyRequests, "3600"},
+		{"503 on POST", http.MethodPost, http.StatusServiceUnavailable, "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if tt.retryAfter != "" {
+					w.Header().Set("Retry-After", tt.retryAfter)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			client := &http.Client{Transport: newUpstreamRoundTripper(http.DefaultTransport, config.HTTPClientConfig{MaxRetries: 3})}
+			req, _ := http.NewRequest(tt.method, srv.URL, strings.NewReader("x"))
+			resp, err := client.Do(req)
+			if err != nil {
+				t.Fatalf("Request failed: %v", err)
+			}
+			resp.Body.Close()
+
+			if attempts != 1 || resp.StatusCode != tt.status {
+				t.Errorf("Got status %d after %d attempts, want %d after 1", resp.StatusCode, attempts, tt.status)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_RespectsContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := &http.Client{Transport: newUpstreamRoundTripper(http.DefaultTransport, config.HTTPClientConfig{MaxRetries: 3})}
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL, nil)
+
+	start := time.Now()
+	if _, err := client.Do(req); err == nil {
+		t.Fatal("Expected context error")
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Retry did not stop when the context was cancelled")
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if d, ok := parseRetryAfter("5", now); !ok || d != 5*time.Second {
+		t.Errorf("parseRetryAfter(5) = %v, %v", d, ok)
+	}
+	if d, ok := parseRetryAfter(now.Add(2*time.Second).Format(http.TimeFormat), now); !ok || d != 2*time.Second {
+		t.Errorf("parseRetryAfter(date) = %v, %v", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon", now); ok {
+		t.Error("Expected invalid Retry-After to be rejected")
+	}
+}
+
+func TestHeaderTransport_AddsClientInfo(t *testing.T) {
+	var gotClient string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotClient = r.Header.Get(ClientInfoHeader)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: &headerTransport{base: http.DefaultTransport}}
+
+	ctx := WithClientInfo(context.Background(), &mcp.Implementation{Name: "claude-code", Version: "1.0.0"})
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL, nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	want := "mcp2-proxy (on behalf of claude-code/1.0.0)"
+	if gotClient != want {
+		t.Errorf("%s = %q, want %q", ClientInfoHeader, gotClient, want)
+	}
+}
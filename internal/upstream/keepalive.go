@@ -0,0 +1,64 @@
+package upstream
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// keepalive pings the upstream whenever interval passes without a request,
+// so intermediaries don't drop an idle connection. It returns when the
+// session ends. Failed pings are logged; reconnecting is left to whoever
+// watches the session.
+func (u *Upstream) keepalive(interval time.Duration, logger *slog.Logger) {
+	u.mu.Lock()
+	session := u.session
+	u.mu.Unlock()
+	if session == nil {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		session.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(interval)
+	defer timer.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-timer.C:
+		}
+
+		idle := u.idleFor()
+		if idle < interval {
+			timer.Reset(interval - idle)
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := session.Ping(ctx, nil)
+		cancel()
+		if err != nil {
+			logger.Warn("keepalive ping failed", "server", u.ID, "error", err)
+		} else {
+			logger.Debug("keepalive ping", "server", u.ID, "idle", idle)
+		}
+		timer.Reset(interval)
+	}
+}
+
+// idleFor returns how long it has been since the last request was sent to
+// the upstream, or since it connected if none has been.
+func (u *Upstream) idleFor() time.Duration {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	last := u.lastRequest
+	if last.IsZero() {
+		last = u.connectedAt
+	}
+	return time.Since(last)
+}
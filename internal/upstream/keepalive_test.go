@@ -0,0 +1,89 @@
+package upstream
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newKeepaliveUpstream connects an upstream whose server counts the pings it
+// receives.
+func newKeepaliveUpstream(t *testing.T) (*Upstream, *atomic.Int32) {
+	t.Helper()
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	var pings atomic.Int32
+	server := mcp.NewServer(&mcp.Implementation{Name: "remote", Version: "1.0.0"}, nil)
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "ping" {
+				pings.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	u := &Upstream{ID: "remote"}
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	client.AddSendingMiddleware(u.limitRequests)
+	u.session, err = client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	u.watch(func(error) {})
+	return u, &pings
+}
+
+func TestKeepalive_PingsIdleUpstream(t *testing.T) {
+	u, pings := newKeepaliveUpstream(t)
+
+	stopped := make(chan struct{})
+	go func() {
+		u.keepalive(20*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		close(stopped)
+	}()
+
+	time.Sleep(110 * time.Millisecond)
+	if n := pings.Load(); n < 2 {
+		t.Errorf("Expected an idle upstream to be pinged repeatedly, got %d pings", n)
+	}
+
+	u.Session().Close()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Expected keepalive to stop when the session closed")
+	}
+}
+
+func TestKeepalive_SkipsBusyUpstream(t *testing.T) {
+	u, pings := newKeepaliveUpstream(t)
+	t.Cleanup(func() { u.Session().Close() })
+
+	go u.keepalive(50*time.Millisecond, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx := context.Background()
+	deadline := time.Now().Add(150 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if _, err := u.Session().ListTools(ctx, nil); err != nil {
+			t.Fatalf("ListTools failed: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if n := pings.Load(); n != 0 {
+		t.Errorf("Expected no keepalive pings while requests are flowing, got %d", n)
+	}
+}
@@ -0,0 +1,80 @@
+package upstream
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyWindow is how many recent request latencies each upstream keeps.
+const latencyWindow = 1024
+
+// latencies is a fixed-size ring of an upstream's most recent request
+// latencies, safe for concurrent use.
+type latencies struct {
+	mu      sync.Mutex
+	samples [latencyWindow]time.Duration
+	next    int
+	full    bool
+}
+
+// observe records one request latency, evicting the oldest once the window
+// is full.
+func (l *latencies) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.samples[l.next] = d
+	l.next++
+	if l.next == latencyWindow {
+		l.next = 0
+		l.full = true
+	}
+}
+
+// LatencySummary gives percentiles of an upstream's recent request
+// latencies, in milliseconds.
+type LatencySummary struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50Ms"`
+	P90   float64 `json:"p90Ms"`
+	P99   float64 `json:"p99Ms"`
+	Max   float64 `json:"maxMs"`
+}
+
+// summary returns percentiles over the current window, or nil if no request
+// has completed.
+func (l *latencies) summary() *LatencySummary {
+	l.mu.Lock()
+	n := l.next
+	if l.full {
+		n = latencyWindow
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, l.samples[:n])
+	l.mu.Unlock()
+
+	if n == 0 {
+		return nil
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	// Nearest-rank percentiles
+	rank := func(p int) float64 {
+		r := (p*n + 99) / 100
+		if r < 1 {
+			r = 1
+		}
+		return milliseconds(sorted[r-1])
+	}
+	return &LatencySummary{
+		Count: n,
+		P50:   rank(50),
+		P90:   rank(90),
+		P99:   rank(99),
+		Max:   milliseconds(sorted[n-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
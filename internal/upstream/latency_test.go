@@ -0,0 +1,36 @@
+package upstream
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLatencies_Summary(t *testing.T) {
+	var l latencies
+	if s := l.summary(); s != nil {
+		t.Fatalf("Expected no summary before any request, got %+v", s)
+	}
+
+	for i := 1; i <= 100; i++ {
+		l.observe(time.Duration(i) * time.Millisecond)
+	}
+	s := l.summary()
+	if s.Count != 100 || s.P50 != 50 || s.P90 != 90 || s.P99 != 99 || s.Max != 100 {
+		t.Errorf("summary() = %+v, want count 100, p50 50, p90 90, p99 99, max 100", s)
+	}
+}
+
+func TestLatencies_WindowIsBounded(t *testing.T) {
+	var l latencies
+	for i := 0; i < latencyWindow; i++ {
+		l.observe(time.Second)
+	}
+	for i := 0; i < latencyWindow; i++ {
+		l.observe(time.Millisecond)
+	}
+
+	s := l.summary()
+	if s.Count != latencyWindow || s.Max != 1 {
+		t.Errorf("summary() = %+v, want only the latest %d samples of 1ms", s, latencyWindow)
+	}
+}
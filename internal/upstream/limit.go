@@ -0,0 +1,109 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrOverloaded is wrapped by the error for a request refused because the
+// upstream's request queue is full or the request waited too long in it.
+var ErrOverloaded = errors.New("server overloaded")
+
+// setConcurrencyLimit limits the upstream to n requests in flight at once
+// (0 = unlimited), with at most maxQueued waiting for a slot and each
+// waiting at most maxWait (0 = no bound). It must be called before the
+// session sends requests.
+func (u *Upstream) setConcurrencyLimit(n, maxQueued int, maxWait time.Duration) {
+	if n > 0 {
+		u.sem = make(chan struct{}, n)
+		u.maxQueued = maxQueued
+		u.maxQueueWait = maxWait
+	}
+}
+
+// acquire takes one of the upstream's request slots, queueing for one if
+// none is free. It fails with ErrOverloaded if the queue is full or no slot
+// frees up within maxQueueWait. How long each request waited is recorded.
+func (u *Upstream) acquire(ctx context.Context) error {
+	select {
+	case u.sem <- struct{}{}:
+		u.queueWait.observe(0)
+		return nil
+	default:
+	}
+
+	u.mu.Lock()
+	if u.maxQueued > 0 && u.queued >= u.maxQueued {
+		u.rejected++
+		u.mu.Unlock()
+		return fmt.Errorf("server %q: %w: %d requests already queued", u.ID, ErrOverloaded, u.maxQueued)
+	}
+	u.queued++
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		u.queued--
+		u.mu.Unlock()
+	}()
+
+	var timeout <-chan time.Time
+	if u.maxQueueWait > 0 {
+		timer := time.NewTimer(u.maxQueueWait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	start := time.Now()
+	select {
+	case u.sem <- struct{}{}:
+		u.queueWait.observe(time.Since(start))
+		return nil
+	case <-timeout:
+		u.mu.Lock()
+		u.rejected++
+		u.mu.Unlock()
+		u.queueWait.observe(time.Since(start))
+		return fmt.Errorf("server %q: %w: no free request slot within %s", u.ID, ErrOverloaded, u.maxQueueWait)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// limitRequests is client sending middleware that counts in-flight requests,
+// records when the last one was sent and how long the upstream took to
+// answer, and, when a limit is set, queues requests beyond it until a slot
+// frees up, the request's context is done, or acquire gives up. Time spent
+// queued is not counted as latency.
+func (u *Upstream) limitRequests(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		if strings.HasPrefix(method, "notifications/") {
+			return next(ctx, method, req)
+		}
+
+		u.mu.Lock()
+		u.inFlight++
+		u.lastRequest = time.Now()
+		u.mu.Unlock()
+		defer func() {
+			u.mu.Lock()
+			u.inFlight--
+			u.mu.Unlock()
+		}()
+
+		if u.sem != nil {
+			if err := u.acquire(ctx); err != nil {
+				return nil, err
+			}
+			defer func() { <-u.sem }()
+		}
+
+		start := time.Now()
+		result, err := next(ctx, method, req)
+		u.latency.observe(time.Since(start))
+		return result, err
+	}
+}
@@ -0,0 +1,166 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestLimitRequests_QueuesBeyondLimit(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	var running, maxRunning atomic.Int32
+	release := make(chan struct{})
+	server := mcp.NewServer(&mcp.Implementation{Name: "fragile", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "work"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			cur := maxRunning.Load()
+			if n <= cur || maxRunning.CompareAndSwap(cur, n) {
+				break
+			}
+		}
+		<-release
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer serverSession.Close()
+
+	u := &Upstream{ID: "fragile"}
+	u.setConcurrencyLimit(1, 0, 0)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	client.AddSendingMiddleware(u.limitRequests)
+	u.session, err = client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer u.Session().Close()
+
+	var releaseOnce sync.Once
+	defer releaseOnce.Do(func() { close(release) })
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := u.Session().CallTool(ctx, &mcp.CallToolParams{Name: "work"}); err != nil {
+				t.Errorf("CallTool failed: %v", err)
+			}
+		}()
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for u.Status().InFlight != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("InFlight = %d, want 3", u.Status().InFlight)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A queued request gives up when its context is done
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := u.Session().CallTool(timeoutCtx, &mcp.CallToolParams{Name: "work"}); err == nil {
+		t.Error("Expected queued call to fail when its context expires")
+	}
+
+	releaseOnce.Do(func() { close(release) })
+	wg.Wait()
+
+	if got := maxRunning.Load(); got != 1 {
+		t.Errorf("Max concurrent calls = %d, want 1", got)
+	}
+	if got := u.Status().InFlight; got != 0 {
+		t.Errorf("InFlight after completion = %d, want 0", got)
+	}
+}
+
+func TestLimitRequests_QueueBounds(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	release := make(chan struct{})
+	server := mcp.NewServer(&mcp.Implementation{Name: "slow", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "work"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		<-release
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer serverSession.Close()
+
+	u := &Upstream{ID: "slow"}
+	u.setConcurrencyLimit(1, 1, 50*time.Millisecond)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	client.AddSendingMiddleware(u.limitRequests)
+	u.session, err = client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer u.Session().Close()
+
+	var releaseOnce sync.Once
+	defer releaseOnce.Do(func() { close(release) })
+
+	// One call holds the only slot
+	done := make(chan error, 1)
+	go func() {
+		_, err := u.Session().CallTool(ctx, &mcp.CallToolParams{Name: "work"})
+		done <- err
+	}()
+	deadline := time.Now().Add(time.Second)
+	for u.Status().InFlight != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("InFlight = %d, want 1", u.Status().InFlight)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A second call queues, and gives up after maxQueueWait; a third,
+	// arriving while the queue is full, fails at once
+	queued := make(chan error, 1)
+	go func() {
+		_, err := u.Session().CallTool(ctx, &mcp.CallToolParams{Name: "work"})
+		queued <- err
+	}()
+	for u.Status().Queued != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Queued = %d, want 1", u.Status().Queued)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := u.Session().CallTool(ctx, &mcp.CallToolParams{Name: "work"}); !errors.Is(err, ErrOverloaded) {
+		t.Errorf("call with a full queue: error = %v, want ErrOverloaded", err)
+	}
+	if err := <-queued; !errors.Is(err, ErrOverloaded) {
+		t.Errorf("call queued past maxQueueWait: error = %v, want ErrOverloaded", err)
+	}
+
+	releaseOnce.Do(func() { close(release) })
+	if err := <-done; err != nil {
+		t.Errorf("call holding the slot failed: %v", err)
+	}
+
+	status := u.Status()
+	if status.Queued != 0 || status.Rejected != 2 {
+		t.Errorf("Queued, Rejected = %d, %d; want 0, 2", status.Queued, status.Rejected)
+	}
+	// initialize and the first call found a free slot; the queued call
+	// waited out maxQueueWait
+	if status.QueueWait == nil || status.QueueWait.Count != 3 || status.QueueWait.Max < 50 {
+		t.Errorf("QueueWait = %+v, want 3 samples, the longest at least 50ms", status.QueueWait)
+	}
+}
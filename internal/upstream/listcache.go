@@ -0,0 +1,124 @@
+package upstream
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// maxCachedItems bounds how many definitions of each kind are cached per
+// upstream. Items beyond it are looked up on the upstream each time.
+const maxCachedItems = 4096
+
+// listable is a definition an upstream lists and listCache caches.
+type listable interface {
+	mcp.Tool | mcp.Resource
+}
+
+// cacheKey returns what item is looked up by: a tool's name or a
+// resource's URI.
+func cacheKey[T listable](item *T) string {
+	switch v := any(item).(type) {
+	case *mcp.Tool:
+		return v.Name
+	case *mcp.Resource:
+		return v.URI
+	}
+	return ""
+}
+
+// listCache holds the definitions from an upstream's most recent listing of
+// one kind.
+type listCache[T listable] struct {
+	mu    sync.RWMutex
+	items map[string]*T
+	// complete is set once every page of a listing has been cached, so a
+	// key missing from it is known not to exist.
+	complete bool
+	// full is set when a listing had more items than maxCachedItems.
+	full bool
+
+	// hits and misses count lookups the cache could and couldn't answer,
+	// and evictions the definitions dropped by reset.
+	hits, misses, evictions atomic.Uint64
+}
+
+// CacheStats counts how well one of an upstream's definition caches is
+// working.
+type CacheStats struct {
+	// Hits counts lookups answered from the cache, including names it
+	// knows the upstream doesn't have; Misses counts lookups that had to
+	// list the upstream.
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	// Evictions counts cached definitions dropped because the upstream's
+	// list changed or it reconnected.
+	Evictions uint64 `json:"evictions"`
+	// Size is how many definitions are cached now.
+	Size int `json:"size"`
+}
+
+// reset drops the cached definitions, as when the upstream's list changes
+// or it reconnects.
+func (c *listCache[T]) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictions.Add(uint64(len(c.items)))
+	c.items, c.complete, c.full = nil, false, false
+}
+
+// store caches a page of a list result. The first page replaces what was
+// cached; the last marks the cache complete. Items are copied, since the
+// hub rewrites the results it lists.
+func (c *listCache[T]) store(items []*T, first, last bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if first {
+		c.items, c.complete, c.full = make(map[string]*T, len(items)), false, false
+	}
+	if c.items == nil {
+		// A later page of a listing that started before a reset
+		return
+	}
+	for _, item := range items {
+		if len(c.items) >= maxCachedItems {
+			c.full = true
+			break
+		}
+		copied := *item
+		c.items[cacheKey(item)] = &copied
+	}
+	c.complete = last && !c.full
+}
+
+// lookup returns a copy of the cached definition for key. known is false if
+// the cache can't tell whether the upstream has it.
+func (c *listCache[T]) lookup(key string) (item *T, known bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if v, ok := c.items[key]; ok {
+		c.hits.Add(1)
+		copied := *v
+		return &copied, true
+	}
+	if c.complete {
+		c.hits.Add(1)
+	} else {
+		c.misses.Add(1)
+	}
+	return nil, c.complete
+}
+
+// stats returns the cache's counters and current size.
+func (c *listCache[T]) stats() CacheStats {
+	c.mu.RLock()
+	size := len(c.items)
+	c.mu.RUnlock()
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+		Size:      size,
+	}
+}
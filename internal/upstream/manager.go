@@ -3,11 +3,18 @@ package upstream
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
 	"os/exec"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/ain3sh/mcp2/internal/version"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
@@ -15,16 +22,103 @@ import (
 type Upstream struct {
 	ID          string
 	DisplayName string
-	Session     *mcp.ClientSession
 	Config      *config.ServerConfig
+
+	// mu guards the session, which Reconnect replaces, and the connection
+	// state reported by Status.
+	mu           sync.Mutex
+	session      *mcp.ClientSession
+	connectedAt  time.Time
+	disconnected bool
+	lastErr      error
+	restarts     int
+	inFlight     int
+	lastRequest  time.Time
+
+	// latency holds the most recent request latencies.
+	latency latencies
+
+	// tools caches the tool definitions from the most recent tools/list;
+	// see Tool.
+	tools *toolCache
+	// resources likewise caches resource definitions; see Resource.
+	resources *resourceCache
+
+	// sem bounds concurrent requests when maxConcurrentRequests is set.
+	sem chan struct{}
+	// maxQueued and maxQueueWait bound the requests waiting for a slot in
+	// sem; queued and rejected count those waiting and those refused.
+	maxQueued    int
+	maxQueueWait time.Duration
+	queued       int
+	rejected     uint64
+	// queueWait holds how long the most recent requests waited for a slot.
+	queueWait latencies
+
+	// client is created once per upstream and shared by all its sessions, so
+	// its handlers and middleware survive reconnects; dial opens a new
+	// session on a fresh transport.
+	client *mcp.Client
+	dial   func(ctx context.Context) (*mcp.ClientSession, error)
+}
+
+// NewUpstream returns an upstream known by id for an already-connected
+// session, to register with Manager.Add.
+func NewUpstream(id string, session *mcp.ClientSession) *Upstream {
+	return &Upstream{ID: id, session: session}
+}
+
+// Session returns the upstream's current session. Reconnect replaces it,
+// so callers should fetch it for each request rather than keep it.
+func (u *Upstream) Session() *mcp.ClientSession {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.session
 }
 
 // Manager manages multiple upstream MCP server connections.
 type Manager struct {
 	upstreams map[string]*Upstream
 	mu        sync.RWMutex
+
+	// connecting holds the IDs of servers whose Connect is in progress.
+	connecting map[string]bool
+
+	// httpClientConfig and httpTransport hold the connection pool shared
+	// by all HTTP upstreams without per-server overrides.
+	httpClientConfig config.HTTPClientConfig
+	httpTransport    *http.Transport
+
+	// cleanEnv starts stdio servers without inheritEnv set from an empty
+	// environment.
+	cleanEnv bool
+
+	log *slog.Logger
+
+	callbacksMu  sync.Mutex
+	onConnect    []func(serverID string)
+	onDisconnect []func(serverID string, err error)
+	onProgress   []func(serverID string, params *mcp.ProgressNotificationParams)
+	onLog        []func(serverID string, params *mcp.LoggingMessageParams)
+	onListChange []func(serverID string, kind ListKind)
+
+	// elicitation and sampling make upstream clients advertise those
+	// capabilities; onElicit and onCreateMessage answer the requests.
+	elicitation     bool
+	onElicit        []func(ctx context.Context, serverID string, params *mcp.ElicitParams) (*mcp.ElicitResult, error)
+	sampling        bool
+	onCreateMessage []func(ctx context.Context, serverID string, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error)
 }
 
+// ListKind names a list an upstream can report as changed.
+type ListKind string
+
+const (
+	ListTools     ListKind = "tools"
+	ListResources ListKind = "resources"
+	ListPrompts   ListKind = "prompts"
+)
+
 // NewManager creates a new upstream manager.
 func NewManager() *Manager {
 	return &Manager{
@@ -32,53 +126,338 @@ func NewManager() *Manager {
 	}
 }
 
-// Connect establishes a connection to an upstream server.
-func (m *Manager) Connect(ctx context.Context, serverID string, serverCfg *config.ServerConfig) error {
+// SetHTTPClientConfig sets the connection pool settings shared by all HTTP
+// upstreams. It must be called before connecting to any server.
+func (m *Manager) SetHTTPClientConfig(hc config.HTTPClientConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.httpClientConfig = hc
+	m.httpTransport = newPooledTransport(hc)
+}
+
+// SetInheritEnv sets whether stdio servers that don't set inheritEnv get
+// mcp2's environment. It is true by default.
+func (m *Manager) SetInheritEnv(inherit bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cleanEnv = !inherit
+}
+
+// SetLogger sets the logger used by the manager. By default it logs to
+// slog.Default().
+func (m *Manager) SetLogger(logger *slog.Logger) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.log = logger
+}
+
+// logger returns the manager's logger, falling back to slog.Default().
+func (m *Manager) logger() *slog.Logger {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.log != nil {
+		return m.log
+	}
+	return slog.Default()
+}
+
+// OnConnect registers fn to be called whenever an upstream connects.
+// Callbacks run in their own goroutine, so they may block.
+func (m *Manager) OnConnect(fn func(serverID string)) {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.onConnect = append(m.onConnect, fn)
+}
+
+// OnDisconnect registers fn to be called whenever an upstream's session ends,
+// whether it dropped or was closed. err is nil for a clean close.
+// Callbacks run in their own goroutine, so they may block.
+func (m *Manager) OnDisconnect(fn func(serverID string, err error)) {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.onDisconnect = append(m.onDisconnect, fn)
+}
+
+// OnProgress registers fn to be called for every progress notification sent
+// by an upstream. Callbacks run synchronously, in order, on the upstream's
+// notification path, so they must not block.
+func (m *Manager) OnProgress(fn func(serverID string, params *mcp.ProgressNotificationParams)) {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.onProgress = append(m.onProgress, fn)
+}
+
+// OnLog registers fn to be called for every log message
+// (notifications/message) sent by an upstream. Like OnProgress callbacks, it
+// runs synchronously on the notification path and must not block.
+func (m *Manager) OnLog(fn func(serverID string, params *mcp.LoggingMessageParams)) {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.onLog = append(m.onLog, fn)
+}
+
+// EnableElicitation makes upstreams advertise the elicitation capability, so
+// they can send elicitation/create requests, answered by the handler set with
+// OnElicit. It must be called before connecting to any server.
+func (m *Manager) EnableElicitation() {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.elicitation = true
+}
+
+// ErrUnclaimed is returned by an OnElicit or OnCreateMessage handler that
+// has no client to forward the request to, letting the next handler try.
+var ErrUnclaimed = errors.New("no client request in flight")
+
+// OnElicit registers fn to answer elicitation/create requests from upstreams.
+// Handlers are tried in registration order until one returns something other
+// than ErrUnclaimed. Unlike notification callbacks, fn may block
+// until the request is answered. Requests are rejected until a handler is set.
+func (m *Manager) OnElicit(fn func(ctx context.Context, serverID string, params *mcp.ElicitParams) (*mcp.ElicitResult, error)) {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.onElicit = append(m.onElicit, fn)
+}
+
+// EnableSampling makes upstream clients advertise the sampling capability,
+// so upstreams may send sampling/createMessage requests, answered by
+// OnCreateMessage. It must be called before connecting to any server.
+func (m *Manager) EnableSampling() {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.sampling = true
+}
 
-	// Check if already connected
-	if _, exists := m.upstreams[serverID]; exists {
+// OnCreateMessage registers fn to answer sampling/createMessage requests from
+// upstreams, tried in registration order like OnElicit handlers.
+func (m *Manager) OnCreateMessage(fn func(ctx context.Context, serverID string, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error)) {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.onCreateMessage = append(m.onCreateMessage, fn)
+}
+
+// OnListChanged registers fn to be called when an upstream reports that its
+// tools, resources, or prompts changed. Callbacks run synchronously on the
+// notification path and must not block.
+func (m *Manager) OnListChanged(fn func(serverID string, kind ListKind)) {
+	m.callbacksMu.Lock()
+	defer m.callbacksMu.Unlock()
+	m.onListChange = append(m.onListChange, fn)
+}
+
+// listChanged notifies the OnListChanged callbacks.
+func (m *Manager) listChanged(serverID string, kind ListKind) {
+	m.callbacksMu.Lock()
+	onListChange := append([]func(string, ListKind){}, m.onListChange...)
+	m.callbacksMu.Unlock()
+
+	for _, fn := range onListChange {
+		fn(serverID, kind)
+	}
+}
+
+// ClientOptions returns the options used for the MCP client connecting to
+// serverID, wiring upstream notifications to the manager's callbacks. It is
+// exported for callers that connect upstreams themselves and register them
+// with Add.
+func (m *Manager) ClientOptions(serverID string) *mcp.ClientOptions {
+	opts := &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			m.callbacksMu.Lock()
+			onProgress := append([]func(string, *mcp.ProgressNotificationParams){}, m.onProgress...)
+			m.callbacksMu.Unlock()
+
+			for _, fn := range onProgress {
+				fn(serverID, req.Params)
+			}
+		},
+		LoggingMessageHandler: func(ctx context.Context, req *mcp.LoggingMessageRequest) {
+			m.callbacksMu.Lock()
+			onLog := append([]func(string, *mcp.LoggingMessageParams){}, m.onLog...)
+			m.callbacksMu.Unlock()
+
+			for _, fn := range onLog {
+				fn(serverID, req.Params)
+			}
+		},
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			m.listChanged(serverID, ListTools)
+		},
+		ResourceListChangedHandler: func(ctx context.Context, req *mcp.ResourceListChangedRequest) {
+			m.listChanged(serverID, ListResources)
+		},
+		PromptListChangedHandler: func(ctx context.Context, req *mcp.PromptListChangedRequest) {
+			m.listChanged(serverID, ListPrompts)
+		},
+	}
+
+	// The capabilities advertised to upstreams follow from which handlers
+	// are set: only what the hub can bridge to its clients is offered
+	m.callbacksMu.Lock()
+	elicitation, sampling := m.elicitation, m.sampling
+	m.callbacksMu.Unlock()
+	if elicitation {
+		opts.ElicitationHandler = func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			m.callbacksMu.Lock()
+			handlers := append([]func(context.Context, string, *mcp.ElicitParams) (*mcp.ElicitResult, error){}, m.onElicit...)
+			m.callbacksMu.Unlock()
+			return firstClaimed(ctx, "elicitation", serverID, req.Params, handlers)
+		}
+	}
+	if sampling {
+		opts.CreateMessageHandler = func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			m.callbacksMu.Lock()
+			handlers := append([]func(context.Context, string, *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error){}, m.onCreateMessage...)
+			m.callbacksMu.Unlock()
+			return firstClaimed(ctx, "sampling", serverID, req.Params, handlers)
+		}
+	}
+	return opts
+}
+
+// firstClaimed calls handlers in order until one returns something other
+// than ErrUnclaimed, and returns its answer.
+func firstClaimed[P, R any](ctx context.Context, feature, serverID string, params P, handlers []func(context.Context, string, P) (R, error)) (R, error) {
+	var zero R
+	if len(handlers) == 0 {
+		return zero, fmt.Errorf("%s is not available", feature)
+	}
+	var err error
+	for _, fn := range handlers {
+		var result R
+		if result, err = fn(ctx, serverID, params); !errors.Is(err, ErrUnclaimed) {
+			return result, err
+		}
+	}
+	return zero, err
+}
+
+// track starts watching u's session and notifies connect and disconnect
+// callbacks.
+func (m *Manager) track(u *Upstream) {
+	m.callbacksMu.Lock()
+	onConnect := append([]func(string){}, m.onConnect...)
+	m.callbacksMu.Unlock()
+
+	for _, fn := range onConnect {
+		go fn(u.ID)
+	}
+
+	u.watch(func(err error) {
+		if err != nil {
+			m.logger().Warn("upstream server disconnected", "server", u.ID, "error", err)
+		}
+
+		m.callbacksMu.Lock()
+		onDisconnect := append([]func(string, error){}, m.onDisconnect...)
+		m.callbacksMu.Unlock()
+
+		for _, fn := range onDisconnect {
+			go fn(u.ID, err)
+		}
+	})
+}
+
+// Connect establishes a connection to an upstream server. Errors reaching
+// the server wrap ErrDial, and errors initializing the session wrap
+// ErrInitialize. Connects to different servers may run concurrently.
+func (m *Manager) Connect(ctx context.Context, serverID string, serverCfg *config.ServerConfig) error {
+	// Reserve the ID, then dial without holding the lock so other servers
+	// can connect meanwhile
+	m.mu.Lock()
+	if _, exists := m.upstreams[serverID]; exists || m.connecting[serverID] {
+		m.mu.Unlock()
 		return fmt.Errorf("already connected to server %q", serverID)
 	}
+	if m.connecting == nil {
+		m.connecting = make(map[string]bool)
+	}
+	m.connecting[serverID] = true
+	inheritEnv := !m.cleanEnv
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.connecting, serverID)
+		m.mu.Unlock()
+	}()
+
+	u := &Upstream{
+		ID:          serverID,
+		DisplayName: serverCfg.DisplayName,
+		Config:      serverCfg,
+	}
+	u.setConcurrencyLimit(serverCfg.MaxConcurrentRequests, serverCfg.MaxQueuedRequests,
+		time.Duration(serverCfg.MaxQueueWait)*time.Second)
 
 	// Create MCP client
 	client := mcp.NewClient(&mcp.Implementation{
 		Name:    "mcp2-proxy",
-		Version: "0.1.0",
-	}, nil)
+		Version: version.Version,
+	}, m.ClientOptions(serverID))
+	client.AddSendingMiddleware(u.limitRequests)
+	client.AddSendingMiddleware(u.cacheToolLists)
+	client.AddSendingMiddleware(u.cacheResourceLists)
+	client.AddReceivingMiddleware(u.resetCachesOnChange)
+	if v := serverCfg.Transport.ProtocolVersion; v != "" {
+		client.AddSendingMiddleware(pinProtocolVersion(v))
+	}
+	u.client = client
+	u.dial = func(ctx context.Context) (*mcp.ClientSession, error) {
+		// Create transport based on config
+		var transport mcp.Transport
+		var err error
 
-	// Create transport based on config
-	var transport mcp.Transport
-	var err error
+		switch serverCfg.Transport.Kind {
+		case "stdio":
+			transport, err = createStdioTransport(serverCfg, inheritEnv)
+		case "http":
+			transport, err = m.createHTTPTransport(serverCfg)
+		default:
+			return nil, fmt.Errorf("unsupported transport kind: %q", serverCfg.Transport.Kind)
+		}
 
-	switch serverCfg.Transport.Kind {
-	case "stdio":
-		transport, err = createStdioTransport(serverCfg)
-	case "http":
-		transport, err = createHTTPTransport(serverCfg)
-	default:
-		return fmt.Errorf("unsupported transport kind: %q", serverCfg.Transport.Kind)
-	}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create transport: %w", err)
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to create transport for server %q: %w", serverID, err)
+		rec := &dialRecorder{}
+		session, err := client.Connect(ctx, rec.wrap(transport), nil)
+		if err != nil {
+			return nil, rec.classify(err)
+		}
+		return session, nil
 	}
 
 	// Connect to the upstream server
-	session, err := client.Connect(ctx, transport, nil)
+	session, err := u.dial(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to server %q: %w", serverID, err)
 	}
 
 	// Store the upstream
-	m.upstreams[serverID] = &Upstream{
-		ID:          serverID,
-		DisplayName: serverCfg.DisplayName,
-		Session:     session,
-		Config:      serverCfg,
-	}
+	u.session = session
+	m.mu.Lock()
+	m.upstreams[serverID] = u
+	m.mu.Unlock()
+	m.track(u)
+	m.startKeepalive(u)
+
+	return nil
+}
+
+// Add registers an already-connected upstream with the manager.
+// This is useful for tests and for embedding with custom transports.
+func (m *Manager) Add(u *Upstream) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
+	if _, exists := m.upstreams[u.ID]; exists {
+		return fmt.Errorf("already connected to server %q", u.ID)
+	}
+	m.track(u)
+	m.upstreams[u.ID] = u
 	return nil
 }
 
@@ -113,7 +492,7 @@ func (m *Manager) Close() error {
 
 	var errs []error
 	for id, upstream := range m.upstreams {
-		if err := upstream.Session.Close(); err != nil {
+		if err := upstream.Session().Close(); err != nil {
 			errs = append(errs, fmt.Errorf("failed to close upstream %q: %w", id, err))
 		}
 	}
@@ -128,11 +507,32 @@ func (m *Manager) Close() error {
 }
 
 // createStdioTransport creates a stdio transport for an upstream server.
-func createStdioTransport(serverCfg *config.ServerConfig) (mcp.Transport, error) {
-	cmd := exec.Command(serverCfg.Transport.Command, serverCfg.Transport.Args...)
+// inheritEnv applies if the server doesn't set inheritEnv itself.
+func createStdioTransport(serverCfg *config.ServerConfig, inheritEnv bool) (mcp.Transport, error) {
+	var cmd *exec.Cmd
+	if serverCfg.Transport.Shell {
+		cmd = exec.Command(userShell(), "-c", shellCommandLine(serverCfg.Transport.Command, serverCfg.Transport.Args))
+	} else {
+		cmd = exec.Command(serverCfg.Transport.Command, serverCfg.Transport.Args...)
+	}
+	cmd.Dir = serverCfg.Transport.Cwd
 
-	// Set environment variables
-	if len(serverCfg.Transport.Env) > 0 {
+	if serverCfg.Transport.InheritEnv != nil {
+		inheritEnv = *serverCfg.Transport.InheritEnv
+	}
+
+	// Set environment variables, over mcp2's own or, without inheritance,
+	// just PATH so the command can still find what it runs
+	if !inheritEnv {
+		env := []string{}
+		if path, ok := os.LookupEnv("PATH"); ok {
+			env = append(env, "PATH="+path)
+		}
+		for k, v := range serverCfg.Transport.Env {
+			env = append(env, fmt.Sprintf("%s=%s", k, v))
+		}
+		cmd.Env = env
+	} else if len(serverCfg.Transport.Env) > 0 {
 		env := cmd.Environ()
 		for k, v := range serverCfg.Transport.Env {
 			env = append(env, fmt.Sprintf("%s=%s", k, v))
@@ -143,11 +543,59 @@ func createStdioTransport(serverCfg *config.ServerConfig) (mcp.Transport, error)
 	return &mcp.CommandTransport{Command: cmd}, nil
 }
 
+// userShell returns the user's shell, falling back to /bin/sh.
+func userShell() string {
+	if shell := os.Getenv("SHELL"); shell != "" {
+		return shell
+	}
+	return "/bin/sh"
+}
+
+// shellCommandLine builds a shell command line from a command string and
+// arguments. The command is used verbatim so it may contain pipes and
+// expansions; each argument is single-quoted.
+func shellCommandLine(command string, args []string) string {
+	var b strings.Builder
+	b.WriteString(command)
+	for _, arg := range args {
+		b.WriteString(" '")
+		b.WriteString(strings.ReplaceAll(arg, "'", `'\''`))
+		b.WriteString("'")
+	}
+	return b.String()
+}
+
 // createHTTPTransport creates an HTTP transport for an upstream server.
-func createHTTPTransport(serverCfg *config.ServerConfig) (mcp.Transport, error) {
+// Servers share the manager's pooled http.Transport unless they override the
+// pool settings, in which case they get a dedicated one.
+func (m *Manager) createHTTPTransport(serverCfg *config.ServerConfig) (mcp.Transport, error) {
+	m.mu.Lock()
+	if m.httpTransport == nil {
+		m.httpTransport = newPooledTransport(m.httpClientConfig)
+	}
+	shared, hubSettings := m.httpTransport, m.httpClientConfig
+	m.mu.Unlock()
+
+	override := serverCfg.Transport.HTTPClient
+	settings := mergeHTTPClientConfig(hubSettings, override)
+
+	var base http.RoundTripper = shared
+	if override.MaxIdleConns > 0 || override.MaxIdleConnsPerHost > 0 || override.MaxConnsPerHost > 0 || override.IdleConnTimeout > 0 {
+		base = newPooledTransport(settings)
+	}
+	base = newUpstreamRoundTripper(base, settings)
+	if serverCfg.Transport.OAuth != nil {
+		base = newOAuthTransport(base, serverCfg.Transport.OAuth)
+	}
+
 	// Use StreamableClientTransport for HTTP
 	return &mcp.StreamableClientTransport{
 		Endpoint: serverCfg.Transport.URL,
-		// TODO: Add support for custom headers via middleware or transport options
+		HTTPClient: &http.Client{
+			Transport: &headerTransport{
+				base:    base,
+				headers: serverCfg.Transport.Headers,
+			},
+		},
 	}, nil
 }
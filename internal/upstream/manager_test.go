@@ -0,0 +1,265 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCreateStdioTransport_Cwd(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.ServerConfig{
+		Transport: config.ServerTransportConfig{
+			Kind:    "stdio",
+			Command: "my-server",
+			Args:    []string{"--flag"},
+			Cwd:     dir,
+		},
+	}
+
+	transport, err := createStdioTransport(cfg, true)
+	if err != nil {
+		t.Fatalf("createStdioTransport failed: %v", err)
+	}
+
+	cmd := transport.(*mcp.CommandTransport).Command
+	if cmd.Dir != dir {
+		t.Errorf("Dir = %q, want %q", cmd.Dir, dir)
+	}
+	if len(cmd.Args) != 2 || cmd.Args[0] != "my-server" || cmd.Args[1] != "--flag" {
+		t.Errorf("Args = %v, want [my-server --flag]", cmd.Args)
+	}
+}
+
+func TestCreateStdioTransport_Shell(t *testing.T) {
+	t.Setenv("SHELL", "/bin/sh")
+
+	cfg := &config.ServerConfig{
+		Transport: config.ServerTransportConfig{
+			Kind:    "stdio",
+			Command: "echo $((1 + 1)) |",
+			Args:    []string{"it's"},
+			Shell:   true,
+		},
+	}
+
+	transport, err := createStdioTransport(cfg, true)
+	if err != nil {
+		t.Fatalf("createStdioTransport failed: %v", err)
+	}
+
+	cmd := transport.(*mcp.CommandTransport).Command
+	want := []string{"/bin/sh", "-c", `echo $((1 + 1)) | 'it'\''s'`}
+	if len(cmd.Args) != len(want) {
+		t.Fatalf("Args = %v, want %v", cmd.Args, want)
+	}
+	for i := range want {
+		if cmd.Args[i] != want[i] {
+			t.Errorf("Args[%d] = %q, want %q", i, cmd.Args[i], want[i])
+		}
+	}
+}
+
+func TestShellCommandLine_QuotesArgs(t *testing.T) {
+	line := shellCommandLine("printf '%s|'", []string{"a b", "it's", "$HOME"})
+
+	out, err := exec.Command("/bin/sh", "-c", line).Output()
+	if err != nil {
+		t.Fatalf("Shell command failed: %v", err)
+	}
+	if string(out) != "a b|it's|$HOME|" {
+		t.Errorf("Output = %q, want %q", out, "a b|it's|$HOME|")
+	}
+}
+
+func TestOnElicit_SkipsUnclaimedHandlers(t *testing.T) {
+	m := NewManager()
+	m.EnableElicitation()
+
+	var calls []string
+	m.OnElicit(func(ctx context.Context, serverID string, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		calls = append(calls, "first")
+		return nil, fmt.Errorf("refused: %w", ErrUnclaimed)
+	})
+	m.OnElicit(func(ctx context.Context, serverID string, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		calls = append(calls, "second")
+		return &mcp.ElicitResult{Action: "accept"}, nil
+	})
+
+	result, err := m.ClientOptions("server1").ElicitationHandler(context.Background(), &mcp.ElicitRequest{Params: &mcp.ElicitParams{}})
+	if err != nil {
+		t.Fatalf("ElicitationHandler failed: %v", err)
+	}
+	if result.Action != "accept" {
+		t.Errorf("Action = %q, want accept", result.Action)
+	}
+	if len(calls) != 2 {
+		t.Errorf("calls = %v, want [first second]", calls)
+	}
+}
+
+func TestCreateStdioTransport_InheritEnv(t *testing.T) {
+	t.Setenv("MCP2_TEST_SECRET", "leaked")
+	t.Setenv("PATH", "/usr/bin")
+
+	inherit, clean := true, false
+	tests := []struct {
+		name           string
+		inheritEnv     *bool
+		defaultInherit bool
+		wantSecret     bool
+	}{
+		{"default inherits", nil, true, true},
+		{"default clean", nil, false, false},
+		{"server overrides default", &clean, true, false},
+		{"server opts back in", &inherit, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.ServerConfig{
+				Transport: config.ServerTransportConfig{
+					Kind:       "stdio",
+					Command:    "my-server",
+					Env:        map[string]string{"API_URL": "http://localhost"},
+					InheritEnv: tt.inheritEnv,
+				},
+			}
+			transport, err := createStdioTransport(cfg, tt.defaultInherit)
+			if err != nil {
+				t.Fatalf("createStdioTransport failed: %v", err)
+			}
+
+			env := transport.(*mcp.CommandTransport).Command.Environ()
+			if got := slices.Contains(env, "MCP2_TEST_SECRET=leaked"); got != tt.wantSecret {
+				t.Errorf("inherited MCP2_TEST_SECRET = %v, want %v (env %v)", got, tt.wantSecret, env)
+			}
+			for _, want := range []string{"PATH=/usr/bin", "API_URL=http://localhost"} {
+				if !slices.Contains(env, want) {
+					t.Errorf("env %v is missing %s", env, want)
+				}
+			}
+			if !tt.wantSecret && len(env) != 2 {
+				t.Errorf("clean env = %v, want only PATH and API_URL", env)
+			}
+		})
+	}
+}
+
+func TestManager_ConnectStdioWithCleanEnv(t *testing.T) {
+	m := NewManager()
+	m.SetInheritEnv(false)
+
+	cfg := &config.ServerConfig{
+		Transport: config.ServerTransportConfig{Kind: "stdio", Command: "/nonexistent/mcp2-test-server"},
+	}
+	done := make(chan error, 1)
+	go func() { done <- m.Connect(context.Background(), "missing", cfg) }()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrDial) {
+			t.Errorf("Connect error = %v, want ErrDial for a missing command", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Connect did not return")
+	}
+}
+
+func TestManager_ConnectErrorPhases(t *testing.T) {
+	// A listener that is closed again leaves a port nothing answers on
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable := "http://" + l.Addr().String() + "/mcp"
+	l.Close()
+
+	notMCP := httptest.NewServer(http.NotFoundHandler())
+	defer notMCP.Close()
+
+	tests := []struct {
+		name string
+		url  string
+		want error
+	}{
+		{"unreachable", unreachable, ErrDial},
+		{"not an MCP server", notMCP.URL, ErrInitialize},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewManager()
+			defer m.Close()
+
+			cfg := &config.ServerConfig{Transport: config.ServerTransportConfig{Kind: "http", URL: tt.url}}
+			err := m.Connect(context.Background(), "server1", cfg)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Connect error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestManager_ConnectPinnedProtocolVersion(t *testing.T) {
+	newServer := func(answer string) *httptest.Server {
+		server := mcp.NewServer(&mcp.Implementation{Name: "pinned", Version: "1.0.0"}, nil)
+		if answer != "" {
+			// Answer with a fixed version, as a server that doesn't support
+			// the requested one would
+			server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+				return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+					res, err := next(ctx, method, req)
+					if initRes, ok := res.(*mcp.InitializeResult); ok {
+						initRes.ProtocolVersion = answer
+					}
+					return res, err
+				}
+			})
+		}
+		handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+		return httptest.NewServer(handler)
+	}
+
+	t.Run("accepted", func(t *testing.T) {
+		srv := newServer("")
+		defer srv.Close()
+
+		m := NewManager()
+		defer m.Close()
+		cfg := &config.ServerConfig{Transport: config.ServerTransportConfig{Kind: "http", URL: srv.URL, ProtocolVersion: "2025-03-26"}}
+		if err := m.Connect(context.Background(), "server1", cfg); err != nil {
+			t.Fatalf("Connect: %v", err)
+		}
+		u, _ := m.Get("server1")
+		if got := u.Session().InitializeResult().ProtocolVersion; got != "2025-03-26" {
+			t.Errorf("negotiated protocol version = %q, want 2025-03-26", got)
+		}
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		srv := newServer("2025-06-18")
+		defer srv.Close()
+
+		m := NewManager()
+		defer m.Close()
+		cfg := &config.ServerConfig{Transport: config.ServerTransportConfig{Kind: "http", URL: srv.URL, ProtocolVersion: "2024-11-05"}}
+		err := m.Connect(context.Background(), "server1", cfg)
+		if !errors.Is(err, ErrInitialize) {
+			t.Fatalf("Connect error = %v, want %v", err, ErrInitialize)
+		}
+		if want := `rejected pinned protocol version "2024-11-05"`; !strings.Contains(err.Error(), want) {
+			t.Errorf("Connect error = %v, want it to contain %s", err, want)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package upstream
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/ain3sh/mcp2/internal/config"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// oauthTransport injects a bearer token obtained via the OAuth 2.0
+// client-credentials flow. Tokens are cached and refreshed shortly before
+// they expire; a 401 response forces a refresh, unless a concurrent request
+// already refreshed the rejected token, and a single retry.
+type oauthTransport struct {
+	base   http.RoundTripper
+	config *clientcredentials.Config
+
+	mu    sync.Mutex
+	token *oauth2.Token
+}
+
+func newOAuthTransport(base http.RoundTripper, oc *config.OAuthConfig) *oauthTransport {
+	return &oauthTransport{
+		base: base,
+		config: &clientcredentials.Config{
+			ClientID:     oc.ClientID,
+			ClientSecret: oc.ClientSecret,
+			TokenURL:     oc.TokenURL,
+			Scopes:       oc.Scopes,
+		},
+	}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *oauthTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.getToken(req.Context(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Rewind before the first attempt so the body can be resent on 401
+	retry, rewindErr := rewindRequest(req)
+
+	resp, err := t.base.RoundTrip(withToken(req, token))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || rewindErr != nil {
+		return resp, err
+	}
+
+	token, err = t.getToken(req.Context(), token)
+	if err != nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return t.base.RoundTrip(withToken(retry, token))
+}
+
+// getToken returns the cached token, fetching a new one if it is missing,
+// about to expire, or is rejected, the token a request was refused with.
+// Requests refused with the same token share the one refetch.
+func (t *oauthTransport) getToken(ctx context.Context, rejected *oauth2.Token) (*oauth2.Token, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != rejected && t.token.Valid() {
+		return t.token, nil
+	}
+
+	// Fetch the token with a plain client so it doesn't recurse through us
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: t.base})
+	token, err := t.config.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.token = token
+	return token, nil
+}
+
+// withToken returns a copy of req carrying the bearer token.
+func withToken(req *http.Request, token *oauth2.Token) *http.Request {
+	req = req.Clone(req.Context())
+	token.SetAuthHeader(req)
+	return req
+}
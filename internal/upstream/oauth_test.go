@@ -0,0 +1,160 @@
+package upstream
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ain3sh/mcp2/internal/config"
+)
+
+// newTokenServer returns a token endpoint that issues tok-1, tok-2, ...
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var issued atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" {
+			t.Errorf("grant_type = %q", r.Form.Get("grant_type"))
+		}
+		n := issued.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"tok-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &issued
+}
+
+func TestOAuthTransport_CachesToken(t *testing.T) {
+	tokenSrv, issued := newTokenServer(t, 3600)
+
+	var auths []string
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: newOAuthTransport(http.DefaultTransport, &config.OAuthConfig{
+		TokenURL: tokenSrv.URL, ClientID: "id", ClientSecret: "secret",
+	})}
+
+	for i := 0; i < 3; i++ {
+		resp, err := client.Get(api.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if issued.Load() != 1 {
+		t.Errorf("Issued %d tokens, want 1", issued.Load())
+	}
+	for _, auth := range auths {
+		if auth != "Bearer tok-1" {
+			t.Errorf("Authorization = %q, want %q", auth, "Bearer tok-1")
+		}
+	}
+}
+
+func TestOAuthTransport_RefreshesOnUnauthorized(t *testing.T) {
+	tokenSrv, issued := newTokenServer(t, 3600)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Reject the first token as if it had been revoked
+		if r.Header.Get("Authorization") == "Bearer tok-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: newOAuthTransport(http.DefaultTransport, &config.OAuthConfig{
+		TokenURL: tokenSrv.URL, ClientID: "id", ClientSecret: "secret",
+	})}
+
+	resp, err := client.Post(api.URL, "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Status = %d, want 200", resp.StatusCode)
+	}
+	if issued.Load() != 2 {
+		t.Errorf("Issued %d tokens, want 2", issued.Load())
+	}
+}
+
+func TestOAuthTransport_ConcurrentUnauthorizedRefreshOnce(t *testing.T) {
+	tokenSrv, issued := newTokenServer(t, 3600)
+
+	// Hold every request with the first token until all have arrived, so
+	// they are all rejected before any refreshes
+	const n = 5
+	var arrived sync.WaitGroup
+	arrived.Add(n)
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "Bearer tok-1" {
+			arrived.Done()
+			arrived.Wait()
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer api.Close()
+
+	client := &http.Client{Transport: newOAuthTransport(http.DefaultTransport, &config.OAuthConfig{
+		TokenURL: tokenSrv.URL, ClientID: "id", ClientSecret: "secret",
+	})}
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(api.URL)
+			if err != nil {
+				t.Errorf("Request failed: %v", err)
+				return
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Errorf("Status = %d, want 200", resp.StatusCode)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if issued.Load() != 2 {
+		t.Errorf("Issued %d tokens, want 2", issued.Load())
+	}
+}
+
+func TestOAuthTransport_RefreshesBeforeExpiry(t *testing.T) {
+	// Tokens expiring within oauth2's expiry delta are treated as expired
+	tokenSrv, issued := newTokenServer(t, 1)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer api.Close()
+
+	client := &http.Client{Transport: newOAuthTransport(http.DefaultTransport, &config.OAuthConfig{
+		TokenURL: tokenSrv.URL, ClientID: "id",
+	})}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(api.URL)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	if issued.Load() != 2 {
+		t.Errorf("Issued %d tokens, want 2", issued.Load())
+	}
+}
@@ -0,0 +1,36 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// pinProtocolVersion returns sending middleware that requests version in
+// the initialize handshake instead of the client's latest, and fails the
+// handshake unless the server agrees to it. Servers that support a pinned
+// version must answer with it, so any other answer means they rejected it.
+func pinProtocolVersion(version string) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			initReq, ok := req.(*mcp.InitializeRequest)
+			if !ok || initReq.Params == nil {
+				return next(ctx, method, req)
+			}
+
+			params := *initReq.Params
+			params.ProtocolVersion = version
+			initReq.Params = &params
+
+			res, err := next(ctx, method, req)
+			if err != nil {
+				return nil, fmt.Errorf("server rejected pinned protocol version %q: %w", version, err)
+			}
+			if initRes, ok := res.(*mcp.InitializeResult); ok && initRes.ProtocolVersion != version {
+				return nil, fmt.Errorf("server rejected pinned protocol version %q (it offered %q)", version, initRes.ProtocolVersion)
+			}
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// readyPollInterval is how often WaitReady retries an upstream that is not
+// answering yet.
+const readyPollInterval = 250 * time.Millisecond
+
+// WaitReady waits until a connected upstream answers a tools/list request (or
+// a ping, if it does not advertise tools), retrying until timeout elapses.
+// Some servers complete initialize before they can serve requests.
+func (m *Manager) WaitReady(ctx context.Context, serverID string, timeout time.Duration) error {
+	u, err := m.Get(serverID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for {
+		if u.SupportsTools() {
+			_, err = u.Session().ListTools(ctx, nil)
+		} else {
+			err = u.Session().Ping(ctx, nil)
+		}
+		if err == nil {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("server %q not ready within %v: %w", serverID, timeout, err)
+		case <-time.After(readyPollInterval):
+		}
+	}
+}
+
+// Remove closes an upstream's connection and stops managing it.
+func (m *Manager) Remove(serverID string) error {
+	m.mu.Lock()
+	u, ok := m.upstreams[serverID]
+	delete(m.upstreams, serverID)
+	m.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("upstream server %q not found", serverID)
+	}
+	return u.Session().Close()
+}
@@ -0,0 +1,77 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newWarmingUpstream connects to a server whose tools/list fails until it
+// has been asked failures times.
+func newWarmingUpstream(t *testing.T, failures int32) *Upstream {
+	t.Helper()
+
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "slow", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	var calls atomic.Int32
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "tools/list" && calls.Add(1) <= failures {
+				return nil, errors.New("still starting")
+			}
+			return next(ctx, method, req)
+		}
+	})
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	return NewUpstream("slow", session)
+}
+
+func TestWaitReady_RetriesUntilReady(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+	if err := m.Add(newWarmingUpstream(t, 2)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if err := m.WaitReady(context.Background(), "slow", 5*time.Second); err != nil {
+		t.Errorf("WaitReady failed: %v", err)
+	}
+}
+
+func TestWaitReady_TimesOut(t *testing.T) {
+	m := NewManager()
+	defer m.Close()
+	if err := m.Add(newWarmingUpstream(t, 1000)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	if err := m.WaitReady(context.Background(), "slow", 300*time.Millisecond); err == nil {
+		t.Fatal("Expected WaitReady to time out")
+	}
+
+	if err := m.Remove("slow"); err != nil {
+		t.Errorf("Remove failed: %v", err)
+	}
+	if _, err := m.Get("slow"); err == nil {
+		t.Error("Expected removed upstream to be gone")
+	}
+}
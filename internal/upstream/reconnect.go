@@ -0,0 +1,56 @@
+package upstream
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Reconnect replaces an upstream's session with a new one on a fresh
+// transport, closing the old session. The upstream keeps its client, so
+// handlers registered on it stay in place and the server sees the same
+// client identity. Connect callbacks run again on success; on failure the
+// old session is left as it was.
+func (m *Manager) Reconnect(ctx context.Context, serverID string) error {
+	u, err := m.Get(serverID)
+	if err != nil {
+		return err
+	}
+	if u.dial == nil {
+		return fmt.Errorf("server %q was not connected by the manager and cannot be reconnected", serverID)
+	}
+
+	session, err := u.dial(ctx)
+	if err != nil {
+		u.mu.Lock()
+		u.lastErr = err
+		u.mu.Unlock()
+		return fmt.Errorf("failed to reconnect to server %q: %w", serverID, err)
+	}
+
+	u.mu.Lock()
+	old := u.session
+	u.session = session
+	u.restarts++
+	u.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	u.toolCache().reset()
+	u.resourceCache().reset()
+	m.track(u)
+	m.startKeepalive(u)
+
+	m.logger().Info("reconnected to upstream server", "server", serverID)
+	return nil
+}
+
+// startKeepalive starts keepalive pings for u's current session if its
+// config asks for them.
+func (m *Manager) startKeepalive(u *Upstream) {
+	if u.Config == nil || u.Config.Transport.Kind != "http" || u.Config.KeepaliveInterval <= 0 {
+		return
+	}
+	go u.keepalive(time.Duration(u.Config.KeepaliveInterval)*time.Second, m.logger())
+}
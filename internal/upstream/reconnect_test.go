@@ -0,0 +1,90 @@
+package upstream
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestManager_ReconnectKeepsClient(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "remote", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	var serverSessions []*mcp.ServerSession
+	u := &Upstream{ID: "remote"}
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp2-proxy", Version: "1.0.0"}, manager.ClientOptions("remote"))
+	client.AddSendingMiddleware(u.limitRequests)
+	u.client = client
+	u.dial = func(ctx context.Context) (*mcp.ClientSession, error) {
+		clientTransport, serverTransport := mcp.NewInMemoryTransports()
+		ss, err := server.Connect(ctx, serverTransport, nil)
+		if err != nil {
+			return nil, err
+		}
+		serverSessions = append(serverSessions, ss)
+		return client.Connect(ctx, clientTransport, nil)
+	}
+	t.Cleanup(func() {
+		for _, ss := range serverSessions {
+			ss.Close()
+		}
+	})
+
+	var err error
+	if u.session, err = u.dial(ctx); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	if err := manager.Add(u); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	t.Cleanup(func() { manager.Close() })
+
+	// Readers fetch the session while it is replaced; run with -race
+	first := u.Session()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for u.Session() == first {
+			u.Alias("replica").Session()
+		}
+	}()
+	if err := manager.Reconnect(ctx, "remote"); err != nil {
+		t.Fatalf("Reconnect failed: %v", err)
+	}
+	<-done
+
+	if u.Session() == first {
+		t.Fatal("Expected Reconnect to replace the session")
+	}
+	if u.client != client {
+		t.Error("Expected Reconnect to keep the upstream's client")
+	}
+	if _, err := first.ListTools(ctx, nil); err == nil {
+		t.Error("Expected the old session to be closed")
+	}
+	if _, err := u.Session().CallTool(ctx, &mcp.CallToolParams{Name: "echo"}); err != nil {
+		t.Errorf("CallTool on the new session failed: %v", err)
+	}
+
+	for i, ss := range serverSessions {
+		if info := ss.InitializeParams().ClientInfo; info.Name != "mcp2-proxy" {
+			t.Errorf("Session %d client info = %+v, want mcp2-proxy", i, info)
+		}
+	}
+	if s := u.Status(); !s.Connected || s.Restarts != 1 {
+		t.Errorf("Status = connected %v, restarts %d; want connected with 1 restart", s.Connected, s.Restarts)
+	}
+}
+
+func TestManager_ReconnectRequiresManagedUpstream(t *testing.T) {
+	manager := NewManager()
+	if err := manager.Reconnect(context.Background(), "missing"); err == nil {
+		t.Error("Expected an error reconnecting an unknown server")
+	}
+}
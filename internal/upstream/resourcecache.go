@@ -0,0 +1,85 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrResourceNotFound is returned by Resource for a URI the upstream doesn't
+// list, such as one only reachable through a resource template.
+var ErrResourceNotFound = errors.New("resource not found")
+
+// resourceCache holds an upstream's resource definitions from its most
+// recent resources/list, so reads can be checked against what a resource
+// declares without listing the upstream's resources on every read.
+type resourceCache = listCache[mcp.Resource]
+
+// resourceCache returns u's resource cache, creating it on first use.
+func (u *Upstream) resourceCache() *resourceCache {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.resources == nil {
+		u.resources = &resourceCache{}
+	}
+	return u.resources
+}
+
+// cacheResourceLists is sending middleware that caches the resource
+// definitions in every resources/list result, whoever listed them.
+func (u *Upstream) cacheResourceLists(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if method != "resources/list" || err != nil {
+			return result, err
+		}
+		if list, ok := result.(*mcp.ListResourcesResult); ok {
+			params, _ := req.GetParams().(*mcp.ListResourcesParams)
+			first := params == nil || params.Cursor == ""
+			u.resourceCache().store(list.Resources, first, list.NextCursor == "")
+		}
+		return result, err
+	}
+}
+
+// Resource returns the upstream's definition of the resource at uri, from
+// the cache when possible and otherwise by listing its resources. It
+// returns an error wrapping ErrResourceNotFound if the upstream doesn't
+// list the resource.
+func (u *Upstream) Resource(ctx context.Context, uri string) (*mcp.Resource, error) {
+	cache := u.resourceCache()
+	if resource, known := cache.lookup(uri); known {
+		if resource == nil {
+			return nil, fmt.Errorf("%w: %q on server %q", ErrResourceNotFound, uri, u.ID)
+		}
+		return resource, nil
+	}
+
+	// As in Tool, pages are stored and also searched directly
+	var found *mcp.Resource
+	params := &mcp.ListResourcesParams{}
+	for first := true; ; first = false {
+		result, err := u.Session().ListResources(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		cache.store(result.Resources, first, result.NextCursor == "")
+		for _, resource := range result.Resources {
+			if resource.URI == uri && found == nil {
+				r := *resource
+				found = &r
+			}
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		params = &mcp.ListResourcesParams{Cursor: result.NextCursor}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: %q on server %q", ErrResourceNotFound, uri, u.ID)
+	}
+	return found, nil
+}
@@ -0,0 +1,81 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestUpstream_ResourceCachesDefinitions(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "remote", Version: "1.0.0"}, nil)
+	addResource := func(uri string, size int64) {
+		server.AddResource(&mcp.Resource{Name: uri, URI: uri, Size: size},
+			func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+				return &mcp.ReadResourceResult{}, nil
+			})
+	}
+	addResource("file:///a", 10)
+
+	var lists atomic.Int32
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "resources/list" {
+				lists.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+
+	u := &Upstream{ID: "remote"}
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp2-proxy", Version: "1.0.0"}, nil)
+	client.AddSendingMiddleware(u.cacheResourceLists)
+	client.AddReceivingMiddleware(u.resetCachesOnChange)
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	if u.session, err = client.Connect(ctx, clientTransport, nil); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { u.Session().Close() })
+
+	for range 3 {
+		resource, err := u.Resource(ctx, "file:///a")
+		if err != nil {
+			t.Fatalf("Resource failed: %v", err)
+		}
+		if resource.Size != 10 {
+			t.Errorf("Resource = %+v, want size 10", resource)
+		}
+	}
+	if _, err := u.Resource(ctx, "file:///missing"); !errors.Is(err, ErrResourceNotFound) {
+		t.Errorf("Resource(missing) error = %v, want ErrResourceNotFound", err)
+	}
+	if got := lists.Load(); got != 1 {
+		t.Errorf("resources/list calls = %d, want 1", got)
+	}
+	if got, want := u.Status().Caches.Resources, (CacheStats{Hits: 3, Misses: 1, Size: 1}); got != want {
+		t.Errorf("Status().Caches.Resources = %+v, want %+v", got, want)
+	}
+
+	// list_changed drops the cache, so the new resource is found
+	addResource("file:///b", 20)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := u.Resource(ctx, "file:///b"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Resource(file:///b) not found after list_changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,172 @@
+package upstream
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Status describes the connection state of an upstream server.
+type Status struct {
+	ID              string                  `json:"id"`
+	DisplayName     string                  `json:"displayName,omitempty"`
+	Connected       bool                    `json:"connected"`
+	ServerName      string                  `json:"serverName,omitempty"`
+	ServerVersion   string                  `json:"serverVersion,omitempty"`
+	ProtocolVersion string                  `json:"protocolVersion,omitempty"`
+	Capabilities    *mcp.ServerCapabilities `json:"capabilities,omitempty"`
+	LastError       string                  `json:"lastError,omitempty"`
+	ConnectedAt     time.Time               `json:"connectedAt"`
+	Restarts        int                     `json:"restarts"`
+	// InFlight is the number of requests currently awaiting a response,
+	// including those queued behind maxConcurrentRequests.
+	InFlight int `json:"inFlight"`
+	// Latency summarizes the most recent requests' latencies, if any have
+	// completed.
+	Latency *LatencySummary `json:"latency,omitempty"`
+	// Queued is how many requests are waiting for a slot under
+	// maxConcurrentRequests, and Rejected how many were refused as
+	// overloaded since the upstream was created.
+	Queued   int    `json:"queued"`
+	Rejected uint64 `json:"rejected"`
+	// QueueWait summarizes how long the most recent requests waited for a
+	// slot, when maxConcurrentRequests is set.
+	QueueWait *LatencySummary `json:"queueWait,omitempty"`
+	// Caches reports the tool and resource definition caches.
+	Caches CacheStatus `json:"caches"`
+}
+
+// CacheStatus reports an upstream's definition caches.
+type CacheStatus struct {
+	Tools     CacheStats `json:"tools"`
+	Resources CacheStats `json:"resources"`
+}
+
+// Status returns the upstream's current connection state. Server info and
+// capabilities come from the initialize result negotiated during Connect.
+// Restarts counts successful Reconnect calls.
+func (u *Upstream) Status() Status {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	s := Status{
+		ID:          u.ID,
+		DisplayName: u.DisplayName,
+		Connected:   u.session != nil && !u.disconnected,
+		ConnectedAt: u.connectedAt,
+		Restarts:    u.restarts,
+		InFlight:    u.inFlight,
+		Latency:     u.latency.summary(),
+		Queued:      u.queued,
+		Rejected:    u.rejected,
+	}
+	if u.sem != nil {
+		s.QueueWait = u.queueWait.summary()
+	}
+	if u.lastErr != nil {
+		s.LastError = u.lastErr.Error()
+	}
+	if u.tools != nil {
+		s.Caches.Tools = u.tools.stats()
+	}
+	if u.resources != nil {
+		s.Caches.Resources = u.resources.stats()
+	}
+	if u.session != nil {
+		if init := u.session.InitializeResult(); init != nil {
+			s.ProtocolVersion = init.ProtocolVersion
+			s.Capabilities = init.Capabilities
+			if init.ServerInfo != nil {
+				s.ServerName = init.ServerInfo.Name
+				s.ServerVersion = init.ServerInfo.Version
+			}
+		}
+	}
+	return s
+}
+
+// Connected reports whether the upstream's session is up.
+func (u *Upstream) Connected() bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.session != nil && !u.disconnected
+}
+
+// Capabilities returns the capabilities the upstream advertised during
+// initialize, or nil if they are unknown.
+func (u *Upstream) Capabilities() *mcp.ServerCapabilities {
+	session := u.Session()
+	if session == nil {
+		return nil
+	}
+	if init := session.InitializeResult(); init != nil {
+		return init.Capabilities
+	}
+	return nil
+}
+
+// SupportsTools, SupportsResources, and SupportsPrompts report whether the
+// upstream advertised the capability. They return true when capabilities are
+// unknown so callers fall back to attempting the request.
+func (u *Upstream) SupportsTools() bool {
+	caps := u.Capabilities()
+	return caps == nil || caps.Tools != nil
+}
+
+func (u *Upstream) SupportsResources() bool {
+	caps := u.Capabilities()
+	return caps == nil || caps.Resources != nil
+}
+
+func (u *Upstream) SupportsPrompts() bool {
+	caps := u.Capabilities()
+	return caps == nil || caps.Prompts != nil
+}
+
+// watch marks the upstream connected and records when its session ends,
+// along with the error that ended it, then calls onDone with that error.
+func (u *Upstream) watch(onDone func(error)) {
+	u.mu.Lock()
+	u.connectedAt = time.Now()
+	u.disconnected = false
+	session := u.session
+	u.mu.Unlock()
+
+	if session == nil {
+		return
+	}
+	go func() {
+		err := session.Wait()
+		if errors.Is(err, mcp.ErrConnectionClosed) {
+			err = nil
+		}
+
+		u.mu.Lock()
+		if u.session != session {
+			u.mu.Unlock()
+			return
+		}
+		u.disconnected = true
+		if err != nil {
+			u.lastErr = err
+		}
+		u.mu.Unlock()
+
+		onDone(err)
+	}()
+}
+
+// Statuses returns the status of every upstream, sorted by server ID.
+func (m *Manager) Statuses() []Status {
+	upstreams := m.List()
+	statuses := make([]Status, 0, len(upstreams))
+	for _, u := range upstreams {
+		statuses = append(statuses, u.Status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ID < statuses[j].ID
+	})
+	return statuses
+}
@@ -0,0 +1,136 @@
+package upstream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestStatus_ReportsInitializeResult(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.2.3"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	m := NewManager()
+	u := NewUpstream("server1", session)
+	u.DisplayName = "Server 1"
+	if err := m.Add(u); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	statuses := m.Statuses()
+	if len(statuses) != 1 {
+		t.Fatalf("Expected 1 status, got %d", len(statuses))
+	}
+	s := statuses[0]
+	if !s.Connected {
+		t.Error("Expected upstream to be connected")
+	}
+	if s.ServerName != "test-server" || s.ServerVersion != "1.2.3" {
+		t.Errorf("Server info = %s/%s, want test-server/1.2.3", s.ServerName, s.ServerVersion)
+	}
+	if s.Capabilities == nil || s.Capabilities.Tools == nil {
+		t.Error("Expected tools capability")
+	}
+	if u, _ := m.Get("server1"); u.Capabilities() == nil || u.Capabilities().Resources != nil {
+		t.Errorf("Capabilities = %+v, want tools only", u.Capabilities())
+	}
+	if s.ConnectedAt.IsZero() {
+		t.Error("Expected ConnectedAt to be set")
+	}
+
+	session.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for m.Statuses()[0].Connected {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected upstream to be reported disconnected after close")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestStatuses_SortedByID(t *testing.T) {
+	m := NewManager()
+	for _, id := range []string{"b", "a", "c"} {
+		if err := m.Add(&Upstream{ID: id}); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+
+	statuses := m.Statuses()
+	for i, want := range []string{"a", "b", "c"} {
+		if statuses[i].ID != want {
+			t.Errorf("statuses[%d].ID = %q, want %q", i, statuses[i].ID, want)
+		}
+		if statuses[i].Connected {
+			t.Errorf("Upstream %q without a session should not be connected", want)
+		}
+	}
+}
+
+func TestManager_ConnectDisconnectCallbacks(t *testing.T) {
+	ctx := context.Background()
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test-server", Version: "1.0.0"}, nil)
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+
+	connected := make(chan string, 1)
+	disconnected := make(chan string, 1)
+
+	m := NewManager()
+	m.OnConnect(func(serverID string) { connected <- serverID })
+	m.OnDisconnect(func(serverID string, err error) { disconnected <- serverID })
+
+	if err := m.Add(NewUpstream("server1", session)); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	select {
+	case id := <-connected:
+		if id != "server1" {
+			t.Errorf("OnConnect got %q, want server1", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnConnect was not called")
+	}
+
+	m.Close()
+
+	select {
+	case id := <-disconnected:
+		if id != "server1" {
+			t.Errorf("OnDisconnect got %q, want server1", id)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("OnDisconnect was not called")
+	}
+}
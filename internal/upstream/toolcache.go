@@ -0,0 +1,123 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrToolNotFound is returned by Tool and GetTool for a name the upstream
+// doesn't list.
+var ErrToolNotFound = errors.New("tool not found")
+
+// toolCache holds an upstream's tool definitions from its most recent
+// tools/list, so call-time checks that need a tool's annotations or schema
+// don't list the upstream's tools on every call.
+type toolCache = listCache[mcp.Tool]
+
+// toolCache returns u's tool cache, creating it on first use.
+func (u *Upstream) toolCache() *toolCache {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.tools == nil {
+		u.tools = &toolCache{}
+	}
+	return u.tools
+}
+
+// cacheToolLists is sending middleware that caches the tool definitions in
+// every tools/list result, whoever listed them.
+func (u *Upstream) cacheToolLists(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		result, err := next(ctx, method, req)
+		if method != "tools/list" || err != nil {
+			return result, err
+		}
+		if list, ok := result.(*mcp.ListToolsResult); ok {
+			params, _ := req.GetParams().(*mcp.ListToolsParams)
+			first := params == nil || params.Cursor == ""
+			u.toolCache().store(list.Tools, first, list.NextCursor == "")
+		}
+		return result, err
+	}
+}
+
+// resetCachesOnChange is receiving middleware that drops the cached tool or
+// resource definitions when the upstream says that list changed.
+func (u *Upstream) resetCachesOnChange(next mcp.MethodHandler) mcp.MethodHandler {
+	return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+		switch method {
+		case "notifications/tools/list_changed":
+			u.toolCache().reset()
+		case "notifications/resources/list_changed":
+			u.resourceCache().reset()
+		}
+		return next(ctx, method, req)
+	}
+}
+
+// Tool returns the upstream's definition of the tool name, from the cache
+// when possible and otherwise by listing its tools. It returns an error
+// wrapping ErrToolNotFound if the upstream doesn't list the tool.
+func (u *Upstream) Tool(ctx context.Context, name string) (*mcp.Tool, error) {
+	cache := u.toolCache()
+	if tool, known := cache.lookup(name); known {
+		if tool == nil {
+			return nil, fmt.Errorf("%w: %q on server %q", ErrToolNotFound, name, u.ID)
+		}
+		return tool, nil
+	}
+
+	// Store pages here too: upstreams not connected by the manager don't
+	// have the caching middleware. The pages are searched directly in case
+	// the tool is past maxCachedItems.
+	var found *mcp.Tool
+	params := &mcp.ListToolsParams{}
+	for first := true; ; first = false {
+		result, err := u.Session().ListTools(ctx, params)
+		if err != nil {
+			return nil, err
+		}
+		cache.store(result.Tools, first, result.NextCursor == "")
+		for _, tool := range result.Tools {
+			if tool.Name == name && found == nil {
+				t := *tool
+				found = &t
+			}
+		}
+		if result.NextCursor == "" {
+			break
+		}
+		params = &mcp.ListToolsParams{Cursor: result.NextCursor}
+	}
+
+	if found == nil {
+		return nil, fmt.Errorf("%w: %q on server %q", ErrToolNotFound, name, u.ID)
+	}
+	return found, nil
+}
+
+// GetTool returns serverID's definition of the tool name; see
+// Upstream.Tool.
+func (m *Manager) GetTool(ctx context.Context, serverID, name string) (*mcp.Tool, error) {
+	u, err := m.Get(serverID)
+	if err != nil {
+		return nil, err
+	}
+	return u.Tool(ctx, name)
+}
+
+// Alias returns an upstream for u's current session known by id instead,
+// sharing u's tool and resource caches, as for a replica group backed by u.
+func (u *Upstream) Alias(id string) *Upstream {
+	return &Upstream{
+		ID:          id,
+		DisplayName: u.DisplayName,
+		session:     u.Session(),
+		Config:      u.Config,
+		tools:       u.toolCache(),
+		resources:   u.resourceCache(),
+	}
+}
@@ -0,0 +1,113 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestUpstream_ToolCachesDefinitions(t *testing.T) {
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "remote", Version: "1.0.0"}, nil)
+	addTool := func(name string) {
+		mcp.AddTool(server, &mcp.Tool{Name: name, Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}},
+			func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{}, nil, nil
+			})
+	}
+	addTool("read")
+
+	var lists atomic.Int32
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "tools/list" {
+				lists.Add(1)
+			}
+			return next(ctx, method, req)
+		}
+	})
+
+	u := &Upstream{ID: "remote"}
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp2-proxy", Version: "1.0.0"}, nil)
+	client.AddSendingMiddleware(u.cacheToolLists)
+	client.AddReceivingMiddleware(u.resetCachesOnChange)
+	clientTransport, serverTransport := mcp.NewInMemoryTransports()
+	ss, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to start server: %v", err)
+	}
+	t.Cleanup(func() { ss.Close() })
+	if u.session, err = client.Connect(ctx, clientTransport, nil); err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	t.Cleanup(func() { u.Session().Close() })
+
+	// A listing by anyone fills the cache, and results rewritten by the
+	// lister don't leak into it
+	result, err := u.Session().ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools failed: %v", err)
+	}
+	result.Tools[0].Name = "remote:read"
+
+	for range 3 {
+		tool, err := u.Tool(ctx, "read")
+		if err != nil {
+			t.Fatalf("Tool failed: %v", err)
+		}
+		if tool.Name != "read" || !tool.Annotations.ReadOnlyHint {
+			t.Errorf("Tool = %+v, want the read definition", tool)
+		}
+	}
+	if _, err := u.Tool(ctx, "missing"); !errors.Is(err, ErrToolNotFound) {
+		t.Errorf("Tool(missing) error = %v, want ErrToolNotFound", err)
+	}
+	if got := lists.Load(); got != 1 {
+		t.Errorf("tools/list calls = %d, want 1", got)
+	}
+	if got, want := u.toolCache().stats(), (CacheStats{Hits: 4, Size: 1}); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+
+	// list_changed drops the cache, so the new tool is found
+	addTool("write")
+	deadline := time.Now().Add(time.Second)
+	for {
+		if _, err := u.Tool(ctx, "write"); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Tool(write) not found after list_changed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := u.toolCache().stats(); got.Evictions != 1 || got.Misses == 0 || got.Size != 2 {
+		t.Errorf("stats after list_changed = %+v, want 1 eviction, a miss, and 2 cached", got)
+	}
+}
+
+func TestToolCache_Bounded(t *testing.T) {
+	var c toolCache
+	tools := make([]*mcp.Tool, maxCachedItems+1)
+	for i := range tools {
+		tools[i] = &mcp.Tool{Name: fmt.Sprintf("tool%d", i)}
+	}
+	c.store(tools, true, true)
+
+	if len(c.items) != maxCachedItems {
+		t.Errorf("cached %d tools, want %d", len(c.items), maxCachedItems)
+	}
+	// The last tool wasn't cached, so the cache can't say it is missing
+	if _, known := c.lookup(tools[maxCachedItems].Name); known {
+		t.Error("lookup past the bound reported a known result")
+	}
+	if got, want := c.stats(), (CacheStats{Misses: 1, Size: maxCachedItems}); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,32 @@
+// Package version reports mcp2's build information. Version, Commit, and Date
+// are set at build time with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/ain3sh/mcp2/internal/version.Commit=$(git rev-parse --short HEAD)" ./cmd/mcp2
+package version
+
+import "runtime"
+
+// Build information, overridden via -ldflags -X.
+var (
+	Version = "0.1.0"
+	Commit  = "unknown"
+	Date    = "unknown"
+)
+
+// Info is mcp2's build information.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	Date      string `json:"date"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the build information for the running binary.
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+	}
+}